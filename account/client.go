@@ -13,11 +13,12 @@ type Client struct {
 	service pb.AccountServiceClient
 }
 
-func NewClient(url string) (*Client, error) {
+// NewClient connects to the account service at url, dialing it with opts on top of the defaults
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
 	if url == "" {
 		return nil, fmt.Errorf("grpc target url cannot be empty")
 	}
-	conn, err := grpc.Dial(url, grpc.WithInsecure())
+	conn, err := grpc.Dial(url, append([]grpc.DialOption{grpc.WithInsecure()}, opts...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -29,17 +30,14 @@ func (c *Client) Close() {
 	c.conn.Close()
 }
 
-func (c *Client) PostAccount(ctx context.Context, name string) (*Account, error) {
+func (c *Client) PostAccount(ctx context.Context, name string, email string) (*Account, error) {
 	// Call the function to create account
-	res, err := c.service.PostAccount(ctx, &pb.PostAccountRequest{Name: name})
+	res, err := c.service.PostAccount(ctx, &pb.PostAccountRequest{Name: name, Email: email})
 	if err != nil {
 		return nil, err
 	}
 
-	return &Account{
-		ID:   res.Account.Id,
-		Name: res.Account.Name,
-	}, nil
+	return accountFromProto(res.Account), nil
 }
 
 func (c *Client) GetAccount(ctx context.Context, id string) (*Account, error) {
@@ -49,10 +47,7 @@ func (c *Client) GetAccount(ctx context.Context, id string) (*Account, error) {
 		return nil, err
 	}
 
-	return &Account{
-		ID:   res.Account.Id,
-		Name: res.Account.Name,
-	}, nil
+	return accountFromProto(res.Account), nil
 }
 
 func (c *Client) GetAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error) {
@@ -68,11 +63,43 @@ func (c *Client) GetAccounts(ctx context.Context, skip uint64, take uint64) ([]A
 
 	// Range over the accounts and fill in the slice
 	for _, a := range res.Accounts {
-		accounts = append(accounts, Account{
-			ID:   a.Id,
-			Name: a.Name,
-		})
+		accounts = append(accounts, *accountFromProto(a))
 	}
 	return accounts, nil
 
 }
+
+// PostGuestAccount creates the guest account of a single guest checkout
+func (c *Client) PostGuestAccount(ctx context.Context, email string) (*Account, error) {
+	res, err := c.service.PostGuestAccount(ctx, &pb.PostGuestAccountRequest{Email: email})
+	if err != nil {
+		return nil, err
+	}
+	return accountFromProto(res.Account), nil
+}
+
+// RequestEmailVerification has the account service email the account a token to verify its address with
+func (c *Client) RequestEmailVerification(ctx context.Context, accountID string) error {
+	_, err := c.service.RequestEmailVerification(ctx, &pb.RequestEmailVerificationRequest{AccountId: accountID})
+	return err
+}
+
+// VerifyEmail returns the account a verification token was sent to, with its email address verified
+func (c *Client) VerifyEmail(ctx context.Context, token string) (*Account, error) {
+	res, err := c.service.VerifyEmail(ctx, &pb.VerifyEmailRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	return accountFromProto(res.Account), nil
+}
+
+// Convert the account back from the protobuf format
+func accountFromProto(a *pb.Account) *Account {
+	return &Account{
+		ID:            a.Id,
+		Name:          a.Name,
+		Email:         a.Email,
+		EmailVerified: a.EmailVerified,
+		Guest:         a.Guest,
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"log"
+	"net"
+	"net/smtp"
 	"time"
 
 	"github.com/PranavTrip/go-grpc-graphql-ms/account"
@@ -11,6 +13,13 @@ import (
 
 type Config struct {
 	DatabaseURL string `envconfig:"DATABASE_URL"`
+	// Key email verification tokens are signed with, verification is disabled while it is unset
+	EmailTokenSecret string `envconfig:"EMAIL_TOKEN_SECRET"`
+	// SMTP server the verification emails are sent through, required with EMAIL_TOKEN_SECRET
+	SMTPAddr     string `envconfig:"SMTP_ADDR"`
+	SMTPUsername string `envconfig:"SMTP_USERNAME"`
+	SMTPPassword string `envconfig:"SMTP_PASSWORD"`
+	EmailFrom    string `envconfig:"EMAIL_FROM" default:"noreply@localhost"`
 }
 
 func main() {
@@ -20,6 +29,22 @@ func main() {
 		log.Fatal(err)
 	}
 
+	verification := account.EmailVerification{Secret: []byte(cfg.EmailTokenSecret)}
+	if cfg.EmailTokenSecret != "" {
+		if cfg.SMTPAddr == "" {
+			log.Fatal("EMAIL_TOKEN_SECRET is set without SMTP_ADDR to email the tokens through")
+		}
+		mailer := account.SMTPMailer{Addr: cfg.SMTPAddr, From: cfg.EmailFrom}
+		if cfg.SMTPUsername != "" {
+			host, _, err := net.SplitHostPort(cfg.SMTPAddr)
+			if err != nil {
+				log.Fatal(err)
+			}
+			mailer.Auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, host)
+		}
+		verification.Mailer = mailer
+	}
+
 	var r account.Repository
 	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
 		r, err = account.NewPostgresRepository(cfg.DatabaseURL)
@@ -31,7 +56,7 @@ func main() {
 	defer r.Close()
 
 	log.Println("Listening on port 8080...")
-	s := account.NewService(r)
+	s := account.NewService(r, verification)
 	log.Fatal(account.ListenGRPC(s, 8080))
 
 }
@@ -0,0 +1,77 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// memoryRepository keeps accounts in memory, for development and tests
+type memoryRepository struct {
+	mu       sync.RWMutex
+	accounts map[string]Account
+	// Expiry of the last email token each account used
+	spentTokens map[string]int64
+}
+
+func NewMemoryRepository() Repository {
+	return &memoryRepository{accounts: map[string]Account{}, spentTokens: map[string]int64{}}
+}
+
+func (r *memoryRepository) Close() {
+}
+
+func (r *memoryRepository) PutAccount(ctx context.Context, a Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.accounts[a.ID]; ok {
+		return fmt.Errorf("account %q already exists", a.ID)
+	}
+	r.accounts[a.ID] = a
+	return nil
+}
+
+func (r *memoryRepository) GetAccountByID(ctx context.Context, id string) (*Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.accounts[id]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	return &a, nil
+}
+
+func (r *memoryRepository) ListAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Sorted like the Postgres repository does, newest IDs first
+	accounts := []Account{}
+	for _, a := range r.accounts {
+		accounts = append(accounts, a)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID > accounts[j].ID })
+
+	if skip >= uint64(len(accounts)) {
+		return []Account{}, nil
+	}
+	accounts = accounts[skip:]
+	if take < uint64(len(accounts)) {
+		accounts = accounts[:take]
+	}
+	return accounts, nil
+}
+
+func (r *memoryRepository) UseEmailToken(ctx context.Context, id string, email string, expiry int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.accounts[id]
+	if !ok || a.Email != email || r.spentTokens[id] >= expiry {
+		return ErrInvalidEmailToken
+	}
+	a.EmailVerified = true
+	r.accounts[id] = a
+	r.spentTokens[id] = expiry
+	return nil
+}
@@ -3,6 +3,7 @@ package account
 import (
 	"context"
 	"database/sql"
+
 	_ "github.com/lib/pq"
 )
 
@@ -12,6 +13,9 @@ type Repository interface {
 	PutAccount(ctx context.Context, a Account) error
 	GetAccountByID(ctx context.Context, id string) (*Account, error)
 	ListAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error)
+	// UseEmailToken marks the email address as verified with the token expiring at expiry, unless that
+	// or a later token has been used already
+	UseEmailToken(ctx context.Context, id string, email string, expiry int64) error
 }
 
 // Postgres Struct
@@ -43,15 +47,15 @@ func (r *postgresRepository) Ping() error {
 
 func (r *postgresRepository) PutAccount(ctx context.Context, a Account) error {
 	// ExecContext for Create Operations
-	_, err := r.db.ExecContext(ctx, "INSERT INTO accounts(id, name) VALUES($1, $2)", a.ID, a.Name)
+	_, err := r.db.ExecContext(ctx, "INSERT INTO accounts(id, name, email, email_verified, guest) VALUES($1, $2, NULLIF($3, ''), $4, $5)", a.ID, a.Name, a.Email, a.EmailVerified, a.Guest)
 	return err
 }
 
 func (r *postgresRepository) GetAccountByID(ctx context.Context, id string) (*Account, error) {
 	// QueryContext for Read Operations
-	row := r.db.QueryRowContext(ctx, "SELECT id,name FROM accounts WHERE id = $1", id)
+	row := r.db.QueryRowContext(ctx, "SELECT id,name,COALESCE(email, ''),email_verified,guest FROM accounts WHERE id = $1", id)
 	a := &Account{}
-	if err := row.Scan(&a.ID, &a.Name); err != nil {
+	if err := row.Scan(&a.ID, &a.Name, &a.Email, &a.EmailVerified, &a.Guest); err != nil {
 		return nil, err
 	}
 	return a, nil
@@ -59,7 +63,7 @@ func (r *postgresRepository) GetAccountByID(ctx context.Context, id string) (*Ac
 
 func (r *postgresRepository) ListAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error) {
 	//  QueryContext for Multiple Read Operations
-	rows, err := r.db.QueryContext(ctx, "SELECT id,name FROM accounts ORDER BY id DESC OFFSET $1 LIMIT $2", skip, take)
+	rows, err := r.db.QueryContext(ctx, "SELECT id,name,COALESCE(email, ''),email_verified,guest FROM accounts ORDER BY id DESC OFFSET $1 LIMIT $2", skip, take)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +75,7 @@ func (r *postgresRepository) ListAccounts(ctx context.Context, skip uint64, take
 	// Loop over the rows and fill in the above slice
 	for rows.Next() {
 		a := &Account{}
-		if err = rows.Scan(&a.ID, &a.Name); err == nil {
+		if err = rows.Scan(&a.ID, &a.Name, &a.Email, &a.EmailVerified, &a.Guest); err == nil {
 			accounts = append(accounts, *a)
 		}
 	}
@@ -81,3 +85,21 @@ func (r *postgresRepository) ListAccounts(ctx context.Context, skip uint64, take
 	return accounts, nil
 
 }
+
+func (r *postgresRepository) UseEmailToken(ctx context.Context, id string, email string, expiry int64) error {
+	// Only the address the token was signed for, in case it changed in between
+	res, err := r.db.ExecContext(
+		ctx,
+		"UPDATE accounts SET email_verified = TRUE, email_tokens_spent_until = $3 WHERE id = $1 AND email = $2 AND email_tokens_spent_until < $3",
+		id, email, expiry,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrInvalidEmailToken
+	}
+	return nil
+}
@@ -20,6 +20,11 @@ func ListenGRPC(s Service, port int) error {
 	if err != nil {
 		return err
 	}
+	return ServeGRPC(s, lis)
+}
+
+// ServeGRPC serves the account service on lis until it is closed
+func ServeGRPC(s Service, lis net.Listener) error {
 	serv := grpc.NewServer()
 	pb.RegisterAccountServiceServer(serv, &grpcServer{UnimplementedAccountServiceServer: pb.UnimplementedAccountServiceServer{}, service: s})
 	reflection.Register(serv)
@@ -28,15 +33,12 @@ func ListenGRPC(s Service, port int) error {
 
 func (s *grpcServer) PostAccount(ctx context.Context, r *pb.PostAccountRequest) (*pb.PostAccountResponse, error) {
 	// Call the service function to create account
-	a, err := s.service.PostAccount(ctx, r.Name)
+	a, err := s.service.PostAccount(ctx, r.Name, r.Email)
 	if err != nil {
 		return nil, err
 	}
 	return &pb.PostAccountResponse{
-		Account: &pb.Account{
-			Id:   a.ID,
-			Name: a.Name,
-		},
+		Account: accountToProto(a),
 	}, nil
 }
 
@@ -49,10 +51,7 @@ func (s *grpcServer) GetAccount(ctx context.Context, r *pb.GetAccountRequest) (*
 
 	// Convert the response to protobuf format for grpc
 	return &pb.GetAccountResponse{
-		Account: &pb.Account{
-			Id:   acc.ID,
-			Name: acc.Name,
-		},
+		Account: accountToProto(acc),
 	}, err
 }
 
@@ -68,10 +67,48 @@ func (s *grpcServer) GetAccounts(ctx context.Context, r *pb.GetAccountsRequest)
 
 	// Range over the response and fill in the slice
 	for _, p := range res {
-		accounts = append(accounts, &pb.Account{
-			Id:   p.ID,
-			Name: p.Name,
-		})
+		accounts = append(accounts, accountToProto(&p))
 	}
 	return &pb.GetAccountsResponse{Accounts: accounts}, nil
 }
+
+func (s *grpcServer) PostGuestAccount(ctx context.Context, r *pb.PostGuestAccountRequest) (*pb.PostGuestAccountResponse, error) {
+	// Call the service function to create the guest account of a checkout
+	a, err := s.service.PostGuestAccount(ctx, r.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostGuestAccountResponse{
+		Account: accountToProto(a),
+	}, nil
+}
+
+func (s *grpcServer) RequestEmailVerification(ctx context.Context, r *pb.RequestEmailVerificationRequest) (*pb.RequestEmailVerificationResponse, error) {
+	// Call the service function to email the account its verification token
+	if err := s.service.RequestEmailVerification(ctx, r.AccountId); err != nil {
+		return nil, err
+	}
+	return &pb.RequestEmailVerificationResponse{}, nil
+}
+
+func (s *grpcServer) VerifyEmail(ctx context.Context, r *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	// Call the service function to verify the email address the token was sent to
+	a, err := s.service.VerifyEmail(ctx, r.Token)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.VerifyEmailResponse{
+		Account: accountToProto(a),
+	}, nil
+}
+
+// Convert the account to protobuf format for grpc
+func accountToProto(a *Account) *pb.Account {
+	return &pb.Account{
+		Id:            a.ID,
+		Name:          a.Name,
+		Email:         a.Email,
+		EmailVerified: a.EmailVerified,
+		Guest:         a.Guest,
+	}
+}
@@ -2,35 +2,66 @@ package account
 
 import (
 	"context"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
 	"github.com/segmentio/ksuid"
 )
 
+var (
+	ErrAccountNotFound = errors.New("account not found")
+	ErrInvalidEmail    = errors.New("invalid email address")
+
+	ErrEmailVerificationDisabled = errors.New("email verification is disabled")
+	ErrNoEmailToVerify           = errors.New("only registered accounts with an email address can verify it")
+	ErrInvalidEmailToken         = errors.New("invalid or expired email verification token")
+)
+
 type Service interface {
-	PostAccount(ctx context.Context, name string) (*Account, error)
+	PostAccount(ctx context.Context, name string, email string) (*Account, error)
 	GetAccount(ctx context.Context, id string) (*Account, error)
 	GetAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error)
+	PostGuestAccount(ctx context.Context, email string) (*Account, error)
+	RequestEmailVerification(ctx context.Context, id string) error
+	VerifyEmail(ctx context.Context, token string) (*Account, error)
 }
 
+// Account is either a registered account or a guest account, which owns the order of a single guest
+// checkout and only has the email address it was placed with
 type Account struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"emailVerified"`
+	Guest         bool   `json:"guest"`
 }
 
 type accountService struct {
-	repository Repository
+	repository   Repository
+	verification EmailVerification
 }
 
-func NewService(r Repository) Service {
-	return &accountService{r}
+func NewService(r Repository, verification EmailVerification) Service {
+	return &accountService{r, verification}
 }
 
-// Method to handle the repository's PutAccount function
-func (s *accountService) PostAccount(ctx context.Context, name string) (*Account, error) {
+// Method to handle the repository's PutAccount function, the email address is optional
+func (s *accountService) PostAccount(ctx context.Context, name string, email string) (*Account, error) {
+	if email != "" {
+		address, err := mail.ParseAddress(email)
+		if err != nil {
+			return nil, ErrInvalidEmail
+		}
+		email = strings.ToLower(address.Address)
+	}
 
 	// Construct account to be posted
 	a := &Account{
-		Name: name,
-		ID:   ksuid.New().String(),
+		Name:  name,
+		ID:    ksuid.New().String(),
+		Email: email,
 	}
 	if err := s.repository.PutAccount(ctx, *a); err != nil {
 		return nil, err
@@ -50,3 +81,65 @@ func (s *accountService) GetAccounts(ctx context.Context, skip uint64, take uint
 	}
 	return s.repository.ListAccounts(ctx, skip, take)
 }
+
+// Create the guest account owning a single guest checkout. Every checkout gets its own, so that
+// claiming one into a registered account never hands over the orders of anyone else using the address.
+func (s *accountService) PostGuestAccount(ctx context.Context, email string) (*Account, error) {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return nil, ErrInvalidEmail
+	}
+
+	// Construct the guest account to be posted
+	a := &Account{
+		ID:    ksuid.New().String(),
+		Name:  "Guest",
+		Email: strings.ToLower(address.Address),
+		Guest: true,
+	}
+	if err := s.repository.PutAccount(ctx, *a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Email the account a token that proves it owns its email address once it is passed to VerifyEmail
+func (s *accountService) RequestEmailVerification(ctx context.Context, id string) error {
+	if !s.verification.enabled() {
+		return ErrEmailVerificationDisabled
+	}
+	a, err := s.repository.GetAccountByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if a.Guest || a.Email == "" {
+		return ErrNoEmailToVerify
+	}
+	return s.verification.Mailer.SendEmailVerification(ctx, *a, s.verification.sign(*a, time.Now()))
+}
+
+// Mark the email address a token was sent to as verified and return its account. Each token works once,
+// and using it also spends the tokens issued before it, so that one leaked from an old email can't stand
+// in for the account later.
+func (s *accountService) VerifyEmail(ctx context.Context, token string) (*Account, error) {
+	if !s.verification.enabled() {
+		return nil, ErrEmailVerificationDisabled
+	}
+	id, err := s.verification.accountID(token)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.repository.GetAccountByID(ctx, id)
+	if err != nil {
+		return nil, ErrInvalidEmailToken
+	}
+	expiry, err := s.verification.verify(token, *a, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.UseEmailToken(ctx, a.ID, a.Email, expiry); err != nil {
+		return nil, err
+	}
+	a.EmailVerified = true
+	return a, nil
+}
@@ -1,4 +1,20 @@
 CREATE TABLE IF NOT EXISTS accounts (
   id CHAR(27) PRIMARY KEY,
-  name VARCHAR(24) NOT NULL
-);
\ No newline at end of file
+  name VARCHAR(24) NOT NULL,
+  email VARCHAR(254),
+  email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+  guest BOOLEAN NOT NULL DEFAULT FALSE,
+  -- Expiry of the last email verification token used, it and every token issued before it are spent
+  email_tokens_spent_until BIGINT NOT NULL DEFAULT 0
+);
+
+-- Existing databases keep the table they were created with, so add the columns it gained since
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS email VARCHAR(254),
+  ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN IF NOT EXISTS guest BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN IF NOT EXISTS email_tokens_spent_until BIGINT NOT NULL DEFAULT 0;
+
+-- Guest accounts used to be shared by every checkout with the same email address, now each checkout
+-- gets its own
+DROP INDEX IF EXISTS accounts_guest_email_idx;
\ No newline at end of file
@@ -0,0 +1,89 @@
+package account
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How long an email verification token can be used for. Tokens also prove who is calling when guest
+// orders are claimed, so they are kept short lived as well as single use.
+const emailTokenTTL = time.Hour
+
+// Mailer delivers the emails of the account service
+type Mailer interface {
+	SendEmailVerification(ctx context.Context, a Account, token string) error
+}
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	// Address of the server as host:port
+	Addr string
+	// Auth is optional, for servers that accept mail without it
+	Auth smtp.Auth
+	From string
+}
+
+func (m SMTPMailer) SendEmailVerification(ctx context.Context, a Account, token string) error {
+	msg := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: Verify your email address\r\n\r\nYour email verification token is\r\n\r\n%s\r\n\r\nIt can be used once within the next hour.\r\n",
+		m.From, a.Email, token,
+	)
+	return smtp.SendMail(m.Addr, m.Auth, m.From, []string{a.Email}, []byte(msg))
+}
+
+// EmailVerification configures how accounts prove that they own their email address. Verification is
+// disabled while the Secret is empty or there is no Mailer to send the tokens.
+type EmailVerification struct {
+	Secret []byte
+	Mailer Mailer
+}
+
+func (v EmailVerification) enabled() bool {
+	return len(v.Secret) > 0 && v.Mailer != nil
+}
+
+// sign returns a token "<account ID>.<expiry unix>.<signature>" for the email address the account has
+// now, so that the token stops working if the address ever changes
+func (v EmailVerification) sign(a Account, now time.Time) string {
+	payload := fmt.Sprintf("%s.%d", a.ID, now.Add(emailTokenTTL).Unix())
+	return payload + "." + v.signature(payload, a.Email)
+}
+
+// accountID returns the account a token was issued to, which verify then checks it against
+func (v EmailVerification) accountID(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", ErrInvalidEmailToken
+	}
+	return parts[0], nil
+}
+
+// verify returns the expiry of a token, which orders the tokens of an account by when they were issued
+func (v EmailVerification) verify(token string, a Account, now time.Time) (int64, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != a.ID {
+		return 0, ErrInvalidEmailToken
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(v.signature(payload, a.Email))) {
+		return 0, ErrInvalidEmailToken
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() >= expiry {
+		return 0, ErrInvalidEmailToken
+	}
+	return expiry, nil
+}
+
+func (v EmailVerification) signature(payload, email string) string {
+	mac := hmac.New(sha256.New, v.Secret)
+	mac.Write([]byte(payload + "\n" + email))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,164 @@
+// Package blob stores files such as product images under slash-separated keys, and serves them over HTTP
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("blob keys are slash-separated paths without . or .. elements")
+)
+
+// Store keeps blobs under keys like "products/<id>/<image>.jpg". Blobs are written once and not changed
+// afterwards, so that they can be cached for good.
+type Store interface {
+	// Put stores a blob, replacing any blob with the same key
+	Put(ctx context.Context, key string, contentType string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a blob; deleting one that doesn't exist is not an error
+	Delete(ctx context.Context, key string) error
+	// URL is where clients download the blob from
+	URL(key string) string
+}
+
+type fileStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewFileStore keeps blobs as files in dir, creating it if needed. They are served at baseURL, e.g. by
+// Handler, which gets them back by their key.
+func NewFileStore(dir string, baseURL string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileStore{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+func (s *fileStore) path(key string) (string, error) {
+	if !fs.ValidPath(key) || key == "." {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Files take their content type from the extension of their key, so it is not stored
+func (s *fileStore) Put(ctx context.Context, key string, contentType string, r io.Reader) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that a blob is never read half written
+	f, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+func (s *fileStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Keys of directories are only prefixes of other keys
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		f.Close()
+		if err == nil {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *fileStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *fileStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// Handler serves the blobs of a store by their key, which is the path of the request with the leading
+// slash removed. Mount it with http.StripPrefix to serve it below a path.
+func Handler(s Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		blob, err := s.Get(r.Context(), key)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidKey) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		defer blob.Close()
+
+		if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		if rs, ok := blob.(io.ReadSeeker); ok {
+			http.ServeContent(w, r, key, modTime(blob), rs)
+			return
+		}
+		io.Copy(w, blob)
+	})
+}
+
+// Blobs that are files are served with their modification time, so that clients can revalidate them
+func modTime(blob io.ReadCloser) time.Time {
+	if f, ok := blob.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		if info, err := f.Stat(); err == nil {
+			return info.ModTime()
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,220 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// ProductRow is a product to import, as read from one line of a CSV or JSONL file.
+// Rows with the ID of an existing product replace it, keeping its creation time, whether it is archived and
+// its variants, and also its stock if the row has none or the product has variants. Rows without an ID become new products.
+type ProductRow struct {
+	// Where the row was read from, to report errors by
+	Line        uint64
+	ID          string
+	Name        string
+	Description string
+	Price       float64
+	Stock       *int64
+	CategoryID  string
+	Tags        []string
+}
+
+// Price changes made by imports are recorded as made by this
+const importActor = "import"
+
+// RowError describes why the row read from a line was not imported
+type RowError struct {
+	Line    uint64
+	Message string
+}
+
+type ImportResult struct {
+	// How many rows were imported, or would have been on a dry run
+	Imported uint64
+	Errors   []RowError
+}
+
+// Validates the rows and upserts the valid ones in bulk; on a dry run nothing is stored.
+// Invalid rows don't stop the others from being imported.
+func (s *catalogService) ImportProducts(ctx context.Context, rows []ProductRow, dryRun bool) (*ImportResult, error) {
+	result := &ImportResult{Errors: []RowError{}}
+
+	// Look the categories up once for all rows
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paths := map[string][]string{}
+	for _, c := range categories {
+		paths[c.ID] = c.Path
+	}
+
+	// Validate the rows, turning the valid ones into products
+	products := []Product{}
+	lines := []uint64{}
+	seen := map[string]uint64{}
+	for _, row := range rows {
+		product, err := productFromRow(row, paths)
+		if err == nil && product.ID != "" {
+			if line, ok := seen[product.ID]; ok {
+				err = fmt.Errorf("product %q was already imported from line %d", product.ID, line)
+			}
+			seen[product.ID] = row.Line
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: row.Line, Message: err.Error()})
+			continue
+		}
+		products = append(products, product)
+		lines = append(lines, row.Line)
+	}
+
+	// Products without an ID get theirs now, so that retries don't create them twice
+	for i := range products {
+		if products[i].ID == "" {
+			products[i].ID = ksuid.New().String()
+		}
+	}
+
+	// Products are replaced only while they are as they were read, so rows whose product changed
+	// meanwhile are merged with it again and retried
+	now := time.Now().UTC()
+	priceChanges := []PriceChange{}
+	pending := make([]int, len(products))
+	for i := range pending {
+		pending[i] = i
+	}
+	for attempt := 1; len(pending) > 0; attempt++ {
+		ids := make([]string, len(pending))
+		for j, i := range pending {
+			ids[j] = products[i].ID
+		}
+		found, err := s.repository.ListProductsWithIDs(ctx, ids, 0, uint64(len(ids)))
+		if err != nil {
+			return nil, err
+		}
+		existing := map[string]Product{}
+		for _, p := range found {
+			existing[p.ID] = p
+		}
+		batch := make([]Product, len(pending))
+		changes := make([]*PriceChange, len(pending))
+		for j, i := range pending {
+			batch[j], changes[j] = importedProduct(products[i], existing, now)
+		}
+		if dryRun {
+			result.Imported = uint64(len(batch))
+			return result, nil
+		}
+
+		errs, err := s.repository.PutProducts(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		retry := []int{}
+		for j, err := range errs {
+			switch {
+			case err == nil:
+				result.Imported++
+				if changes[j] != nil {
+					priceChanges = append(priceChanges, *changes[j])
+				}
+			case errors.Is(err, ErrProductChanged) && attempt < maxProductRetries:
+				retry = append(retry, pending[j])
+			default:
+				result.Errors = append(result.Errors, RowError{Line: lines[pending[j]], Message: err.Error()})
+			}
+		}
+		pending = retry
+	}
+	sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Line < result.Errors[j].Line })
+
+	if len(priceChanges) > 0 {
+		if err := s.repository.PutPriceChanges(ctx, priceChanges); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// importedProduct is the product a row becomes, keeping what the row doesn't set of the existing product
+// with its ID, and the change of its price if it has one
+func importedProduct(p Product, existing map[string]Product, now time.Time) (Product, *PriceChange) {
+	old, ok := existing[p.ID]
+	if !ok {
+		p.CreatedAt = now
+		if p.Stock < 0 {
+			p.Stock = 0
+		}
+		return p, nil
+	}
+	p.version = old.version
+	p.CreatedAt = old.CreatedAt
+	p.Archived = old.Archived
+	p.AverageRating = old.AverageRating
+	p.RatingCount = old.RatingCount
+	p.Translations = old.Translations
+	p.Images = old.Images
+	// The stock of a product with variants is theirs, which rows don't describe
+	p.Variants = old.Variants
+	if p.Stock < 0 || len(p.Variants) > 0 {
+		p.Stock = old.Stock
+	}
+	if p.Price == old.Price {
+		return p, nil
+	}
+	return p, &PriceChange{
+		ProductID: p.ID,
+		OldPrice:  old.Price,
+		NewPrice:  p.Price,
+		ChangedBy: importActor,
+		ChangedAt: now,
+	}
+}
+
+// productFromRow checks a row and converts it to a product. A row without stock gets a negative one,
+// to be replaced by the stock of the existing product.
+func productFromRow(row ProductRow, categoryPaths map[string][]string) (Product, error) {
+	product := Product{
+		ID:           strings.TrimSpace(row.ID),
+		Name:         strings.TrimSpace(row.Name),
+		Description:  row.Description,
+		Price:        row.Price,
+		Stock:        -1,
+		CategoryPath: []string{},
+		Tags:         normalizeTags(row.Tags),
+	}
+	if product.Name == "" {
+		return product, fmt.Errorf("name is required")
+	}
+	if !validPrice(row.Price) {
+		return product, fmt.Errorf("price must be a non-negative number")
+	}
+	if row.Stock != nil {
+		if *row.Stock < 0 {
+			return product, fmt.Errorf("stock must not be negative")
+		}
+		product.Stock = *row.Stock
+	}
+	if categoryID := strings.TrimSpace(row.CategoryID); categoryID != "" {
+		path, ok := categoryPaths[categoryID]
+		if !ok {
+			return product, fmt.Errorf("category %q does not exist", categoryID)
+		}
+		product.CategoryID = categoryID
+		product.CategoryPath = path
+	}
+	return product, nil
+}
+
+// Passes all products to fn, a page at a time
+func (s *catalogService) ExportProducts(ctx context.Context, fn func(products []Product) error) error {
+	return s.repository.ScrollProducts(ctx, fn)
+}
@@ -0,0 +1,261 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestImportProducts(t *testing.T) {
+	ctx := context.Background()
+	stock := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name   string
+		rows   []ProductRow
+		dryRun bool
+		// The products stored afterwards, by ID, and how many the import reports as imported
+		want     map[string]importedProductSummary
+		imported uint64
+		errors   []RowError
+	}{
+		{
+			name: "updates keep what rows don't set",
+			rows: []ProductRow{
+				{Line: 2, ID: "boot", Name: "Alpine Boot II", Price: 130, Tags: []string{"Winter"}},
+				{Line: 3, ID: "shirt", Name: "Linen Shirt", Price: 30, Stock: stock(99), CategoryID: "tops"},
+			},
+			want: map[string]importedProductSummary{
+				"boot":  {Name: "Alpine Boot II", Price: 130, Stock: 4, Tags: []string{"winter"}},
+				"shirt": {Name: "Linen Shirt", Price: 30, Stock: 5, CategoryID: "tops", Variants: 2},
+			},
+			imported: 2,
+		},
+		{
+			name: "stock of products without variants is replaced",
+			rows: []ProductRow{{Line: 2, ID: "boot", Name: "Alpine Boot", Price: 120, Stock: stock(10)}},
+			want: map[string]importedProductSummary{
+				"boot": {Name: "Alpine Boot", Price: 120, Stock: 10},
+			},
+			imported: 1,
+		},
+		{
+			name: "rows for missing products create them",
+			rows: []ProductRow{{Line: 2, ID: "sandal", Name: "Beach Sandal", Price: 25}},
+			want: map[string]importedProductSummary{
+				"sandal": {Name: "Beach Sandal", Price: 25},
+			},
+			imported: 1,
+		},
+		{
+			name: "invalid rows are reported by line",
+			rows: []ProductRow{
+				{Line: 2, ID: "boot", Price: 130},
+				{Line: 3, ID: "shirt", Name: "Linen Shirt", Price: -1},
+				{Line: 4, ID: "shirt", Name: "Linen Shirt", Price: 30, Stock: stock(-2)},
+				{Line: 5, ID: "sandal", Name: "Beach Sandal", Price: 25, CategoryID: "shoes"},
+				{Line: 6, ID: "hat", Name: "Sun Hat", Price: 15},
+			},
+			want: map[string]importedProductSummary{
+				"boot":  {Name: "Alpine Boot", Price: 120, Stock: 4},
+				"shirt": {Name: "Oxford Shirt", Price: 40, Stock: 5, CategoryID: "tops", Variants: 2},
+				"hat":   {Name: "Sun Hat", Price: 15},
+			},
+			imported: 1,
+			errors: []RowError{
+				{Line: 2, Message: "name is required"},
+				{Line: 3, Message: "price must be a non-negative number"},
+				{Line: 4, Message: "stock must not be negative"},
+				{Line: 5, Message: `category "shoes" does not exist`},
+			},
+		},
+		{
+			name: "duplicate IDs are only imported once",
+			rows: []ProductRow{
+				{Line: 2, ID: "boot", Name: "Alpine Boot II", Price: 130},
+				{Line: 3, ID: "boot", Name: "Alpine Boot III", Price: 140},
+			},
+			want: map[string]importedProductSummary{
+				"boot": {Name: "Alpine Boot II", Price: 130, Stock: 4},
+			},
+			imported: 1,
+			errors:   []RowError{{Line: 3, Message: `product "boot" was already imported from line 2`}},
+		},
+		{
+			name: "dry runs store nothing",
+			rows: []ProductRow{
+				{Line: 2, ID: "boot", Name: "Alpine Boot II", Price: 130},
+				{Line: 3, ID: "sandal", Name: "Beach Sandal", Price: 25},
+				{Line: 4, Name: "", Price: 25},
+			},
+			dryRun: true,
+			want: map[string]importedProductSummary{
+				"boot":   {Name: "Alpine Boot", Price: 120, Stock: 4},
+				"sandal": {},
+			},
+			imported: 2,
+			errors:   []RowError{{Line: 4, Message: "name is required"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMemoryRepository()
+			putImportCatalog(t, r)
+			s := NewService(r, nil, Personalization{})
+
+			result, err := s.ImportProducts(ctx, tt.rows, tt.dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Imported != tt.imported {
+				t.Errorf("got %d products imported, want %d", result.Imported, tt.imported)
+			}
+			if tt.errors == nil {
+				tt.errors = []RowError{}
+			}
+			if !reflect.DeepEqual(result.Errors, tt.errors) {
+				t.Errorf("got errors %+v, want %+v", result.Errors, tt.errors)
+			}
+			for id, want := range tt.want {
+				got, err := r.GetProductByID(ctx, id)
+				if want.Name == "" {
+					if !errors.Is(err, ErrProductNotFound) {
+						t.Errorf("got product %q with error %v, want %v", id, err, ErrProductNotFound)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if g := summarizeImported(*got); !reflect.DeepEqual(g, want) {
+					t.Errorf("got product %q %+v, want %+v", id, g, want)
+				}
+				if got.CreatedAt.IsZero() {
+					t.Errorf("got product %q without a creation time", id)
+				}
+			}
+		})
+	}
+}
+
+func TestImportProductsRecordsPriceChanges(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	putImportCatalog(t, r)
+	s := NewService(r, nil, Personalization{})
+
+	if _, err := s.ImportProducts(ctx, []ProductRow{
+		{Line: 2, ID: "boot", Name: "Alpine Boot", Price: 110},
+		{Line: 3, ID: "shirt", Name: "Oxford Shirt", Price: 40},
+	}, false); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := r.ListPriceChanges(ctx, "boot", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].OldPrice != 120 || changes[0].NewPrice != 110 || changes[0].ChangedBy != importActor {
+		t.Errorf("got price changes %+v, want one from 120 to 110 by %s", changes, importActor)
+	}
+	if changes, err := r.ListPriceChanges(ctx, "shirt", 0, 10); err != nil || len(changes) != 0 {
+		t.Errorf("got price changes %+v, %v for an unchanged price, want none", changes, err)
+	}
+}
+
+// changingRepository adjusts the stock of a product right after it was listed, the given number of times,
+// as if someone ordered it while an import was merging rows with it
+type changingRepository struct {
+	Repository
+	productID string
+	changes   int
+}
+
+func (r *changingRepository) ListProductsWithIDs(ctx context.Context, ids []string, skip uint64, take uint64) ([]Product, error) {
+	products, err := r.Repository.ListProductsWithIDs(ctx, ids, skip, take)
+	if err == nil && r.changes > 0 {
+		r.changes--
+		err = r.Repository.AdjustStock(ctx, r.productID, "", -1)
+	}
+	return products, err
+}
+
+func TestImportProductsRetriesChangedProducts(t *testing.T) {
+	ctx := context.Background()
+	rows := []ProductRow{
+		{Line: 2, ID: "boot", Name: "Alpine Boot II", Price: 130},
+		{Line: 3, ID: "sandal", Name: "Beach Sandal", Price: 25},
+	}
+
+	// The row is merged with the product again, keeping the stock it was changed to
+	r := &changingRepository{Repository: NewMemoryRepository(), productID: "boot", changes: 1}
+	putImportCatalog(t, r.Repository)
+	result, err := NewService(r, nil, Personalization{}).ImportProducts(ctx, rows, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 2 || len(result.Errors) != 0 {
+		t.Fatalf("got %+v, want both rows imported", result)
+	}
+	boot, err := r.GetProductByID(ctx, "boot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if boot.Name != "Alpine Boot II" || boot.Stock != 3 {
+		t.Errorf("got %q with %d in stock, want Alpine Boot II with 3", boot.Name, boot.Stock)
+	}
+
+	// A product that keeps changing fails its row once the retries run out
+	r = &changingRepository{Repository: NewMemoryRepository(), productID: "boot", changes: maxProductRetries}
+	putImportCatalog(t, r.Repository)
+	result, err = NewService(r, nil, Personalization{}).ImportProducts(ctx, rows, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RowError{{Line: 2, Message: ErrProductChanged.Error()}}
+	if result.Imported != 1 || !reflect.DeepEqual(result.Errors, want) {
+		t.Errorf("got %+v, want the sandal imported and errors %+v", result, want)
+	}
+	if boot, err := r.GetProductByID(ctx, "boot"); err != nil || boot.Name != "Alpine Boot" {
+		t.Errorf("got %+v, %v, want the boot left as it was", boot, err)
+	}
+}
+
+// putImportCatalog stores a category, a boot and a shirt sold in two variants for imports to update
+func putImportCatalog(t *testing.T, r Repository) {
+	t.Helper()
+	if err := r.PutCategory(context.Background(), Category{ID: "tops", Name: "Tops", Path: []string{"tops"}}); err != nil {
+		t.Fatal(err)
+	}
+	boot := testProduct("boot", "Alpine Boot", 120)
+	boot.Stock = 4
+	shirt := testProduct("shirt", "Oxford Shirt", 40)
+	shirt.CategoryID, shirt.CategoryPath = "tops", []string{"tops"}
+	shirt.Variants = []Variant{{ID: "m", SKU: "SHIRT-M", Stock: 2}, {ID: "l", SKU: "SHIRT-L", Stock: 3}}
+	shirt.Stock = 5
+	putProducts(t, r, boot, shirt)
+}
+
+// importedProductSummary is what the import tests compare of a stored product
+type importedProductSummary struct {
+	Name       string
+	Price      float64
+	Stock      int64
+	CategoryID string
+	Tags       []string
+	Variants   int
+}
+
+func summarizeImported(p Product) importedProductSummary {
+	summary := importedProductSummary{
+		Name:       p.Name,
+		Price:      p.Price,
+		Stock:      p.Stock,
+		CategoryID: p.CategoryID,
+		Variants:   len(p.Variants),
+	}
+	if len(p.Tags) > 0 {
+		summary.Tags = p.Tags
+	}
+	return summary
+}
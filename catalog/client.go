@@ -3,6 +3,8 @@ package catalog
 import (
 	"context"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/PranavTrip/go-grpc-graphql-ms/catalog/pb"
 	"google.golang.org/grpc"
@@ -13,11 +15,12 @@ type Client struct {
 	service pb.CatalogServiceClient
 }
 
-func NewClient(url string) (*Client, error) {
+// NewClient connects to the catalog service at url, dialing it with opts on top of the defaults
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
 	if url == "" {
 		return nil, fmt.Errorf("grpc target url cannot be empty")
 	}
-	conn, err := grpc.Dial(url, grpc.WithInsecure())
+	conn, err := grpc.Dial(url, append([]grpc.DialOption{grpc.WithInsecure()}, opts...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -29,22 +32,20 @@ func (c *Client) Close() {
 	c.conn.Close()
 }
 
-func (c *Client) PostProduct(ctx context.Context, name string, description string, price float64) (*Product, error) {
+func (c *Client) PostProduct(ctx context.Context, name string, description string, price float64, stock int64, categoryID string, tags []string) (*Product, error) {
 	// Call the function to Post a Product 
 	res, err := c.service.PostProduct(ctx, &pb.PostProductRequest{
 		Name:        name,
 		Description: description,
 		Price:       price,
+		Stock:       stock,
+		CategoryId:  categoryID,
+		Tags:        tags,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &Product{
-		ID:          res.Product.Id,
-		Name:        res.Product.Name,
-		Description: res.Product.Description,
-		Price:       res.Product.Price,
-	}, nil
+	return productFromProto(res.Product), nil
 
 }
 
@@ -56,12 +57,7 @@ func (c *Client) GetProduct(ctx context.Context, id string) (*Product, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Product{
-		ID:          res.Product.Id,
-		Name:        res.Product.Name,
-		Description: res.Product.Description,
-		Price:       res.Product.Price,
-	}, nil
+	return productFromProto(res.Product), nil
 }
 
 func (c *Client) GetProducts(ctx context.Context, ids []string, skip uint64, take uint64, query string) ([]Product, error) {
@@ -77,12 +73,550 @@ func (c *Client) GetProducts(ctx context.Context, ids []string, skip uint64, tak
 	}
 	products := []Product{}
 	for _, r := range res.Products {
-		products = append(products, Product{
-			ID:          r.Id,
-			Name:        r.Name,
-			Description: r.Description,
-			Price:       r.Price,
-		})
+		products = append(products, *productFromProto(r))
 	}
 	return products, nil
 }
+
+// UpdateProduct changes the details of a product that are set in the update, recording who changed its price
+func (c *Client) UpdateProduct(ctx context.Context, id string, update ProductUpdate, changedBy string) (*Product, error) {
+	res, err := c.service.UpdateProduct(ctx, &pb.UpdateProductRequest{
+		Id:          id,
+		Name:        update.Name,
+		Description: update.Description,
+		Price:       update.Price,
+		ChangedBy:   changedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+// ArchiveProduct takes a product out of sale, keeping it resolvable by ID
+func (c *Client) ArchiveProduct(ctx context.Context, id string) (*Product, error) {
+	res, err := c.service.ArchiveProduct(ctx, &pb.ArchiveProductRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+// GetPriceHistory lists the price changes of a product, newest first
+func (c *Client) GetPriceHistory(ctx context.Context, productID string, skip uint64, take uint64) ([]PriceChange, error) {
+	res, err := c.service.GetPriceHistory(ctx, &pb.GetPriceHistoryRequest{
+		ProductId: productID,
+		Skip:      skip,
+		Take:      take,
+	})
+	if err != nil {
+		return nil, err
+	}
+	changes := []PriceChange{}
+	for _, ch := range res.Changes {
+		change := PriceChange{
+			ProductID: ch.ProductId,
+			VariantID: ch.VariantId,
+			OldPrice:  ch.OldPrice,
+			NewPrice:  ch.NewPrice,
+			ChangedBy: ch.ChangedBy,
+		}
+		change.ChangedAt.UnmarshalBinary(ch.ChangedAt)
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func (c *Client) AdjustStock(ctx context.Context, id string, variantID string, delta int64) (*Product, error) {
+	// Call the function to add delta to the stock of a product, or of its variant if one is given
+	res, err := c.service.AdjustStock(ctx, &pb.AdjustStockRequest{
+		ProductId: id,
+		VariantId: variantID,
+		Delta:     delta,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+// UpdateRating replaces the average rating and rating count kept on a product
+func (c *Client) UpdateRating(ctx context.Context, id string, averageRating float64, ratingCount uint64) (*Product, error) {
+	res, err := c.service.UpdateRating(ctx, &pb.UpdateRatingRequest{
+		ProductId:     id,
+		AverageRating: averageRating,
+		RatingCount:   ratingCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+// TranslateProduct sets the name and description of a product in a locale other than the default one;
+// leaving both empty removes the translation
+func (c *Client) TranslateProduct(ctx context.Context, id string, locale string, name string, description string) (*Product, error) {
+	res, err := c.service.TranslateProduct(ctx, &pb.TranslateProductRequest{
+		ProductId:   id,
+		Locale:      locale,
+		Name:        name,
+		Description: description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+// PutVariant adds a variant to a product, or changes the variant with the same ID
+func (c *Client) PutVariant(ctx context.Context, productID string, variant Variant, changedBy string) (*Product, error) {
+	res, err := c.service.PutVariant(ctx, &pb.PutVariantRequest{
+		ProductId: productID,
+		Variant:   variantToProto(variant),
+		ChangedBy: changedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+func (c *Client) DeleteVariant(ctx context.Context, productID string, variantID string) (*Product, error) {
+	res, err := c.service.DeleteVariant(ctx, &pb.DeleteVariantRequest{
+		ProductId: productID,
+		VariantId: variantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+// SearchProducts searches the products matching the query text, or all of them if it is empty, narrowed down
+// by the filter, and counts the matching products in total and per category and tag
+func (c *Client) SearchProducts(ctx context.Context, query ProductQuery) (*ProductSearchResult, error) {
+	res, err := c.service.GetProducts(ctx, &pb.GetProductsRequest{
+		Query:      query.Query,
+		Skip:       query.Skip,
+		Take:       query.Take,
+		CategoryId: query.Filter.CategoryID,
+		Tags:       query.Filter.Tags,
+		MinPrice:   query.Filter.MinPrice,
+		MaxPrice:   query.Filter.MaxPrice,
+		InStock:    query.Filter.InStock,
+		Sort:       string(query.Sort),
+		Locale:     query.Locale,
+		AccountId:  query.AccountID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &ProductSearchResult{
+		Products: []Product{},
+		Total:    res.TotalCount,
+		Facets: ProductFacets{
+			Categories: facetCountsFromProto(res.Facets.GetCategories()),
+			Tags:       facetCountsFromProto(res.Facets.GetTags()),
+		},
+	}
+	for _, r := range res.Products {
+		result.Products = append(result.Products, *productFromProto(r))
+	}
+	return result, nil
+}
+
+// CategorizeProduct moves a product into a category, or out of any if categoryID is empty, and replaces its tags
+func (c *Client) CategorizeProduct(ctx context.Context, id string, categoryID string, tags []string) (*Product, error) {
+	res, err := c.service.CategorizeProduct(ctx, &pb.CategorizeProductRequest{
+		ProductId:  id,
+		CategoryId: categoryID,
+		Tags:       tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+func (c *Client) PostCategory(ctx context.Context, name string, parentID string) (*Category, error) {
+	res, err := c.service.PostCategory(ctx, &pb.PostCategoryRequest{
+		Name:     name,
+		ParentId: parentID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return categoryFromProto(res.Category), nil
+}
+
+func (c *Client) GetCategories(ctx context.Context) ([]Category, error) {
+	res, err := c.service.GetCategories(ctx, &pb.GetCategoriesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	categories := []Category{}
+	for _, r := range res.Categories {
+		categories = append(categories, *categoryFromProto(r))
+	}
+	return categories, nil
+}
+
+func (c *Client) DeleteCategory(ctx context.Context, id string) error {
+	_, err := c.service.DeleteCategory(ctx, &pb.DeleteCategoryRequest{Id: id})
+	return err
+}
+
+// SuggestProducts returns up to size products whose names complete the prefix, best first
+func (c *Client) SuggestProducts(ctx context.Context, prefix string, size uint64) ([]ProductSuggestion, error) {
+	res, err := c.service.SuggestProducts(ctx, &pb.SuggestProductsRequest{
+		Prefix: prefix,
+		Size:   size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	suggestions := []ProductSuggestion{}
+	for _, s := range res.Suggestions {
+		suggestions = append(suggestions, ProductSuggestion{
+			ID:    s.Id,
+			Name:  s.Name,
+			Score: s.Score,
+		})
+	}
+	return suggestions, nil
+}
+
+// ImportProducts streams the rows returned by next to the catalog, a batch per call, until next returns io.EOF.
+// On a dry run the rows are only validated.
+func (c *Client) ImportProducts(ctx context.Context, dryRun bool, next func() ([]ProductRow, error)) (*ImportResult, error) {
+	// Cancelling the stream rather than closing it keeps the server from taking it as complete
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.service.ImportProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		rows, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		req := &pb.ImportProductsRequest{DryRun: dryRun}
+		for _, row := range rows {
+			req.Rows = append(req.Rows, &pb.ProductRow{
+				Line:        row.Line,
+				Id:          row.ID,
+				Name:        row.Name,
+				Description: row.Description,
+				Price:       row.Price,
+				Stock:       row.Stock,
+				CategoryId:  row.CategoryID,
+				Tags:        row.Tags,
+			})
+		}
+		if err := stream.Send(req); err != nil {
+			// The server ended the import; its error comes with the response
+			break
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	result := &ImportResult{Imported: res.Imported, Errors: []RowError{}}
+	for _, e := range res.Errors {
+		result.Errors = append(result.Errors, RowError{Line: e.Line, Message: e.Message})
+	}
+	return result, nil
+}
+
+// ExportProducts passes all products of the catalog to fn, a page at a time
+func (c *Client) ExportProducts(ctx context.Context, fn func(products []Product) error) error {
+	stream, err := c.service.ExportProducts(ctx, &pb.ExportProductsRequest{})
+	if err != nil {
+		return err
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		products := []Product{}
+		for _, p := range res.Products {
+			products = append(products, *productFromProto(p))
+		}
+		if err := fn(products); err != nil {
+			return err
+		}
+	}
+}
+
+// Size of the chunks image files are streamed to the catalog in
+const imageChunkSize = 64 << 10
+
+// UploadProductImage streams an image file to the catalog, which adds it to the product at position among
+// its images, or after them if position is nil
+func (c *Client) UploadProductImage(ctx context.Context, productID string, file io.Reader, altText string, position *int) (*Product, error) {
+	// Cancelling the stream rather than closing it keeps the server from taking a failed upload as complete
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.service.UploadProductImage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := &pb.ImageUploadInfo{ProductId: productID, AltText: altText}
+	if position != nil {
+		if *position < 0 {
+			return nil, ErrInvalidImage
+		}
+		p := uint32(*position)
+		info.Position = &p
+	}
+	if err := stream.Send(&pb.UploadProductImageRequest{Data: &pb.UploadProductImageRequest_Info{Info: info}}); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, imageChunkSize)
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			chunk := &pb.UploadProductImageRequest{Data: &pb.UploadProductImageRequest_Chunk{Chunk: buf[:n]}}
+			if err := stream.Send(chunk); err != nil {
+				// The server rejected the upload; its error comes with the response
+				break
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+// UpdateProductImage changes the alt text or the position of an image of the product
+func (c *Client) UpdateProductImage(ctx context.Context, productID string, imageID string, update ImageUpdate) (*Product, error) {
+	req := &pb.UpdateProductImageRequest{ProductId: productID, ImageId: imageID, AltText: update.AltText}
+	if update.Position != nil {
+		if *update.Position < 0 {
+			return nil, ErrInvalidImage
+		}
+		p := uint32(*update.Position)
+		req.Position = &p
+	}
+	res, err := c.service.UpdateProductImage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+// DeleteProductImage removes an image from the product
+func (c *Client) DeleteProductImage(ctx context.Context, productID string, imageID string) (*Product, error) {
+	res, err := c.service.DeleteProductImage(ctx, &pb.DeleteProductImageRequest{ProductId: productID, ImageId: imageID})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(res.Product), nil
+}
+
+// PutMerchandisingRule creates a rule if it has no ID, or replaces the rule with its ID
+func (c *Client) PutMerchandisingRule(ctx context.Context, rule MerchandisingRule) (*MerchandisingRule, error) {
+	res, err := c.service.PutMerchandisingRule(ctx, &pb.PutMerchandisingRuleRequest{Rule: merchandisingRuleToProto(rule)})
+	if err != nil {
+		return nil, err
+	}
+	r := merchandisingRuleFromProto(res.Rule)
+	return &r, nil
+}
+
+func (c *Client) GetMerchandisingRules(ctx context.Context) ([]MerchandisingRule, error) {
+	res, err := c.service.GetMerchandisingRules(ctx, &pb.GetMerchandisingRulesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	rules := []MerchandisingRule{}
+	for _, r := range res.Rules {
+		rules = append(rules, merchandisingRuleFromProto(r))
+	}
+	return rules, nil
+}
+
+func (c *Client) DeleteMerchandisingRule(ctx context.Context, id string) error {
+	_, err := c.service.DeleteMerchandisingRule(ctx, &pb.DeleteMerchandisingRuleRequest{Id: id})
+	return err
+}
+
+func (c *Client) GetSearchVocabulary(ctx context.Context) (*SearchVocabulary, error) {
+	res, err := c.service.GetSearchVocabulary(ctx, &pb.GetSearchVocabularyRequest{})
+	if err != nil {
+		return nil, err
+	}
+	v := vocabularyFromProto(res.Vocabulary)
+	return &v, nil
+}
+
+// PutSearchVocabulary replaces the synonyms and stopwords of searches
+func (c *Client) PutSearchVocabulary(ctx context.Context, v SearchVocabulary) (*SearchVocabulary, error) {
+	res, err := c.service.PutSearchVocabulary(ctx, &pb.PutSearchVocabularyRequest{Vocabulary: vocabularyToProto(v)})
+	if err != nil {
+		return nil, err
+	}
+	saved := vocabularyFromProto(res.Vocabulary)
+	return &saved, nil
+}
+
+// GetSearchReport lists the top queries and those without results since a time, or of the last week if it is zero
+func (c *Client) GetSearchReport(ctx context.Context, since time.Time, size uint64) (*SearchReport, error) {
+	req := &pb.GetSearchReportRequest{Size: size}
+	if !since.IsZero() {
+		req.Since, _ = since.MarshalBinary()
+	}
+	res, err := c.service.GetSearchReport(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	report := &SearchReport{
+		Searches:           res.Searches,
+		ZeroResultSearches: res.ZeroResultSearches,
+		TopQueries:         queryStatsFromProto(res.TopQueries),
+		ZeroResultQueries:  queryStatsFromProto(res.ZeroResultQueries),
+	}
+	report.Since.UnmarshalBinary(res.Since)
+	return report, nil
+}
+
+// Convert the product back from the protobuf format
+func productFromProto(p *pb.Product) *Product {
+	product := &Product{
+		ID:            p.Id,
+		Name:          p.Name,
+		Description:   p.Description,
+		Price:         p.Price,
+		Stock:         p.Stock,
+		CategoryID:    p.CategoryId,
+		CategoryPath:  p.CategoryPath,
+		Tags:          p.Tags,
+		Archived:      p.Archived,
+		AverageRating: p.AverageRating,
+		RatingCount:   p.RatingCount,
+	}
+	if product.CategoryPath == nil {
+		product.CategoryPath = []string{}
+	}
+	if product.Tags == nil {
+		product.Tags = []string{}
+	}
+	if len(p.CreatedAt) > 0 {
+		product.CreatedAt.UnmarshalBinary(p.CreatedAt)
+	}
+	product.Variants = []Variant{}
+	for _, v := range p.Variants {
+		product.Variants = append(product.Variants, variantFromProto(v))
+	}
+	product.Translations = map[string]ProductTranslation{}
+	for locale, t := range p.Translations {
+		product.Translations[locale] = ProductTranslation{Name: t.GetName(), Description: t.GetDescription()}
+	}
+	product.Images = []ProductImage{}
+	for _, img := range p.Images {
+		product.Images = append(product.Images, ProductImage{
+			ID:           img.Id,
+			URL:          img.Url,
+			ThumbnailURL: img.ThumbnailUrl,
+			AltText:      img.AltText,
+			ContentType:  img.ContentType,
+			Width:        int(img.Width),
+			Height:       int(img.Height),
+		})
+	}
+	return product
+}
+
+func variantFromProto(v *pb.Variant) Variant {
+	variant := Variant{
+		ID:      v.Id,
+		SKU:     v.Sku,
+		Options: []VariantOption{},
+		Price:   v.Price,
+		Stock:   v.Stock,
+	}
+	for _, o := range v.Options {
+		variant.Options = append(variant.Options, VariantOption{Name: o.Name, Value: o.Value})
+	}
+	return variant
+}
+
+func categoryFromProto(c *pb.Category) *Category {
+	category := &Category{
+		ID:       c.Id,
+		Name:     c.Name,
+		ParentID: c.ParentId,
+		Path:     c.Path,
+	}
+	if category.Path == nil {
+		category.Path = []string{}
+	}
+	return category
+}
+
+func facetCountsFromProto(protoCounts []*pb.FacetCount) []FacetCount {
+	counts := []FacetCount{}
+	for _, c := range protoCounts {
+		counts = append(counts, FacetCount{Value: c.Value, Count: c.Count})
+	}
+	return counts
+}
+
+func merchandisingRuleFromProto(r *pb.MerchandisingRule) MerchandisingRule {
+	rule := MerchandisingRule{
+		ID:         r.Id,
+		Query:      r.Query,
+		CategoryID: r.CategoryId,
+		Pinned:     r.Pinned,
+		Boosts:     []AttributeBoost{},
+		Buried:     r.Buried,
+		Hidden:     r.Hidden,
+	}
+	for _, b := range r.Boosts {
+		rule.Boosts = append(rule.Boosts, AttributeBoost{Attribute: BoostAttribute(b.Attribute), Value: b.Value, Factor: b.Factor})
+	}
+	for _, ids := range []*[]string{&rule.Pinned, &rule.Buried, &rule.Hidden} {
+		if *ids == nil {
+			*ids = []string{}
+		}
+	}
+	return rule
+}
+
+func vocabularyFromProto(v *pb.SearchVocabulary) SearchVocabulary {
+	vocabulary := SearchVocabulary{Synonyms: [][]string{}, Stopwords: []string{}}
+	if v == nil {
+		return vocabulary
+	}
+	for _, group := range v.Synonyms {
+		vocabulary.Synonyms = append(vocabulary.Synonyms, group.Terms)
+	}
+	vocabulary.Stopwords = append(vocabulary.Stopwords, v.Stopwords...)
+	return vocabulary
+}
+
+func queryStatsFromProto(protoQueries []*pb.QueryStats) []QueryStats {
+	queries := []QueryStats{}
+	for _, q := range protoQueries {
+		queries = append(queries, QueryStats{Query: q.Query, Searches: q.Searches})
+	}
+	return queries
+}
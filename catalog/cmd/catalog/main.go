@@ -1,16 +1,32 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"net/http"
 	"time"
 
+	"github.com/PranavTrip/go-grpc-graphql-ms/blob"
 	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"github.com/PranavTrip/go-grpc-graphql-ms/order"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/tinrab/retry"
 )
 
 type Config struct {
 	DATABASE_URL string `envconfig:"DATABASE_URL"`
+	// Where the catalog is stored: "elastic", with DATABASE_URL pointing at Elasticsearch, or "postgres",
+	// with DATABASE_URL being a Postgres connection string
+	CATALOG_BACKEND string `envconfig:"CATALOG_BACKEND" default:"elastic"`
+	// Product images are kept in MEDIA_DIR and served on MEDIA_PORT, where clients reach them at MEDIA_URL
+	MEDIA_DIR  string `envconfig:"MEDIA_DIR" default:"/var/lib/catalog/media"`
+	MEDIA_PORT int    `envconfig:"MEDIA_PORT" default:"8090"`
+	MEDIA_URL  string `envconfig:"MEDIA_URL" default:"http://localhost:8090"`
+	// Searches are personalized with the purchase history of the order service at ORDER_SERVICE_URL, if set,
+	// favouring the categories and tags an account bought from by up to these factors
+	ORDER_SERVICE_URL              string  `envconfig:"ORDER_SERVICE_URL"`
+	PERSONALIZATION_CATEGORY_BOOST float64 `envconfig:"PERSONALIZATION_CATEGORY_BOOST" default:"2"`
+	PERSONALIZATION_TAG_BOOST      float64 `envconfig:"PERSONALIZATION_TAG_BOOST" default:"1.5"`
 }
 
 func main() {
@@ -21,10 +37,20 @@ func main() {
 		log.Fatal(err)
 	}
 
+	var newRepository func(url string) (catalog.Repository, error)
+	switch cfg.CATALOG_BACKEND {
+	case "elastic":
+		newRepository = catalog.NewElasticRepository
+	case "postgres":
+		newRepository = catalog.NewPostgresRepository
+	default:
+		log.Fatalf("unknown CATALOG_BACKEND %q, use elastic or postgres", cfg.CATALOG_BACKEND)
+	}
+
 	var r catalog.Repository
 
 	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
-		r, err = catalog.NewElasticRepository(cfg.DATABASE_URL)
+		r, err = newRepository(cfg.DATABASE_URL)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -32,8 +58,33 @@ func main() {
 	})
 	defer r.Close()
 
+	media, err := blob.NewFileStore(cfg.MEDIA_DIR, cfg.MEDIA_URL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		log.Printf("Serving images on port %d", cfg.MEDIA_PORT)
+		log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", cfg.MEDIA_PORT), blob.Handler(media)))
+	}()
+
+	personalization := catalog.Personalization{
+		CategoryBoost: cfg.PERSONALIZATION_CATEGORY_BOOST,
+		TagBoost:      cfg.PERSONALIZATION_TAG_BOOST,
+	}
+	if err := personalization.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.ORDER_SERVICE_URL != "" {
+		orderClient, err := order.NewClient(cfg.ORDER_SERVICE_URL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer orderClient.Close()
+		personalization.History = orderClient
+	}
+
 	log.Println("Listening on port 8080")
-	s := catalog.NewService(r)
+	s := catalog.NewService(r, media, personalization)
 	log.Fatal(catalog.ListenGRPC(s, 8080))
 
 }
@@ -0,0 +1,173 @@
+// Command catalogctl imports products into the catalog from CSV or JSONL files and exports them again,
+// manages the merchandising rules and the vocabulary of searches, and reports on what customers search for.
+//
+//	catalogctl import [-format csv|jsonl] [-dry-run] [-batch 500] products.csv
+//	catalogctl export [-format csv|jsonl] [-o products.jsonl]
+//	catalogctl rules list|put rule.json|delete id
+//	catalogctl vocabulary get|put vocabulary.json
+//	catalogctl searches [-since 168h] [-size 20]
+//
+// The catalog service is reached at CATALOG_SERVICE_URL.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	CatalogURL string `envconfig:"CATALOG_SERVICE_URL" default:"localhost:8080"`
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		log.Fatal("usage: catalogctl import|export|rules|vocabulary|searches [flags]")
+	}
+
+	var cfg Config
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := catalog.NewClient(cfg.CatalogURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	switch os.Args[1] {
+	case "import":
+		err = importProducts(client, os.Args[2:])
+	case "export":
+		err = exportProducts(client, os.Args[2:])
+	case "rules":
+		err = merchandisingRules(client, os.Args[2:])
+	case "vocabulary":
+		err = searchVocabulary(client, os.Args[2:])
+	case "searches":
+		err = searchReport(client, os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown command %q, use import, export, rules, vocabulary or searches", os.Args[1])
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func importProducts(client *catalog.Client, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "csv or jsonl; taken from the file extension by default")
+	dryRun := flags.Bool("dry-run", false, "only validate the rows")
+	batch := flags.Int("batch", 500, "rows per bulk request")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *batch <= 0 {
+		return errors.New("usage: catalogctl import [-format csv|jsonl] [-dry-run] [-batch n] file")
+	}
+
+	path := flags.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	reader, err := newRowReader(f, *format)
+	if err != nil {
+		return err
+	}
+
+	// Rows that cannot be parsed are reported along with those the catalog rejects
+	parseErrors := []catalog.RowError{}
+	next := func() ([]catalog.ProductRow, error) {
+		rows := []catalog.ProductRow{}
+		for len(rows) < *batch {
+			row, err := reader.Read()
+			var rowErr *rowError
+			if errors.As(err, &rowErr) {
+				parseErrors = append(parseErrors, catalog.RowError{Line: rowErr.line, Message: rowErr.err.Error()})
+				continue
+			}
+			if err == io.EOF && len(rows) > 0 {
+				return rows, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	}
+	res, err := client.ImportProducts(context.Background(), *dryRun, next)
+	if err != nil {
+		return err
+	}
+
+	errs := append(parseErrors, res.Errors...)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+	for _, e := range errs {
+		fmt.Printf("line %d: %s\n", e.Line, e.Message)
+	}
+	if *dryRun {
+		fmt.Printf("Dry run: %d rows valid, %d rows invalid\n", res.Imported, len(errs))
+	} else {
+		fmt.Printf("Imported %d products, %d rows failed\n", res.Imported, len(errs))
+	}
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func exportProducts(client *catalog.Client, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "jsonl", "csv or jsonl")
+	output := flags.String("o", "", "file to write to instead of stdout")
+	flags.Parse(args)
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	writer, err := newRowWriter(out, *format)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	err = client.ExportProducts(context.Background(), func(products []catalog.Product) error {
+		for _, p := range products {
+			if err := writer.Write(p); err != nil {
+				return err
+			}
+		}
+		count += len(products)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	log.Printf("Exported %d products", count)
+	return nil
+}
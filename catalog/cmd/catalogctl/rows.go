@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+)
+
+// Columns of the CSV files; only name and price are required
+var csvColumns = []string{"id", "name", "description", "price", "stock", "categoryId", "tags"}
+
+// Tags are separated within their CSV column
+const tagSeparator = "|"
+
+// jsonRow is a product as one line of a JSONL file
+type jsonRow struct {
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Price       *float64 `json:"price"`
+	Stock       *int64   `json:"stock,omitempty"`
+	CategoryID  string   `json:"categoryId,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// rowError is a row that could not be parsed; it is reported without being sent to the catalog
+type rowError struct {
+	line uint64
+	err  error
+}
+
+func (e *rowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.err)
+}
+
+type rowReader interface {
+	// Read returns the next row, a *rowError if it cannot be parsed, or io.EOF after the last one
+	Read() (catalog.ProductRow, error)
+}
+
+func newRowReader(r io.Reader, format string) (rowReader, error) {
+	switch format {
+	case "csv":
+		return newCSVReader(r)
+	case "jsonl":
+		s := bufio.NewScanner(r)
+		s.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &jsonlReader{scanner: s}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, use csv or jsonl", format)
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	// Index of each column in the records, by name
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// The header names the columns, in any order
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		known := false
+		for _, c := range csvColumns {
+			known = known || c == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown column %q, expected some of %s", name, strings.Join(csvColumns, ", "))
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Read() (catalog.ProductRow, error) {
+	record, err := r.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return catalog.ProductRow{}, &rowError{uint64(parseErr.StartLine), parseErr.Err}
+	}
+	if err != nil {
+		return catalog.ProductRow{}, err
+	}
+	line, _ := r.reader.FieldPos(0)
+	row := catalog.ProductRow{Line: uint64(line)}
+
+	field := func(name string) string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	row.ID = field("id")
+	row.Name = field("name")
+	row.Description = field("description")
+	row.CategoryID = field("categoryId")
+	if tags := field("tags"); tags != "" {
+		row.Tags = strings.Split(tags, tagSeparator)
+	}
+	if row.Price, err = strconv.ParseFloat(field("price"), 64); err != nil {
+		return row, &rowError{row.Line, fmt.Errorf("invalid price %q", field("price"))}
+	}
+	if stock := field("stock"); stock != "" {
+		n, err := strconv.ParseInt(stock, 10, 64)
+		if err != nil {
+			return row, &rowError{row.Line, fmt.Errorf("invalid stock %q", stock)}
+		}
+		row.Stock = &n
+	}
+	return row, nil
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    uint64
+}
+
+func (r *jsonlReader) Read() (catalog.ProductRow, error) {
+	// Skip blank lines
+	for r.scanner.Scan() {
+		r.line++
+		text := strings.TrimSpace(r.scanner.Text())
+		if text == "" {
+			continue
+		}
+		row := catalog.ProductRow{Line: r.line}
+		j := jsonRow{}
+		if err := json.Unmarshal([]byte(text), &j); err != nil {
+			return row, &rowError{r.line, err}
+		}
+		if j.Price == nil {
+			return row, &rowError{r.line, errors.New("price is required")}
+		}
+		row.ID = j.ID
+		row.Name = j.Name
+		row.Description = j.Description
+		row.Price = *j.Price
+		row.Stock = j.Stock
+		row.CategoryID = j.CategoryID
+		row.Tags = j.Tags
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return catalog.ProductRow{}, err
+	}
+	return catalog.ProductRow{}, io.EOF
+}
+
+type rowWriter interface {
+	Write(p catalog.Product) error
+	Flush() error
+}
+
+func newRowWriter(w io.Writer, format string) (rowWriter, error) {
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvColumns); err != nil {
+			return nil, err
+		}
+		return &csvWriter{writer}, nil
+	case "jsonl":
+		return &jsonlWriter{json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, use csv or jsonl", format)
+}
+
+// Products are written in the format they are imported in, so an export can be imported again
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(p catalog.Product) error {
+	return w.writer.Write([]string{
+		p.ID,
+		p.Name,
+		p.Description,
+		strconv.FormatFloat(p.Price, 'f', -1, 64),
+		strconv.FormatInt(p.Stock, 10),
+		p.CategoryID,
+		strings.Join(p.Tags, tagSeparator),
+	})
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type jsonlWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonlWriter) Write(p catalog.Product) error {
+	return w.encoder.Encode(jsonRow{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       &p.Price,
+		Stock:       &p.Stock,
+		CategoryID:  p.CategoryID,
+		Tags:        p.Tags,
+	})
+}
+
+func (w *jsonlWriter) Flush() error {
+	return nil
+}
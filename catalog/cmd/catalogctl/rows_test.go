@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+)
+
+func TestRowReader(t *testing.T) {
+	stock := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		// The rows read, and the lines of those that failed to parse
+		want   []catalog.ProductRow
+		failed []uint64
+	}{
+		{
+			name:   "csv",
+			format: "csv",
+			input: "id,name,description,price,stock,categoryId,tags\n" +
+				"boot, Alpine Boot ,Warm,120,4,shoes,winter|hiking\n" +
+				",Beach Sandal,,25,,,\n",
+			want: []catalog.ProductRow{
+				{Line: 2, ID: "boot", Name: "Alpine Boot", Description: "Warm", Price: 120, Stock: stock(4), CategoryID: "shoes", Tags: []string{"winter", "hiking"}},
+				{Line: 3, Name: "Beach Sandal", Price: 25},
+			},
+		},
+		{
+			name:   "csv columns in any order, some left out",
+			format: "csv",
+			input:  "price,name\n25,Beach Sandal\n30\n",
+			want: []catalog.ProductRow{
+				{Line: 2, Name: "Beach Sandal", Price: 25},
+				{Line: 3, Price: 30},
+			},
+		},
+		{
+			name:   "csv lines that don't parse",
+			format: "csv",
+			input: "name,price,stock\n" +
+				"Alpine Boot,cheap,1\n" +
+				"Beach Sandal,25,many\n" +
+				"\"Sun Hat,15\n",
+			failed: []uint64{2, 3, 4},
+		},
+		{
+			name:   "jsonl",
+			format: "jsonl",
+			input: `{"id":"boot","name":"Alpine Boot","description":"Warm","price":120,"stock":4,"categoryId":"shoes","tags":["winter"]}` + "\n" +
+				"\n" +
+				`{"name":"Beach Sandal","price":0}` + "\n",
+			want: []catalog.ProductRow{
+				{Line: 1, ID: "boot", Name: "Alpine Boot", Description: "Warm", Price: 120, Stock: stock(4), CategoryID: "shoes", Tags: []string{"winter"}},
+				{Line: 3, Name: "Beach Sandal"},
+			},
+		},
+		{
+			name:   "jsonl lines that don't parse",
+			format: "jsonl",
+			input: `{"name":"Alpine Boot"}` + "\n" +
+				`{"name":"Beach Sandal","price":"25"}` + "\n" +
+				`{"name":"Sun Hat",` + "\n" +
+				`{"name":"Linen Shirt","price":30}` + "\n",
+			want:   []catalog.ProductRow{{Line: 4, Name: "Linen Shirt", Price: 30}},
+			failed: []uint64{1, 2, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := newRowReader(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows := []catalog.ProductRow{}
+			failed := []uint64{}
+			for {
+				row, err := reader.Read()
+				if err == io.EOF {
+					break
+				}
+				var rowErr *rowError
+				if errors.As(err, &rowErr) {
+					failed = append(failed, rowErr.line)
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				rows = append(rows, row)
+			}
+			if tt.want == nil {
+				tt.want = []catalog.ProductRow{}
+			}
+			if tt.failed == nil {
+				tt.failed = []uint64{}
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("got rows %+v, want %+v", rows, tt.want)
+			}
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Errorf("got lines %v failing, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestNewRowReaderRejectsHeaders(t *testing.T) {
+	for _, header := range []string{"", "name,price,colour", "id,name", "id,price"} {
+		if _, err := newRowReader(strings.NewReader(header+"\n"), "csv"); err == nil {
+			t.Errorf("got a reader for the header %q, want an error", header)
+		}
+	}
+	if _, err := newRowReader(strings.NewReader(""), "xml"); err == nil {
+		t.Error("got a reader for the xml format, want an error")
+	}
+}
+
+func TestRowWriterRoundTrips(t *testing.T) {
+	product := catalog.Product{ID: "boot", Name: "Alpine Boot", Description: "Warm, dry", Price: 120.5, Stock: 4, CategoryID: "shoes", Tags: []string{"winter", "hiking"}}
+	want := catalog.ProductRow{ID: "boot", Name: "Alpine Boot", Description: "Warm, dry", Price: 120.5, Stock: &product.Stock, CategoryID: "shoes", Tags: []string{"winter", "hiking"}}
+
+	for _, format := range []string{"csv", "jsonl"} {
+		t.Run(format, func(t *testing.T) {
+			out := &strings.Builder{}
+			writer, err := newRowWriter(out, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := writer.Write(product); err != nil {
+				t.Fatal(err)
+			}
+			if err := writer.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			// Exports can be imported again as they are
+			reader, err := newRowReader(strings.NewReader(out.String()), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			row, err := reader.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			row.Line = 0
+			if !reflect.DeepEqual(row, want) {
+				t.Errorf("got %+v, want %+v", row, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+)
+
+const rulesUsage = "usage: catalogctl rules list|put rule.json|delete id"
+
+// Rules are written and read as JSON, one rule per line when listed
+func merchandisingRules(client *catalog.Client, args []string) error {
+	if len(args) == 0 {
+		return errors.New(rulesUsage)
+	}
+	ctx := context.Background()
+	out := json.NewEncoder(os.Stdout)
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		rules, err := client.GetMerchandisingRules(ctx)
+		if err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			if err := out.Encode(rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	case args[0] == "put" && len(args) == 2:
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		var rule catalog.MerchandisingRule
+		if err := json.Unmarshal(data, &rule); err != nil {
+			return err
+		}
+		saved, err := client.PutMerchandisingRule(ctx, rule)
+		if err != nil {
+			return err
+		}
+		return out.Encode(saved)
+	case args[0] == "delete" && len(args) == 2:
+		return client.DeleteMerchandisingRule(ctx, args[1])
+	}
+	return errors.New(rulesUsage)
+}
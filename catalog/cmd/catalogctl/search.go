@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+)
+
+// The vocabulary is written and read as JSON, replacing the whole of it when put
+func searchVocabulary(client *catalog.Client, args []string) error {
+	ctx := context.Background()
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+
+	switch {
+	case len(args) == 1 && args[0] == "get":
+		v, err := client.GetSearchVocabulary(ctx)
+		if err != nil {
+			return err
+		}
+		return out.Encode(v)
+	case len(args) == 2 && args[0] == "put":
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		var v catalog.SearchVocabulary
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		saved, err := client.PutSearchVocabulary(ctx, v)
+		if err != nil {
+			return err
+		}
+		return out.Encode(saved)
+	}
+	return errors.New("usage: catalogctl vocabulary get|put vocabulary.json")
+}
+
+func searchReport(client *catalog.Client, args []string) error {
+	flags := flag.NewFlagSet("searches", flag.ExitOnError)
+	since := flags.Duration("since", 7*24*time.Hour, "how far back to report on")
+	size := flags.Uint64("size", 20, "queries to list")
+	flags.Parse(args)
+
+	report, err := client.GetSearchReport(context.Background(), time.Now().Add(-*since), *size)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d searches since %s, %d without results\n", report.Searches, report.Since.Format(time.RFC3339), report.ZeroResultSearches)
+	fmt.Println("\nTop queries:")
+	for _, q := range report.TopQueries {
+		fmt.Printf("%8d  %s\n", q.Searches, q.Query)
+	}
+	fmt.Println("\nQueries without results:")
+	for _, q := range report.ZeroResultQueries {
+		fmt.Printf("%8d  %s\n", q.Searches, q.Query)
+	}
+	return nil
+}
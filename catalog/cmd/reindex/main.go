@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	DATABASE_URL string `envconfig:"DATABASE_URL"`
+}
+
+// Copies the catalog into a new index with the current mappings and switches the catalog alias over to it
+func main() {
+	var cfg Config
+
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	index, copied, err := catalog.ReindexElastic(context.Background(), cfg.DATABASE_URL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Copied %d products into %s, catalog now points at it", copied, index)
+}
@@ -0,0 +1,260 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrImageNotFound = errors.New("image not found")
+	ErrInvalidImage  = errors.New("image must be a JPEG, PNG or GIF of at most 10 MB and 40 megapixels")
+)
+
+const (
+	// Largest image file accepted, and the most pixels it may decode to
+	MaxImageSize   = 10 << 20
+	maxImagePixels = 40_000_000
+	// Thumbnails fit into a square of this many pixels
+	thumbnailSize = 256
+)
+
+// ProductImage is a picture of a product, stored in the blob store with a thumbnail next to it.
+// The images of a product are kept in the order they are shown in.
+type ProductImage struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+	// Where the image and its thumbnail are in the blob store
+	Key          string `json:"key"`
+	ThumbnailKey string `json:"thumbnailKey"`
+	AltText      string `json:"altText"`
+	ContentType  string `json:"contentType"`
+	// Size of the image as uploaded
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ImageUpload is an image to add to a product, at Position among its images or after them if it is nil
+type ImageUpload struct {
+	Data     []byte
+	AltText  string
+	Position *int
+}
+
+// ImageUpdate holds the details of an image to change; nil fields are left as they are
+type ImageUpdate struct {
+	AltText  *string
+	Position *int
+}
+
+// Stores the image and a thumbnail of it, and adds it to the product
+func (s *catalogService) UploadProductImage(ctx context.Context, productID string, upload ImageUpload) (*Product, error) {
+	if len(upload.Data) == 0 || len(upload.Data) > MaxImageSize {
+		return nil, ErrInvalidImage
+	}
+	if upload.Position != nil && *upload.Position < 0 {
+		return nil, ErrInvalidImage
+	}
+	// Don't store files for a product that doesn't exist
+	if _, err := s.repository.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	// Check the size before decoding, so that a small file can't expand into an enormous image
+	config, format, err := image.DecodeConfig(bytes.NewReader(upload.Data))
+	if err != nil || config.Width*config.Height > maxImagePixels {
+		return nil, ErrInvalidImage
+	}
+	img, _, err := image.Decode(bytes.NewReader(upload.Data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	// Thumbnails of JPEGs stay JPEGs, the others become PNGs to keep their transparency
+	thumbnail := &bytes.Buffer{}
+	thumbnailFormat := "png"
+	if format == "jpeg" {
+		thumbnailFormat = "jpeg"
+		err = jpeg.Encode(thumbnail, resize(img, thumbnailSize), &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(thumbnail, resize(img, thumbnailSize))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id := ksuid.New().String()
+	productImage := ProductImage{
+		ID:           id,
+		Key:          fmt.Sprintf("products/%s/%s.%s", productID, id, imageExtensions[format]),
+		ThumbnailKey: fmt.Sprintf("products/%s/%s_thumb.%s", productID, id, imageExtensions[thumbnailFormat]),
+		AltText:      upload.AltText,
+		ContentType:  "image/" + format,
+		Width:        config.Width,
+		Height:       config.Height,
+	}
+	productImage.URL = s.media.URL(productImage.Key)
+	productImage.ThumbnailURL = s.media.URL(productImage.ThumbnailKey)
+
+	if err := s.media.Put(ctx, productImage.Key, productImage.ContentType, bytes.NewReader(upload.Data)); err != nil {
+		return nil, err
+	}
+	if err := s.media.Put(ctx, productImage.ThumbnailKey, "image/"+thumbnailFormat, thumbnail); err != nil {
+		s.deleteImageFiles(ctx, productImage)
+		return nil, err
+	}
+
+	// Added in place, so that images uploaded or changed meanwhile are kept
+	product, err := s.repository.AddProductImage(ctx, productID, productImage, upload.Position)
+	if err != nil {
+		s.deleteImageFiles(ctx, productImage)
+		return nil, err
+	}
+	return product, nil
+}
+
+// Changes the alt text of an image of the product, or moves it to another position
+func (s *catalogService) UpdateProductImage(ctx context.Context, productID string, imageID string, update ImageUpdate) (*Product, error) {
+	if update.Position != nil && *update.Position < 0 {
+		return nil, ErrInvalidImage
+	}
+	return s.repository.UpdateProductImage(ctx, productID, imageID, update)
+}
+
+// Removes an image from the product, and then its files from the blob store
+func (s *catalogService) DeleteProductImage(ctx context.Context, productID string, imageID string) (*Product, error) {
+	product, err := s.repository.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	i := imageIndex(product.Images, imageID)
+	if i < 0 {
+		return nil, ErrImageNotFound
+	}
+
+	// The files of an image never change, so they can be looked up before it is removed
+	productImage := product.Images[i]
+	product, err = s.repository.RemoveProductImage(ctx, productID, imageID)
+	if err != nil {
+		return nil, err
+	}
+	s.deleteImageFiles(ctx, productImage)
+	return product, nil
+}
+
+// Files that can't be deleted are left behind, as nothing refers to them any more. They are deleted even
+// if the request was canceled meanwhile, since that is often why the image couldn't be added.
+func (s *catalogService) deleteImageFiles(ctx context.Context, productImage ProductImage) {
+	ctx = context.WithoutCancel(ctx)
+	s.media.Delete(ctx, productImage.Key)
+	s.media.Delete(ctx, productImage.ThumbnailKey)
+}
+
+// File extensions of the formats images are decoded from and encoded to
+var imageExtensions = map[string]string{
+	"jpeg": "jpg",
+	"png":  "png",
+	"gif":  "gif",
+}
+
+func imageIndex(images []ProductImage, id string) int {
+	for i, img := range images {
+		if img.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Adds an image at a position among those of the product, or after them if the position is nil
+func addImage(p *Product, img ProductImage, position *int) {
+	at := len(p.Images)
+	if position != nil {
+		at = *position
+	}
+	p.Images = insertImage(p.Images, img, at)
+}
+
+func updateImage(p *Product, id string, update ImageUpdate) error {
+	i := imageIndex(p.Images, id)
+	if i < 0 {
+		return ErrImageNotFound
+	}
+	img := p.Images[i]
+	if update.AltText != nil {
+		img.AltText = *update.AltText
+	}
+	position := i
+	if update.Position != nil {
+		position = *update.Position
+	}
+	images := append(append([]ProductImage{}, p.Images[:i]...), p.Images[i+1:]...)
+	p.Images = insertImage(images, img, position)
+	return nil
+}
+
+func removeImage(p *Product, id string) error {
+	i := imageIndex(p.Images, id)
+	if i < 0 {
+		return ErrImageNotFound
+	}
+	p.Images = append(append([]ProductImage{}, p.Images[:i]...), p.Images[i+1:]...)
+	return nil
+}
+
+// Inserts the image at a position, or appends it if the position is past the end
+func insertImage(images []ProductImage, img ProductImage, position int) []ProductImage {
+	if position > len(images) {
+		position = len(images)
+	}
+	result := append([]ProductImage{}, images[:position]...)
+	result = append(result, img)
+	return append(result, images[position:]...)
+}
+
+// resize scales an image down to fit into a square of the given size, keeping its aspect ratio. Every
+// pixel of the result is the average of the pixels it covers, which keeps thin lines and text legible.
+// Images that already fit are copied as they are.
+func resize(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if w > size || h > size {
+		if w >= h {
+			dw, dh = size, max(1, h*size/w)
+		} else {
+			dw, dh = max(1, w*size/h), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := bounds.Min.Y+y*h/dh, bounds.Min.Y+(y+1)*h/dh
+		for x := 0; x < dw; x++ {
+			x0, x1 := bounds.Min.X+x*w/dw, bounds.Min.X+(x+1)*w/dw
+			// Colors are averaged premultiplied by their alpha, so transparent pixels don't darken edges
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(b / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
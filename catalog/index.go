@@ -0,0 +1,544 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	elastic "gopkg.in/olivere/elastic.v5"
+)
+
+// Products are read and written through the catalog alias, which points at one versioned index
+// named catalog_v1, catalog_v2 and so on. Reindexing builds the next version and swaps the alias over.
+const catalogAlias = "catalog"
+
+// Settings of the versioned catalog indices. Text is folded to lowercase ASCII and lightly stemmed,
+// so that e.g. "Café Shoes" matches "cafe shoe". Every locale has an analyzer of its own, stemming the
+// words of its language.
+func productSettings(v SearchVocabulary) map[string]interface{} {
+	analysis := searchAnalysis(v)
+	analyzers := analysis["analyzer"].(map[string]interface{})
+	for code, l := range locales {
+		analyzers[textAnalyzer(code)] = map[string]interface{}{
+			"type":      "custom",
+			"tokenizer": "standard",
+			"filter":    []string{"lowercase", "asciifolding", l.language + "_stemmer"},
+		}
+	}
+	return map[string]interface{}{"analysis": analysis}
+}
+
+// searchAnalysis holds the analyzers of search queries, which also leave out the stopwords of the vocabulary
+// and add the synonyms of its terms. They only analyze queries, so the vocabulary doesn't change how products
+// are indexed, but as the analyzers of an open index are fixed, the catalog is still reindexed to change it.
+// Synonyms of several words need a graph filter, which only works on queries anyway.
+func searchAnalysis(v SearchVocabulary) map[string]interface{} {
+	var stopwords interface{} = "_none_"
+	if len(v.Stopwords) > 0 {
+		stopwords = v.Stopwords
+	}
+	filters := map[string]interface{}{
+		"product_stopwords": map[string]interface{}{"type": "stop", "stopwords": stopwords},
+	}
+	chain := []string{"lowercase", "asciifolding", "product_stopwords"}
+	if len(v.Synonyms) > 0 {
+		rules := []string{}
+		for _, group := range v.Synonyms {
+			rules = append(rules, strings.Join(group, ", "))
+		}
+		filters["product_synonyms"] = map[string]interface{}{"type": "synonym_graph", "synonyms": rules}
+		chain = append(chain, "product_synonyms")
+	}
+
+	analyzers := map[string]interface{}{}
+	for code, l := range locales {
+		stemmer := l.language + "_stemmer"
+		filters[stemmer] = map[string]interface{}{"type": "stemmer", "language": l.stemmer}
+		analyzers[searchAnalyzer(code)] = map[string]interface{}{
+			"type":      "custom",
+			"tokenizer": "standard",
+			"filter":    append(slices.Clone(chain), stemmer),
+		}
+	}
+	return map[string]interface{}{"filter": filters, "analyzer": analyzers}
+}
+
+// textAnalyzer names the analyzer of the text of a locale; the default locale's is the one product
+// names and descriptions were analyzed with before there were translations
+func textAnalyzer(locale string) string {
+	if locale == DefaultLocale {
+		return "product_text"
+	}
+	return "product_text_" + locale
+}
+
+func searchAnalyzer(locale string) string {
+	return textAnalyzer(locale) + "_search"
+}
+
+// Explicit mappings of the catalog indices, so that categories and tags are matched exactly instead of
+// being analyzed like text
+var productMapping = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"name": map[string]interface{}{
+			"type":            "text",
+			"analyzer":        "product_text",
+			"search_analyzer": "product_text_search",
+			"fields": map[string]interface{}{
+				"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+			},
+		},
+		"description": map[string]interface{}{
+			"type":            "text",
+			"analyzer":        "product_text",
+			"search_analyzer": "product_text_search",
+		},
+		"price":         map[string]interface{}{"type": "double"},
+		"stock":         map[string]interface{}{"type": "long"},
+		"createdAt":     map[string]interface{}{"type": "date"},
+		"categoryId":    map[string]interface{}{"type": "keyword"},
+		"categoryPath":  map[string]interface{}{"type": "keyword"},
+		"tags":          map[string]interface{}{"type": "keyword"},
+		"suggest":       map[string]interface{}{"type": "completion"},
+		"archived":      map[string]interface{}{"type": "boolean"},
+		"variants":      variantMapping,
+		"averageRating": map[string]interface{}{"type": "double"},
+		"ratingCount":   map[string]interface{}{"type": "long"},
+		"translations":  translationsMapping(),
+		"images":        map[string]interface{}{"type": "object", "enabled": false},
+		// Only kept until they are copied into the price_changes index
+		"pendingPriceChanges": map[string]interface{}{"type": "object", "enabled": false},
+	},
+}
+
+// Translations are objects keyed by locale, with the name and description analyzed for its language
+func translationsMapping() map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, code := range translatedLocales() {
+		properties[code] = map[string]interface{}{
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":            "text",
+					"analyzer":        textAnalyzer(code),
+					"search_analyzer": searchAnalyzer(code),
+					"fields": map[string]interface{}{
+						"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+					},
+				},
+				"description": map[string]interface{}{
+					"type":            "text",
+					"analyzer":        textAnalyzer(code),
+					"search_analyzer": searchAnalyzer(code),
+				},
+			},
+		}
+	}
+	return map[string]interface{}{"properties": properties}
+}
+
+var variantMapping = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"id":  map[string]interface{}{"type": "keyword"},
+		"sku": map[string]interface{}{"type": "keyword"},
+		"options": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"name":  map[string]interface{}{"type": "keyword"},
+				"value": map[string]interface{}{"type": "keyword"},
+			},
+		},
+		"price": map[string]interface{}{"type": "double"},
+		"stock": map[string]interface{}{"type": "long"},
+	},
+}
+
+var categoryMapping = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"name":     map[string]interface{}{"type": "text"},
+		"parentId": map[string]interface{}{"type": "keyword"},
+		"path":     map[string]interface{}{"type": "keyword"},
+	},
+}
+
+var priceChangeMapping = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"productId": map[string]interface{}{"type": "keyword"},
+		"variantId": map[string]interface{}{"type": "keyword"},
+		"oldPrice":  map[string]interface{}{"type": "double"},
+		"newPrice":  map[string]interface{}{"type": "double"},
+		"changedBy": map[string]interface{}{"type": "keyword"},
+		"changedAt": map[string]interface{}{"type": "date"},
+	},
+}
+
+// Rules are only looked up by ID or listed, so their lists are just stored
+var merchandisingRuleMapping = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"query":      map[string]interface{}{"type": "keyword"},
+		"categoryId": map[string]interface{}{"type": "keyword"},
+		"pinned":     map[string]interface{}{"type": "keyword", "index": false},
+		"boosts":     map[string]interface{}{"type": "object", "enabled": false},
+		"buried":     map[string]interface{}{"type": "keyword", "index": false},
+		"hidden":     map[string]interface{}{"type": "keyword", "index": false},
+	},
+}
+
+// The vocabulary is a single document, and its terms are only stored
+var searchVocabularyMapping = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"synonyms":  map[string]interface{}{"type": "keyword", "index": false},
+		"stopwords": map[string]interface{}{"type": "keyword", "index": false},
+	},
+}
+
+var searchLogMapping = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"query":      map[string]interface{}{"type": "keyword"},
+		"locale":     map[string]interface{}{"type": "keyword"},
+		"categoryId": map[string]interface{}{"type": "keyword"},
+		"hits":       map[string]interface{}{"type": "long"},
+		"searchedAt": map[string]interface{}{"type": "date"},
+	},
+}
+
+// catalogIndices describes the indices behind the catalog alias
+type catalogIndices struct {
+	// The versioned indices the alias points at, normally just one
+	current []string
+	// Whether catalog is still a plain index, as created before the alias was introduced
+	legacy bool
+	// The highest version of any catalog index, 0 if there is none
+	latestVersion int
+	// All versioned indices, whether the alias points at them or not
+	versions []string
+}
+
+func (r *elasticRepository) getCatalogIndices(ctx context.Context) (*catalogIndices, error) {
+	res, err := r.client.Aliases().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indices := &catalogIndices{current: res.IndicesByAlias(catalogAlias)}
+	for name := range res.Indices {
+		if name == catalogAlias {
+			indices.legacy = true
+			continue
+		}
+		version, ok := strings.CutPrefix(name, catalogAlias+"_v")
+		if !ok {
+			continue
+		}
+		v, err := strconv.Atoi(version)
+		if err != nil {
+			continue
+		}
+		indices.versions = append(indices.versions, name)
+		if v > indices.latestVersion {
+			indices.latestVersion = v
+		}
+	}
+	return indices, nil
+}
+
+// ensureIndices creates the first versioned catalog index with its alias, or puts the mappings of fields
+// added since into the existing one, and creates the categories and price changes indices. A legacy catalog
+// index, which may have been created by dynamic mapping, is used as it is until the next reindex.
+func (r *elasticRepository) ensureIndices(ctx context.Context) error {
+	// Catalog indices are created with the search vocabulary, so it has to be readable first
+	if err := r.ensureIndex(ctx, "search_vocabulary", "vocabulary", searchVocabularyMapping); err != nil {
+		return err
+	}
+	indices, err := r.getCatalogIndices(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case indices.legacy:
+		log.Println("catalog is a plain index, reindex to move it behind an alias")
+		properties := productMapping["properties"].(map[string]interface{})
+		_, err = r.client.PutMapping().Index(catalogAlias).Type("product").BodyJson(map[string]interface{}{
+			"properties": map[string]interface{}{
+				"createdAt":           properties["createdAt"],
+				"categoryId":          properties["categoryId"],
+				"categoryPath":        properties["categoryPath"],
+				"tags":                properties["tags"],
+				"suggest":             properties["suggest"],
+				"archived":            properties["archived"],
+				"variants":            properties["variants"],
+				"averageRating":       properties["averageRating"],
+				"ratingCount":         properties["ratingCount"],
+				"images":              properties["images"],
+				"pendingPriceChanges": properties["pendingPriceChanges"],
+			},
+		}).Do(ctx)
+	case len(indices.current) == 0:
+		index := fmt.Sprintf("%s_v%d", catalogAlias, indices.latestVersion+1)
+		if err = r.createProductIndex(ctx, index, nil); err == nil {
+			_, err = r.client.Alias().Add(index, catalogAlias).Do(ctx)
+		}
+	default:
+		mapping := productMapping
+		var analyzed bool
+		if analyzed, err = r.hasAnalyzers(ctx, textAnalyzer); err != nil {
+			return err
+		}
+		if !analyzed {
+			// The analyzers of an index can't be added while it is open, so until the next reindex
+			// translations are only stored, without being searchable
+			log.Println("catalog index predates translations, reindex to search them")
+			mapping = withProperty(mapping, "translations", map[string]interface{}{"type": "object", "enabled": false})
+		}
+		if analyzed, err = r.hasAnalyzers(ctx, searchAnalyzer); err != nil {
+			return err
+		}
+		if !analyzed {
+			// Reindexing blocks product changes while it runs, so it is left to the operator. Until then
+			// queries are analyzed like the text they search, without the search vocabulary.
+			log.Println("catalog index predates the search analyzers, reindex to apply the search vocabulary")
+			mapping = withoutSearchAnalyzers(mapping)
+		}
+		_, err = r.client.PutMapping().Index(catalogAlias).Type("product").BodyJson(mapping).Do(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := r.ensureIndex(ctx, "categories", "category", categoryMapping); err != nil {
+		return err
+	}
+	if err := r.ensureIndex(ctx, "price_changes", "priceChange", priceChangeMapping); err != nil {
+		return err
+	}
+	if err := r.ensureIndex(ctx, "merchandising_rules", "rule", merchandisingRuleMapping); err != nil {
+		return err
+	}
+	return r.ensureIndex(ctx, "search_log", "search", searchLogMapping)
+}
+
+// hasAnalyzers reports whether the indices behind the catalog alias have the analyzers, named by locale,
+// of all locales
+func (r *elasticRepository) hasAnalyzers(ctx context.Context, analyzer func(locale string) string) (bool, error) {
+	res, err := r.client.IndexGetSettings(catalogAlias).Do(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, index := range res {
+		settings, _ := index.Settings["index"].(map[string]interface{})
+		analysis, _ := settings["analysis"].(map[string]interface{})
+		analyzers, _ := analysis["analyzer"].(map[string]interface{})
+		for code := range locales {
+			if _, ok := analyzers[analyzer(code)]; !ok {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// withProperty returns a copy of a mapping with one of its properties replaced
+func withProperty(mapping map[string]interface{}, name string, property map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for k, v := range mapping["properties"].(map[string]interface{}) {
+		properties[k] = v
+	}
+	properties[name] = property
+	return map[string]interface{}{"properties": properties}
+}
+
+// withoutSearchAnalyzers returns a copy of a mapping whose text fields search with the analyzer they are
+// indexed with
+func withoutSearchAnalyzers(mapping map[string]interface{}) map[string]interface{} {
+	copied := map[string]interface{}{}
+	for k, v := range mapping {
+		if field, ok := v.(map[string]interface{}); ok {
+			v = withoutSearchAnalyzers(field)
+		}
+		copied[k] = v
+	}
+	if analyzer, ok := copied["analyzer"]; ok {
+		if _, ok := copied["search_analyzer"]; ok {
+			copied["search_analyzer"] = analyzer
+		}
+	}
+	return copied
+}
+
+// ensureIndex creates an index with the mapping of its single type, or puts the mapping of fields added
+// since into it if it exists
+func (r *elasticRepository) ensureIndex(ctx context.Context, index string, typ string, mapping map[string]interface{}) error {
+	exists, err := r.client.IndexExists(index).Do(ctx)
+	if err != nil {
+		return err
+	}
+	if exists {
+		_, err = r.client.PutMapping().Index(index).Type(typ).BodyJson(mapping).Do(ctx)
+		return err
+	}
+	_, err = r.client.CreateIndex(index).BodyJson(map[string]interface{}{
+		"mappings": map[string]interface{}{typ: mapping},
+	}).Do(ctx)
+	return err
+}
+
+// refresh makes everything written so far visible to searches, which Elasticsearch otherwise only guarantees
+// about a second later
+func (r *elasticRepository) refresh(ctx context.Context) error {
+	_, err := r.client.Refresh(catalogAlias, "categories", "price_changes", "merchandising_rules", "search_log").Do(ctx)
+	return err
+}
+
+// createProductIndex creates a versioned catalog index, with the search analyzers of the vocabulary if one
+// is given or else of the stored one
+func (r *elasticRepository) createProductIndex(ctx context.Context, index string, v *SearchVocabulary) error {
+	if v == nil {
+		var err error
+		if v, err = r.GetSearchVocabulary(ctx); err != nil {
+			return err
+		}
+	}
+	_, err := r.client.CreateIndex(index).BodyJson(map[string]interface{}{
+		"settings": productSettings(*v),
+		"mappings": map[string]interface{}{"product": productMapping},
+	}).Do(ctx)
+	return err
+}
+
+// ReindexElastic copies all products into a new version of the catalog index, created with the current
+// settings and mappings, and then atomically points the catalog alias at it, so searches never see a
+// partial index. It returns the name of the new index and how many products were copied.
+//
+// Writes to the catalog fail while the copy runs instead of landing in the index being replaced after
+// their product was copied. The previous index is kept for rolling back, except for a legacy plain catalog
+// index, which is removed in the same request that gives its name to the alias. Versions older than the
+// previous one are deleted.
+func ReindexElastic(ctx context.Context, url string) (string, uint64, error) {
+	client, err := elastic.NewClient(elastic.SetURL(url), elastic.SetSniff(false))
+	if err != nil {
+		return "", 0, err
+	}
+	r := &elasticRepository{client: client}
+	if err := r.ensureIndices(ctx); err != nil {
+		return "", 0, err
+	}
+	return r.reindex(ctx, nil)
+}
+
+// reindex builds the next version of the catalog index, with the analyzers of the given search vocabulary
+// or else the stored one, and moves the alias over to it
+func (r *elasticRepository) reindex(ctx context.Context, v *SearchVocabulary) (string, uint64, error) {
+	indices, index, err := r.createNextIndex(ctx, v)
+	if err != nil {
+		return "", 0, err
+	}
+	copied, err := r.fillIndex(ctx, indices, index)
+	if err != nil {
+		return "", 0, err
+	}
+	return index, copied, nil
+}
+
+// createNextIndex creates the next version of the catalog index, still empty, and returns it along with the
+// catalog indices it is going to replace
+func (r *elasticRepository) createNextIndex(ctx context.Context, v *SearchVocabulary) (*catalogIndices, string, error) {
+	indices, err := r.getCatalogIndices(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	index := fmt.Sprintf("%s_v%d", catalogAlias, indices.latestVersion+1)
+	if err := r.createProductIndex(ctx, index, v); err != nil {
+		return nil, "", err
+	}
+	return indices, index, nil
+}
+
+// fillIndex copies all products into an index made by createNextIndex and moves the alias over to it,
+// returning how many products were copied
+func (r *elasticRepository) fillIndex(ctx context.Context, indices *catalogIndices, index string) (uint64, error) {
+	// Block writes to the serving index until the alias has moved on. Once blocked it no longer changes, so
+	// the copy is complete, and writes in the meantime fail rather than getting lost in the old index.
+	serving := indices.current
+	if indices.legacy {
+		serving = []string{catalogAlias}
+	}
+	if err := r.blockWrites(ctx, serving, true); err != nil {
+		return 0, err
+	}
+	legacyRemoved := false
+	defer func() {
+		if legacyRemoved {
+			return
+		}
+		// Also after a failed or cancelled reindex, so the catalog stays writable
+		if err := r.blockWrites(context.WithoutCancel(ctx), serving, false); err != nil {
+			log.Println("could not unblock writes to the catalog: ", err)
+		}
+	}()
+
+	// Copy through the application rather than the reindex API, so derived fields like the
+	// suggestion inputs are computed for documents stored before they existed
+	copied := uint64(0)
+	err := r.ScrollProducts(ctx, func(products []Product) error {
+		bulk := r.client.Bulk().Index(index).Type("product")
+		for _, p := range products {
+			bulk.Add(elastic.NewBulkIndexRequest().Id(p.ID).Doc(documentFromProduct(p)))
+		}
+		res, err := bulk.Do(ctx)
+		if err != nil {
+			return err
+		}
+		if failed := res.Failed(); len(failed) > 0 {
+			return fmt.Errorf("could not copy product %s: %s", failed[0].Id, failed[0].Error.Reason)
+		}
+		copied += uint64(len(products))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.client.Refresh(index).Do(ctx); err != nil {
+		return 0, err
+	}
+
+	// Swap the alias over in a single request, which Elasticsearch applies atomically. A legacy index
+	// frees its name for the alias within that request, only after everything has been copied out of it.
+	swap := r.client.Alias()
+	for _, old := range indices.current {
+		swap = swap.Remove(old, catalogAlias)
+	}
+	if indices.legacy {
+		swap = swap.Action(removeIndexAction(catalogAlias))
+	}
+	if _, err := swap.Add(index, catalogAlias).Do(ctx); err != nil {
+		return 0, err
+	}
+	legacyRemoved = indices.legacy
+
+	// The alias has moved on, so failing to clean up doesn't fail the reindex
+	for _, old := range indices.versions {
+		if slices.Contains(indices.current, old) {
+			continue
+		}
+		if _, err := r.client.DeleteIndex(old).Do(ctx); err != nil {
+			log.Printf("could not delete the outdated catalog index %s: %v", old, err)
+		}
+	}
+	return copied, nil
+}
+
+// blockWrites makes indices read-only, or writable again
+func (r *elasticRepository) blockWrites(ctx context.Context, indices []string, blocked bool) error {
+	if len(indices) == 0 {
+		return nil
+	}
+	_, err := r.client.IndexPutSettings(indices...).BodyJson(map[string]interface{}{"index.blocks.write": blocked}).Do(ctx)
+	return err
+}
+
+// removeIndexAction deletes an index as part of an alias update, which the client has no action for
+type removeIndexAction string
+
+func (a removeIndexAction) Source() (interface{}, error) {
+	return map[string]interface{}{"remove_index": map[string]interface{}{"index": string(a)}}, nil
+}
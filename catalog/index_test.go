@@ -0,0 +1,28 @@
+package catalog
+
+import "testing"
+
+func TestWithoutSearchAnalyzers(t *testing.T) {
+	mapping := withoutSearchAnalyzers(productMapping)
+
+	properties := mapping["properties"].(map[string]interface{})
+	for _, name := range []string{"name", "description"} {
+		field := properties[name].(map[string]interface{})
+		if field["search_analyzer"] != "product_text" {
+			t.Errorf("got %s searched with %v, want product_text", name, field["search_analyzer"])
+		}
+	}
+	translations := properties["translations"].(map[string]interface{})["properties"].(map[string]interface{})
+	for _, code := range translatedLocales() {
+		field := translations[code].(map[string]interface{})["properties"].(map[string]interface{})["name"].(map[string]interface{})
+		if field["search_analyzer"] != textAnalyzer(code) {
+			t.Errorf("got the %s name searched with %v, want %s", code, field["search_analyzer"], textAnalyzer(code))
+		}
+	}
+
+	// The mapping new indices are created with keeps its search analyzers
+	name := productMapping["properties"].(map[string]interface{})["name"].(map[string]interface{})
+	if name["search_analyzer"] != searchAnalyzer(DefaultLocale) {
+		t.Errorf("got product names searched with %v, want %s", name["search_analyzer"], searchAnalyzer(DefaultLocale))
+	}
+}
@@ -0,0 +1,111 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidLocale = errors.New("unsupported locale")
+
+// DefaultLocale is the locale of a product's own name and description. Content in other locales is kept in
+// the product's translations, and anything missing from them falls back to the default locale.
+const DefaultLocale = "en"
+
+// How the text of a locale is analyzed for search
+type localeAnalysis struct {
+	// Name of the language, which is also the name of its Postgres text search configuration
+	language string
+	// Language of the Elasticsearch stemmer token filter
+	stemmer string
+}
+
+var locales = map[string]localeAnalysis{
+	"en": {language: "english", stemmer: "light_english"},
+	"de": {language: "german", stemmer: "light_german"},
+	"es": {language: "spanish", stemmer: "light_spanish"},
+	"fr": {language: "french", stemmer: "light_french"},
+	"it": {language: "italian", stemmer: "light_italian"},
+	"nl": {language: "dutch", stemmer: "dutch"},
+	"pt": {language: "portuguese", stemmer: "light_portuguese"},
+}
+
+// Locales lists the locales products can be translated into and searched in, including the default one
+func Locales() []string {
+	codes := []string{}
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// IsLocale reports whether locale is one of the supported locales
+func IsLocale(locale string) bool {
+	_, ok := locales[locale]
+	return ok
+}
+
+// translatedLocales lists the supported locales other than the default one, in a stable order
+func translatedLocales() []string {
+	codes := []string{}
+	for _, code := range Locales() {
+		if code != DefaultLocale {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// ProductTranslation is the name and description of a product in one locale. An empty field falls back to
+// the product's own.
+type ProductTranslation struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Localized returns the product with its name and description in the given locale, as far as they have been
+// translated into it
+func (p Product) Localized(locale string) Product {
+	t, ok := p.Translations[locale]
+	if !ok {
+		return p
+	}
+	if t.Name != "" {
+		p.Name = t.Name
+	}
+	if t.Description != "" {
+		p.Description = t.Description
+	}
+	return p
+}
+
+// Sets the name and description of a product in a locale other than the default one, or removes the
+// translation if both are empty
+func (s *catalogService) TranslateProduct(ctx context.Context, id string, locale string, name string, description string) (*Product, error) {
+	if !IsLocale(locale) || locale == DefaultLocale {
+		return nil, ErrInvalidLocale
+	}
+	product, err := s.repository.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	translations := map[string]ProductTranslation{}
+	for l, t := range product.Translations {
+		translations[l] = t
+	}
+	t := ProductTranslation{Name: strings.TrimSpace(name), Description: description}
+	if t.Name == "" && t.Description == "" {
+		delete(translations, locale)
+	} else {
+		translations[locale] = t
+	}
+	product.Translations = translations
+
+	if err := s.repository.UpdateProductTranslations(ctx, *product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
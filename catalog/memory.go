@@ -0,0 +1,654 @@
+package catalog
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryRepository keeps the catalog in memory, for development and tests. Products are searched for every
+// word of the query, or one of its synonyms, as a case-insensitive substring of their name or description, in
+// the default locale or the one searched in, ranking matches in the name first. Suggestions match the start of
+// any word of a product's name.
+type memoryRepository struct {
+	mu           sync.RWMutex
+	products     map[string]Product
+	categories   map[string]Category
+	priceChanges []PriceChange
+	rules        map[string]MerchandisingRule
+	vocabulary   SearchVocabulary
+	searches     []SearchLogEntry
+}
+
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		products:   map[string]Product{},
+		categories: map[string]Category{},
+		rules:      map[string]MerchandisingRule{},
+		vocabulary: SearchVocabulary{Synonyms: [][]string{}, Stopwords: []string{}},
+	}
+}
+
+func (r *memoryRepository) Close() {
+}
+
+// Copies a product, so that neither callers nor the repository see each other's changes to its slices
+func cloneProduct(p Product) Product {
+	p.CategoryPath = slices.Clone(stringsOrEmpty(p.CategoryPath))
+	p.Tags = slices.Clone(stringsOrEmpty(p.Tags))
+	variants := []Variant{}
+	for _, v := range p.Variants {
+		v.Options = slices.Clone(v.Options)
+		if v.Price != nil {
+			price := *v.Price
+			v.Price = &price
+		}
+		variants = append(variants, v)
+	}
+	p.Variants = variants
+	p.Translations = maps.Clone(p.Translations)
+	if p.Translations == nil {
+		p.Translations = map[string]ProductTranslation{}
+	}
+	p.Images = append([]ProductImage{}, p.Images...)
+	return p
+}
+
+func cloneCategory(c Category) Category {
+	c.Path = slices.Clone(stringsOrEmpty(c.Path))
+	return c
+}
+
+func cloneRule(rule MerchandisingRule) MerchandisingRule {
+	rule.Pinned = slices.Clone(stringsOrEmpty(rule.Pinned))
+	rule.Boosts = slices.Clone(boostsOrEmpty(rule.Boosts))
+	rule.Buried = slices.Clone(stringsOrEmpty(rule.Buried))
+	rule.Hidden = slices.Clone(stringsOrEmpty(rule.Hidden))
+	return rule
+}
+
+// Returns the products for which keep is true, sorted by ID
+func (r *memoryRepository) filterProducts(keep func(p Product) bool) []Product {
+	products := []Product{}
+	for _, p := range r.products {
+		if keep(p) {
+			products = append(products, cloneProduct(p))
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products
+}
+
+// Returns the page from skip to take of the items
+func page[T any](items []T, skip uint64, take uint64) []T {
+	if skip >= uint64(len(items)) {
+		return []T{}
+	}
+	items = items[skip:]
+	if take < uint64(len(items)) {
+		items = items[:take]
+	}
+	return items
+}
+
+func (r *memoryRepository) PutProduct(ctx context.Context, p Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p = cloneProduct(p)
+	p.version = r.products[p.ID].version + 1
+	r.products[p.ID] = p
+	return nil
+}
+
+func (r *memoryRepository) PutProducts(ctx context.Context, products []Product) ([]error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	errs := make([]error, len(products))
+	for i, p := range products {
+		stored := r.products[p.ID]
+		if stored.version != p.version {
+			errs[i] = ErrProductChanged
+			continue
+		}
+		p = cloneProduct(p)
+		p.version = stored.version + 1
+		r.products[p.ID] = p
+	}
+	return errs, nil
+}
+
+func (r *memoryRepository) GetProductByID(ctx context.Context, id string) (*Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.products[id]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+	p = cloneProduct(p)
+	return &p, nil
+}
+
+func (r *memoryRepository) ListProducts(ctx context.Context, skip uint64, take uint64) ([]Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	products := r.filterProducts(func(p Product) bool { return !p.Archived })
+	return page(products, skip, take), nil
+}
+
+// ListProductsWithIDs returns the products that exist in the order of the IDs. Like the other repositories
+// it looks all of them up, whatever skip and take are.
+func (r *memoryRepository) ListProductsWithIDs(ctx context.Context, ids []string, skip uint64, take uint64) ([]Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	products := []Product{}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if p, ok := r.products[id]; ok && !seen[id] {
+			products = append(products, cloneProduct(p))
+			seen[id] = true
+		}
+	}
+	return products, nil
+}
+
+// ScrollProducts passes all products to fn, a page at a time, stopping at the first error fn returns
+func (r *memoryRepository) ScrollProducts(ctx context.Context, fn func(products []Product) error) error {
+	// Take a snapshot first, so that fn may write to the repository
+	r.mu.RLock()
+	products := r.filterProducts(func(Product) bool { return true })
+	r.mu.RUnlock()
+
+	for len(products) > 0 {
+		n := min(len(products), scrollSize)
+		if err := fn(products[:n]); err != nil {
+			return err
+		}
+		products = products[n:]
+	}
+	return nil
+}
+
+func (r *memoryRepository) SearchProducts(ctx context.Context, query ProductQuery) (*ProductSearchResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Matches in the name rank above those only in the description, and boosts multiply the rank
+	text := normalizeQuery(query.Query)
+	clauses := r.vocabulary.expand(text)
+	scores := map[string]float64{}
+	filter := query.Filter
+	m := query.Merchandising
+	products := r.filterProducts(func(p Product) bool {
+		if p.Archived || slices.Contains(m.Hidden, p.ID) {
+			return false
+		}
+		scores[p.ID] = 1
+		// Pinned products are found whether they match or not
+		if text != "" && !slices.Contains(m.Pinned, p.ID) {
+			t := p.Translations[query.Locale]
+			contains := func(fields ...string) bool {
+				return len(clauses) > 0 && !slices.ContainsFunc(clauses, func(terms []string) bool {
+					return !slices.ContainsFunc(fields, func(f string) bool {
+						return slices.ContainsFunc(terms, func(term string) bool { return strings.Contains(strings.ToLower(f), term) })
+					})
+				})
+			}
+			switch {
+			case contains(p.Name, t.Name):
+				scores[p.ID] = 2
+			case contains(p.Name, t.Name, p.Description, t.Description):
+				scores[p.ID] = 1
+			default:
+				return false
+			}
+		}
+		if filter.CategoryID != "" && !slices.Contains(p.CategoryPath, filter.CategoryID) {
+			return false
+		}
+		for _, tag := range filter.Tags {
+			if !slices.Contains(p.Tags, tag) {
+				return false
+			}
+		}
+		if filter.MinPrice != nil && p.Price < *filter.MinPrice {
+			return false
+		}
+		if filter.MaxPrice != nil && p.Price > *filter.MaxPrice {
+			return false
+		}
+		for _, b := range m.Boosts {
+			if b.appliesTo(p) {
+				scores[p.ID] *= b.Factor
+			}
+		}
+		return !filter.InStock || p.Stock > 0
+	})
+
+	// Pinned products come first in their order, and buried ones after all others
+	tier := func(p Product) int {
+		if i := slices.Index(m.Pinned, p.ID); i >= 0 {
+			return i - len(m.Pinned)
+		}
+		if slices.Contains(m.Buried, p.ID) {
+			return 1
+		}
+		return 0
+	}
+
+	// Ties are broken by ID, which the products are already sorted by
+	sort.SliceStable(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		switch query.Sort {
+		case ProductSortPriceAsc:
+			return a.Price < b.Price
+		case ProductSortPriceDesc:
+			return a.Price > b.Price
+		case ProductSortNewest:
+			// Products without a creation time come last
+			return a.CreatedAt.After(b.CreatedAt)
+		case ProductSortName:
+			return a.Name < b.Name
+		case ProductSortRating:
+			if a.AverageRating != b.AverageRating {
+				return a.AverageRating > b.AverageRating
+			}
+			return a.RatingCount > b.RatingCount
+		}
+		if tier(a) != tier(b) {
+			return tier(a) < tier(b)
+		}
+		return scores[a.ID] > scores[b.ID]
+	})
+
+	result := &ProductSearchResult{
+		Products: page(products, query.Skip, query.Take),
+		Total:    uint64(len(products)),
+	}
+	result.Facets.Categories = countFacets(products, func(p Product) []string { return p.CategoryPath })
+	result.Facets.Tags = countFacets(products, func(p Product) []string { return p.Tags })
+	return result, nil
+}
+
+// Counts the products per value of a field, most frequent values first
+func countFacets(products []Product, values func(p Product) []string) []FacetCount {
+	counts := map[string]uint64{}
+	for _, p := range products {
+		for _, value := range values(p) {
+			counts[value]++
+		}
+	}
+	facets := []FacetCount{}
+	for value, count := range counts {
+		facets = append(facets, FacetCount{Value: value, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	return page(facets, 0, facetSize)
+}
+
+func (r *memoryRepository) SuggestProducts(ctx context.Context, prefix string, size uint64) ([]ProductSuggestion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Names starting with the prefix score higher than those with a later word starting with it
+	prefix = strings.ToLower(prefix)
+	suggestions := []ProductSuggestion{}
+	for _, p := range r.products {
+		if p.Archived {
+			continue
+		}
+		name := strings.ToLower(p.Name)
+		if strings.HasPrefix(name, prefix) {
+			suggestions = append(suggestions, ProductSuggestion{ID: p.ID, Name: p.Name, Score: 1})
+		} else if strings.Contains(name, " "+prefix) {
+			suggestions = append(suggestions, ProductSuggestion{ID: p.ID, Name: p.Name, Score: 0.5})
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+	return page(suggestions, 0, size), nil
+}
+
+// Applies update to a stored product, reporting a product that doesn't exist
+func (r *memoryRepository) updateProduct(id string, update func(p *Product)) error {
+	return r.modifyProduct(id, func(p *Product) error {
+		update(p)
+		return nil
+	})
+}
+
+// Like updateProduct, but leaves the product as it was if modify fails
+func (r *memoryRepository) modifyProduct(id string, modify func(p *Product) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.products[id]
+	if !ok {
+		return ErrProductNotFound
+	}
+	p = cloneProduct(p)
+	if err := modify(&p); err != nil {
+		return err
+	}
+	p.version++
+	r.products[id] = cloneProduct(p)
+	return nil
+}
+
+func (r *memoryRepository) AdjustStock(ctx context.Context, id string, variantID string, delta int64) error {
+	return r.modifyProduct(id, func(p *Product) error {
+		i := variantIndex(p.Variants, variantID)
+		if delta < 0 && (p.Stock+delta < 0 || (i >= 0 && p.Variants[i].Stock+delta < 0)) {
+			return ErrOutOfStock
+		}
+		p.Stock += delta
+		if i >= 0 {
+			p.Variants[i].Stock += delta
+		}
+		return nil
+	})
+}
+
+func (r *memoryRepository) UpdateProductDetails(ctx context.Context, p Product, change *PriceChange) error {
+	return r.modifyProduct(p.ID, func(stored *Product) error {
+		if change != nil {
+			if stored.Price != change.OldPrice {
+				return ErrProductChanged
+			}
+			r.priceChanges = append(r.priceChanges, *change)
+		}
+		stored.Name = p.Name
+		stored.Description = p.Description
+		stored.Price = p.Price
+		return nil
+	})
+}
+
+func (r *memoryRepository) AddVariant(ctx context.Context, productID string, v Variant) (*Product, error) {
+	return r.modifiedProduct(productID, func(p *Product) error {
+		addVariant(p, v)
+		return nil
+	})
+}
+
+func (r *memoryRepository) UpdateVariant(ctx context.Context, productID string, v Variant, change *PriceChange) (*Product, error) {
+	return r.modifiedProduct(productID, func(p *Product) error {
+		if err := updateVariant(p, v, change); err != nil {
+			return err
+		}
+		if change != nil {
+			r.priceChanges = append(r.priceChanges, *change)
+		}
+		return nil
+	})
+}
+
+func (r *memoryRepository) RemoveVariant(ctx context.Context, productID string, variantID string) (*Product, error) {
+	return r.modifiedProduct(productID, func(p *Product) error {
+		return removeVariant(p, variantID)
+	})
+}
+
+// Changes a product under the lock like modifyProduct, returning the product as it is afterwards
+func (r *memoryRepository) modifiedProduct(id string, modify func(p *Product) error) (*Product, error) {
+	var updated Product
+	err := r.modifyProduct(id, func(p *Product) error {
+		if err := modify(p); err != nil {
+			return err
+		}
+		updated = cloneProduct(*p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *memoryRepository) ArchiveProduct(ctx context.Context, id string) error {
+	return r.updateProduct(id, func(p *Product) {
+		p.Archived = true
+	})
+}
+
+func (r *memoryRepository) UpdateProductCategory(ctx context.Context, p Product) error {
+	return r.updateProduct(p.ID, func(stored *Product) {
+		stored.CategoryID = p.CategoryID
+		stored.CategoryPath = p.CategoryPath
+		stored.Tags = p.Tags
+	})
+}
+
+func (r *memoryRepository) UpdateProductRating(ctx context.Context, p Product) error {
+	return r.updateProduct(p.ID, func(stored *Product) {
+		stored.AverageRating = p.AverageRating
+		stored.RatingCount = p.RatingCount
+	})
+}
+
+func (r *memoryRepository) UpdateProductTranslations(ctx context.Context, p Product) error {
+	return r.updateProduct(p.ID, func(stored *Product) {
+		stored.Translations = maps.Clone(p.Translations)
+	})
+}
+
+func (r *memoryRepository) AddProductImage(ctx context.Context, productID string, img ProductImage, position *int) (*Product, error) {
+	return r.modifiedProduct(productID, func(p *Product) error {
+		addImage(p, img, position)
+		return nil
+	})
+}
+
+func (r *memoryRepository) UpdateProductImage(ctx context.Context, productID string, imageID string, update ImageUpdate) (*Product, error) {
+	return r.modifiedProduct(productID, func(p *Product) error {
+		return updateImage(p, imageID, update)
+	})
+}
+
+func (r *memoryRepository) RemoveProductImage(ctx context.Context, productID string, imageID string) (*Product, error) {
+	return r.modifiedProduct(productID, func(p *Product) error {
+		return removeImage(p, imageID)
+	})
+}
+
+// Lists the products, archived ones included, that have a variant with the SKU
+func (r *memoryRepository) ListProductsWithSKU(ctx context.Context, sku string) ([]Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.filterProducts(func(p Product) bool {
+		return slices.ContainsFunc(p.Variants, func(v Variant) bool { return v.SKU == sku })
+	}), nil
+}
+
+func (r *memoryRepository) PutPriceChanges(ctx context.Context, changes []PriceChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.priceChanges = append(r.priceChanges, changes...)
+	return nil
+}
+
+func (r *memoryRepository) ListPriceChanges(ctx context.Context, productID string, skip uint64, take uint64) ([]PriceChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Newest first; of changes made at the same time the one stored last comes first
+	changes := []PriceChange{}
+	for i := len(r.priceChanges) - 1; i >= 0; i-- {
+		if c := r.priceChanges[i]; c.ProductID == productID {
+			changes = append(changes, c)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].ChangedAt.After(changes[j].ChangedAt) })
+	return page(changes, skip, take), nil
+}
+
+func (r *memoryRepository) PutCategory(ctx context.Context, c Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.categories[c.ID] = cloneCategory(c)
+	return nil
+}
+
+func (r *memoryRepository) GetCategoryByID(ctx context.Context, id string) (*Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.categories[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	c = cloneCategory(c)
+	return &c, nil
+}
+
+func (r *memoryRepository) ListCategories(ctx context.Context) ([]Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	categories := []Category{}
+	for _, c := range r.categories {
+		categories = append(categories, cloneCategory(c))
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	return categories, nil
+}
+
+// A category is in use while it has subcategories or products in it
+func (r *memoryRepository) CategoryInUse(ctx context.Context, id string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.categories {
+		if c.ParentID == id {
+			return true, nil
+		}
+	}
+	for _, p := range r.products {
+		if p.CategoryID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryRepository) DeleteCategory(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.categories, id)
+	return nil
+}
+
+func (r *memoryRepository) PutMerchandisingRule(ctx context.Context, rule MerchandisingRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules[rule.ID] = cloneRule(rule)
+	return nil
+}
+
+func (r *memoryRepository) GetMerchandisingRule(ctx context.Context, id string) (*MerchandisingRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[id]
+	if !ok {
+		return nil, ErrRuleNotFound
+	}
+	rule = cloneRule(rule)
+	return &rule, nil
+}
+
+func (r *memoryRepository) ListMerchandisingRules(ctx context.Context) ([]MerchandisingRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rules := []MerchandisingRule{}
+	for _, rule := range r.rules {
+		rules = append(rules, cloneRule(rule))
+	}
+	return rules, nil
+}
+
+func (r *memoryRepository) DeleteMerchandisingRule(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rules, id)
+	return nil
+}
+
+func (r *memoryRepository) GetSearchVocabulary(ctx context.Context) (*SearchVocabulary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v := SearchVocabulary{Synonyms: [][]string{}, Stopwords: slices.Clone(r.vocabulary.Stopwords)}
+	for _, group := range r.vocabulary.Synonyms {
+		v.Synonyms = append(v.Synonyms, slices.Clone(group))
+	}
+	return &v, nil
+}
+
+func (r *memoryRepository) PutSearchVocabulary(ctx context.Context, v SearchVocabulary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.vocabulary = SearchVocabulary{Synonyms: [][]string{}, Stopwords: slices.Clone(v.Stopwords)}
+	for _, group := range v.Synonyms {
+		r.vocabulary.Synonyms = append(r.vocabulary.Synonyms, slices.Clone(group))
+	}
+	return nil
+}
+
+func (r *memoryRepository) LogSearch(ctx context.Context, entry SearchLogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.searches = append(r.searches, entry)
+	return nil
+}
+
+func (r *memoryRepository) GetSearchReport(ctx context.Context, since time.Time, size uint64) (*SearchReport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	report := &SearchReport{Since: since}
+	searches, zeroResults := map[string]uint64{}, map[string]uint64{}
+	for _, entry := range r.searches {
+		if entry.SearchedAt.Before(since) {
+			continue
+		}
+		report.Searches++
+		if entry.Hits == 0 {
+			report.ZeroResultSearches++
+		}
+		if entry.Query == "" {
+			continue
+		}
+		searches[entry.Query]++
+		if entry.Hits == 0 {
+			zeroResults[entry.Query]++
+		}
+	}
+	report.TopQueries = topQueries(searches, size)
+	report.ZeroResultQueries = topQueries(zeroResults, size)
+	return report, nil
+}
+
+// Lists the most searched queries, breaking ties by query
+func topQueries(counts map[string]uint64, size uint64) []QueryStats {
+	queries := []QueryStats{}
+	for query, n := range counts {
+		queries = append(queries, QueryStats{Query: query, Searches: n})
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Searches != queries[j].Searches {
+			return queries[i].Searches > queries[j].Searches
+		}
+		return queries[i].Query < queries[j].Query
+	})
+	return page(queries, 0, size)
+}
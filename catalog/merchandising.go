@@ -0,0 +1,208 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrRuleNotFound = errors.New("merchandising rule not found")
+	ErrInvalidRule  = errors.New("merchandising rule needs either a query or a category, at most 20 pinned products " +
+		"none of which are buried or hidden, and boosts of a tag or category by a factor above 0 and up to 10")
+)
+
+const (
+	maxPinnedProducts = 20
+	maxBoostFactor    = 10
+)
+
+// MerchandisingRule changes the results of the searches for a query, or of those narrowed down to a
+// category. Rules only reorder results sorted by relevance, but hide products from every search they apply to.
+type MerchandisingRule struct {
+	ID string `json:"id"`
+	// Queries match as a whole, ignoring case and extra spaces; a rule has either a query or a category
+	Query      string `json:"query"`
+	CategoryID string `json:"categoryId"`
+	// Products shown before all others in this order, as long as they pass the filters of the search, even if
+	// they don't match its query
+	Pinned []string         `json:"pinned"`
+	Boosts []AttributeBoost `json:"boosts"`
+	// Buried products come after all others, hidden ones are left out
+	Buried []string `json:"buried"`
+	Hidden []string `json:"hidden"`
+}
+
+type BoostAttribute string
+
+const (
+	BoostAttributeTag      BoostAttribute = "TAG"
+	BoostAttributeCategory BoostAttribute = "CATEGORY"
+)
+
+// AttributeBoost multiplies the relevance of the products with a tag, or in a category or its subcategories,
+// by Factor. Factors below 1 lower it.
+type AttributeBoost struct {
+	Attribute BoostAttribute `json:"attribute"`
+	Value     string         `json:"value"`
+	Factor    float64        `json:"factor"`
+}
+
+// Merchandising is what the rules that apply to a search do to its results, merged in the order the rules
+// were created in
+type Merchandising struct {
+	Pinned []string
+	Boosts []AttributeBoost
+	Buried []string
+	Hidden []string
+}
+
+// Reorders reports whether the merchandising changes the order of the results, rather than only hiding some
+func (m Merchandising) Reorders() bool {
+	return len(m.Pinned) > 0 || len(m.Boosts) > 0 || len(m.Buried) > 0
+}
+
+// appliesTo reports whether the product has the tag, or is in the category, the boost is for
+func (b AttributeBoost) appliesTo(p Product) bool {
+	if b.Attribute == BoostAttributeCategory {
+		return slices.Contains(p.CategoryPath, b.Value)
+	}
+	return slices.Contains(p.Tags, b.Value)
+}
+
+// Queries of rules and searches are compared in lower case, with single spaces between words
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// Creates a rule, or replaces the rule with the same ID
+func (s *catalogService) PutMerchandisingRule(ctx context.Context, rule MerchandisingRule) (*MerchandisingRule, error) {
+	rule.Query = normalizeQuery(rule.Query)
+	rule.CategoryID = strings.TrimSpace(rule.CategoryID)
+	if (rule.Query == "") == (rule.CategoryID == "") {
+		return nil, ErrInvalidRule
+	}
+	rule.Pinned = uniqueIDs(rule.Pinned)
+	rule.Buried = uniqueIDs(rule.Buried)
+	rule.Hidden = uniqueIDs(rule.Hidden)
+	if len(rule.Pinned) > maxPinnedProducts {
+		return nil, ErrInvalidRule
+	}
+	for _, id := range rule.Pinned {
+		if slices.Contains(rule.Buried, id) || slices.Contains(rule.Hidden, id) {
+			return nil, ErrInvalidRule
+		}
+	}
+	if rule.Boosts == nil {
+		rule.Boosts = []AttributeBoost{}
+	}
+	for i, b := range rule.Boosts {
+		// Tags are stored in lower case
+		switch b.Attribute {
+		case BoostAttributeTag:
+			b.Value = strings.ToLower(strings.TrimSpace(b.Value))
+		case BoostAttributeCategory:
+			b.Value = strings.TrimSpace(b.Value)
+		default:
+			return nil, ErrInvalidRule
+		}
+		if b.Value == "" || b.Factor <= 0 || b.Factor > maxBoostFactor {
+			return nil, ErrInvalidRule
+		}
+		rule.Boosts[i] = b
+	}
+
+	if rule.CategoryID != "" {
+		if _, err := s.repository.GetCategoryByID(ctx, rule.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+	if rule.ID == "" {
+		rule.ID = ksuid.New().String()
+	} else if _, err := s.repository.GetMerchandisingRule(ctx, rule.ID); err != nil {
+		return nil, err
+	}
+	if err := s.repository.PutMerchandisingRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Lists all rules, oldest first
+func (s *catalogService) GetMerchandisingRules(ctx context.Context) ([]MerchandisingRule, error) {
+	rules, err := s.repository.ListMerchandisingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
+
+func (s *catalogService) DeleteMerchandisingRule(ctx context.Context, id string) error {
+	if _, err := s.repository.GetMerchandisingRule(ctx, id); err != nil {
+		return err
+	}
+	return s.repository.DeleteMerchandisingRule(ctx, id)
+}
+
+// Merges the rules that apply to a search. Products pinned by an earlier rule stay in front of those pinned
+// by a later one, and products buried or hidden by any rule aren't pinned.
+func (s *catalogService) merchandising(ctx context.Context, query ProductQuery) (Merchandising, error) {
+	m := Merchandising{}
+	text := normalizeQuery(query.Query)
+	if text == "" && query.Filter.CategoryID == "" {
+		return m, nil
+	}
+	rules, err := s.GetMerchandisingRules(ctx)
+	if err != nil {
+		return m, err
+	}
+	for _, rule := range rules {
+		matches := (rule.Query != "" && rule.Query == text) ||
+			(rule.CategoryID != "" && rule.CategoryID == query.Filter.CategoryID)
+		if !matches {
+			continue
+		}
+		m.Pinned = append(m.Pinned, rule.Pinned...)
+		m.Boosts = append(m.Boosts, rule.Boosts...)
+		m.Buried = append(m.Buried, rule.Buried...)
+		m.Hidden = append(m.Hidden, rule.Hidden...)
+	}
+	m.Buried = uniqueIDs(m.Buried)
+	m.Hidden = uniqueIDs(m.Hidden)
+	m.Pinned = slices.DeleteFunc(uniqueIDs(m.Pinned), func(id string) bool {
+		return slices.Contains(m.Buried, id) || slices.Contains(m.Hidden, id)
+	})
+	if len(m.Pinned) > maxPinnedProducts {
+		m.Pinned = m.Pinned[:maxPinnedProducts]
+	}
+
+	// Other sorts only leave out the hidden products
+	if query.Sort != ProductSortRelevance {
+		m = Merchandising{Hidden: m.Hidden}
+	}
+	return m, nil
+}
+
+// Drops empty and repeated IDs, keeping the first of each
+func uniqueIDs(ids []string) []string {
+	unique := []string{}
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id != "" && !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func boostsOrEmpty(boosts []AttributeBoost) []AttributeBoost {
+	if boosts == nil {
+		return []AttributeBoost{}
+	}
+	return boosts
+}
@@ -0,0 +1,111 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// Most categories, and most tags, a personalized search favours
+const maxPersonalBoosts = 10
+
+var ErrInvalidPersonalization = fmt.Errorf("personalization boosts must be above 0 and at most %d", maxBoostFactor)
+
+// PurchaseHistory tells how many orders of an account each product it bought was in, by product ID. The
+// order client provides it; this package can't import the order service's, which imports this one.
+type PurchaseHistory interface {
+	GetPurchaseCounts(ctx context.Context, accountID string) (map[string]uint64, error)
+}
+
+// Personalization ranks the searches of accounts that opt in by what they bought before. Products in the
+// category the account bought from in the most orders rank CategoryBoost times higher, and products with
+// the tag it bought most TagBoost times higher; other categories and tags less so, in proportion to their
+// orders. Searches aren't personalized without a History, and boosts of 1 turn either kind off.
+//
+// Products have no brand of their own. A brand is a tag like any other, e.g. "brand-acme", so accounts'
+// favourite brands rank higher through TagBoost, together with the other tags they bought.
+type Personalization struct {
+	History       PurchaseHistory
+	CategoryBoost float64
+	TagBoost      float64
+}
+
+// Validate checks that the boosts are within the factors merchandising rules may boost by, so that what an
+// account bought before can't outweigh the relevance to its query by orders of magnitude
+func (p Personalization) Validate() error {
+	if p.CategoryBoost <= 0 || p.CategoryBoost > maxBoostFactor || p.TagBoost <= 0 || p.TagBoost > maxBoostFactor {
+		return ErrInvalidPersonalization
+	}
+	return nil
+}
+
+// Boosts the categories and tags of the products the account bought. Products that no longer exist are
+// skipped, and searches go on without personalization if the purchase history can't be read.
+func (s *catalogService) personalBoosts(ctx context.Context, accountID string) []AttributeBoost {
+	p := s.personalization
+	if accountID == "" || p.History == nil || (p.CategoryBoost == 1 && p.TagBoost == 1) {
+		return nil
+	}
+	counts, err := p.History.GetPurchaseCounts(ctx, accountID)
+	if err != nil {
+		log.Println("Error reading purchase history: ", err)
+		return nil
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	ids := []string{}
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	products, err := s.repository.ListProductsWithIDs(ctx, ids, 0, uint64(len(ids)))
+	if err != nil {
+		log.Println("Error reading purchased products: ", err)
+		return nil
+	}
+
+	categories, tags := map[string]uint64{}, map[string]uint64{}
+	for _, product := range products {
+		if product.CategoryID != "" {
+			categories[product.CategoryID] += counts[product.ID]
+		}
+		for _, tag := range product.Tags {
+			tags[tag] += counts[product.ID]
+		}
+	}
+	boosts := affinityBoosts(BoostAttributeCategory, categories, p.CategoryBoost)
+	return append(boosts, affinityBoosts(BoostAttributeTag, tags, p.TagBoost)...)
+}
+
+// affinityBoosts turns the orders per category or tag into boosts, scaled so that the one with the most
+// orders gets the full factor
+func affinityBoosts(attribute BoostAttribute, orders map[string]uint64, factor float64) []AttributeBoost {
+	if factor == 1 || len(orders) == 0 {
+		return nil
+	}
+	values := []string{}
+	for value := range orders {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if orders[values[i]] != orders[values[j]] {
+			return orders[values[i]] > orders[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	if len(values) > maxPersonalBoosts {
+		values = values[:maxPersonalBoosts]
+	}
+
+	most := float64(orders[values[0]])
+	boosts := []AttributeBoost{}
+	for _, value := range values {
+		boosts = append(boosts, AttributeBoost{
+			Attribute: attribute,
+			Value:     value,
+			Factor:    1 + (factor-1)*float64(orders[value])/most,
+		})
+	}
+	return boosts
+}
@@ -0,0 +1,1090 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// postgresRepository stores the catalog in Postgres, for setups too small to run Elasticsearch for.
+// Products are searched with the full-text search of Postgres over their name and description, which
+// stems the words of each locale's language but, unlike the Elasticsearch repository, doesn't fold accents.
+// Queries are expanded with the synonyms of the search vocabulary, and its stopwords left out of them.
+// Only the default locale's text is indexed; translations are analyzed while searching. Suggestions match
+// the start of any word of a product's name, without allowing for typos.
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(url string) (Repository, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+	return &postgresRepository{db}, nil
+}
+
+func (r *postgresRepository) Close() {
+	r.db.Close()
+}
+
+// The columns a product is read from, in the order scanProduct expects them. The ID of the transaction that
+// last wrote the row serves as the version of the product.
+const productColumns = "id, name, description, price, stock, created_at, category_id, category_path, tags, archived, variants, average_rating, rating_count, translations, images, xmin::text::bigint"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProduct(row rowScanner) (Product, error) {
+	p := Product{}
+	var createdAt sql.NullTime
+	var categoryPath, tags pq.StringArray
+	var variants, translations, images []byte
+	err := row.Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.Stock, &createdAt, &p.CategoryID, &categoryPath, &tags, &p.Archived, &variants, &p.AverageRating, &p.RatingCount, &translations, &images, &p.version)
+	if err != nil {
+		return p, err
+	}
+	if createdAt.Valid {
+		p.CreatedAt = createdAt.Time.UTC()
+	}
+	p.CategoryPath = []string(categoryPath)
+	if p.CategoryPath == nil {
+		p.CategoryPath = []string{}
+	}
+	p.Tags = []string(tags)
+	if p.Tags == nil {
+		p.Tags = []string{}
+	}
+	if err := json.Unmarshal(variants, &p.Variants); err != nil {
+		return p, err
+	}
+	if p.Variants == nil {
+		p.Variants = []Variant{}
+	}
+	if err := json.Unmarshal(translations, &p.Translations); err != nil {
+		return p, err
+	}
+	if p.Translations == nil {
+		p.Translations = map[string]ProductTranslation{}
+	}
+	if err := json.Unmarshal(images, &p.Images); err != nil {
+		return p, err
+	}
+	if p.Images == nil {
+		p.Images = []ProductImage{}
+	}
+	return p, nil
+}
+
+func scanProducts(rows *sql.Rows) ([]Product, error) {
+	products := []Product{}
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+// Variants are stored as a JSON array, empty rather than null for products without them
+func variantsJSON(variants []Variant) ([]byte, error) {
+	if variants == nil {
+		variants = []Variant{}
+	}
+	return json.Marshal(variants)
+}
+
+// Translations are stored as a JSON object keyed by locale
+func translationsJSON(translations map[string]ProductTranslation) ([]byte, error) {
+	if translations == nil {
+		translations = map[string]ProductTranslation{}
+	}
+	return json.Marshal(translations)
+}
+
+// Images are stored as a JSON array in the order they are shown in
+func imagesJSON(images []ProductImage) ([]byte, error) {
+	if images == nil {
+		images = []ProductImage{}
+	}
+	return json.Marshal(images)
+}
+
+const insertProduct = `
+	INSERT INTO products(id, name, description, price, stock, created_at, category_id, category_path, tags, archived, variants, average_rating, rating_count, translations, images)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+const upsertProduct = insertProduct + `
+	ON CONFLICT (id) DO UPDATE SET
+		name = EXCLUDED.name,
+		description = EXCLUDED.description,
+		price = EXCLUDED.price,
+		stock = EXCLUDED.stock,
+		created_at = EXCLUDED.created_at,
+		category_id = EXCLUDED.category_id,
+		category_path = EXCLUDED.category_path,
+		tags = EXCLUDED.tags,
+		archived = EXCLUDED.archived,
+		variants = EXCLUDED.variants,
+		average_rating = EXCLUDED.average_rating,
+		rating_count = EXCLUDED.rating_count,
+		translations = EXCLUDED.translations,
+		images = EXCLUDED.images
+	`
+
+// Replaces a product only while it still has the version passed as the last argument
+const replaceProduct = upsertProduct + "WHERE products.xmin::text::bigint = $16"
+
+// The arguments of upsertProduct; products without a creation time are stored without one
+func productArgs(p Product) ([]interface{}, error) {
+	variants, err := variantsJSON(p.Variants)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := translationsJSON(p.Translations)
+	if err != nil {
+		return nil, err
+	}
+	images, err := imagesJSON(p.Images)
+	if err != nil {
+		return nil, err
+	}
+	var createdAt *time.Time
+	if !p.CreatedAt.IsZero() {
+		createdAt = &p.CreatedAt
+	}
+	return []interface{}{
+		p.ID, p.Name, p.Description, p.Price, p.Stock, createdAt, p.CategoryID,
+		pq.Array(stringsOrEmpty(p.CategoryPath)), pq.Array(stringsOrEmpty(p.Tags)), p.Archived, variants,
+		p.AverageRating, p.RatingCount, translations, images,
+	}, nil
+}
+
+func stringsOrEmpty(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func (r *postgresRepository) PutProduct(ctx context.Context, p Product) error {
+	args, err := productArgs(p)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, upsertProduct, args...)
+	return err
+}
+
+// PutProducts stores the products one by one, so that one failing doesn't keep the others from being stored,
+// and returns the error of each product, nil for those that were stored
+func (r *postgresRepository) PutProducts(ctx context.Context, products []Product) ([]error, error) {
+	errs := make([]error, len(products))
+	if len(products) == 0 {
+		return errs, nil
+	}
+	create, err := r.db.PrepareContext(ctx, insertProduct+"ON CONFLICT (id) DO NOTHING")
+	if err != nil {
+		return nil, err
+	}
+	defer create.Close()
+	replace, err := r.db.PrepareContext(ctx, replaceProduct)
+	if err != nil {
+		return nil, err
+	}
+	defer replace.Close()
+
+	for i, p := range products {
+		args, err := productArgs(p)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		stmt := create
+		if p.version != 0 {
+			stmt, args = replace, append(args, p.version)
+		}
+		res, err := stmt.ExecContext(ctx, args...)
+		if err == nil {
+			var n int64
+			if n, err = res.RowsAffected(); err == nil && n == 0 {
+				err = ErrProductChanged
+			}
+		}
+		if err != nil {
+			// Only problems with the product itself are reported per product
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			errs[i] = err
+		}
+	}
+	return errs, nil
+}
+
+func (r *postgresRepository) GetProductByID(ctx context.Context, id string) (*Product, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = $1", id)
+	p, err := scanProduct(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *postgresRepository) ListProducts(ctx context.Context, skip uint64, take uint64) ([]Product, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+productColumns+` FROM products
+		WHERE NOT archived
+		ORDER BY id
+		OFFSET $1 LIMIT $2
+		`, skip, take,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanProducts(rows)
+}
+
+// ListProductsWithIDs returns the products that exist in the order of the IDs. Like the Elasticsearch
+// repository it looks all of them up, whatever skip and take are.
+func (r *postgresRepository) ListProductsWithIDs(ctx context.Context, ids []string, skip uint64, take uint64) ([]Product, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	found, err := scanProducts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]Product{}
+	for _, p := range found {
+		byID[p.ID] = p
+	}
+	products := []Product{}
+	for _, id := range ids {
+		if p, ok := byID[id]; ok {
+			products = append(products, p)
+			delete(byID, id)
+		}
+	}
+	return products, nil
+}
+
+// ScrollProducts passes all products to fn, a page at a time, stopping at the first error fn returns
+func (r *postgresRepository) ScrollProducts(ctx context.Context, fn func(products []Product) error) error {
+	// Page by ID rather than offset, so that products written meanwhile don't shift the pages
+	after := ""
+	for {
+		rows, err := r.db.QueryContext(ctx, `
+			SELECT `+productColumns+` FROM products
+			WHERE id > $1
+			ORDER BY id
+			LIMIT $2
+			`, after, scrollSize,
+		)
+		if err != nil {
+			return err
+		}
+		products, err := scanProducts(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		if len(products) == 0 {
+			return nil
+		}
+		if err := fn(products); err != nil {
+			return err
+		}
+		after = products[len(products)-1].ID
+	}
+}
+
+// textQuery builds a tsquery matching every clause of an expanded query by any of its terms, analyzed with
+// a text search configuration. A query of only stopwords has no clauses, and matches nothing.
+func textQuery(clauses [][]string, config string, add func(condition string, arg interface{}) string) string {
+	if len(clauses) == 0 {
+		return "''::tsquery"
+	}
+	and := []string{}
+	for _, terms := range clauses {
+		or := []string{}
+		for _, term := range terms {
+			or = append(or, fmt.Sprintf("plainto_tsquery(%s, %s)", config, add("$%d", term)))
+		}
+		and = append(and, "("+strings.Join(or, " || ")+")")
+	}
+	return "(" + strings.Join(and, " && ") + ")"
+}
+
+func (r *postgresRepository) SearchProducts(ctx context.Context, query ProductQuery) (*ProductSearchResult, error) {
+	// Match the query against name and description, or match all products without one
+	args := []interface{}{}
+	add := func(condition string, arg interface{}) string {
+		args = append(args, arg)
+		return fmt.Sprintf(condition, len(args))
+	}
+	conditions := []string{"NOT archived"}
+	rank := ""
+	if query.Query != "" {
+		vocabulary, err := r.GetSearchVocabulary(ctx)
+		if err != nil {
+			return nil, err
+		}
+		clauses := vocabulary.expand(query.Query)
+		tsquery := textQuery(clauses, "'english'", add)
+		match := "search @@ " + tsquery
+		rank = "ts_rank(search, " + tsquery + ")"
+
+		// Products are also found by their translation into the locale, analyzed for its language
+		if l, ok := locales[query.Locale]; ok && query.Locale != DefaultLocale {
+			config := add("$%d::regconfig", l.language)
+			translation := add("translations->($%d::text)", query.Locale)
+			translated := fmt.Sprintf(
+				"setweight(to_tsvector(%[1]s, coalesce(%[2]s->>'name', '')), 'A') || setweight(to_tsvector(%[1]s, coalesce(%[2]s->>'description', '')), 'B')",
+				config, translation,
+			)
+			translatedQuery := textQuery(clauses, config, add)
+			match = "(" + match + " OR " + translated + " @@ " + translatedQuery + ")"
+			rank = "greatest(" + rank + ", ts_rank(" + translated + ", " + translatedQuery + "))"
+		}
+		// Pinned products are found whether they match or not
+		if pinned := query.Merchandising.Pinned; len(pinned) > 0 {
+			match = "(" + match + " OR " + add("id = ANY($%d::text[])", pq.Array(pinned)) + ")"
+		}
+		conditions = append(conditions, match)
+	}
+	if hidden := query.Merchandising.Hidden; len(hidden) > 0 {
+		conditions = append(conditions, add("NOT (id = ANY($%d::text[]))", pq.Array(hidden)))
+	}
+
+	// A category also matches the products of its subcategories
+	filter := query.Filter
+	if filter.CategoryID != "" {
+		conditions = append(conditions, add("$%d = ANY(category_path)", filter.CategoryID))
+	}
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, add("tags @> $%d", pq.Array(filter.Tags)))
+	}
+	if filter.MinPrice != nil {
+		conditions = append(conditions, add("price >= $%d", *filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		conditions = append(conditions, add("price <= $%d", *filter.MaxPrice))
+	}
+	if filter.InStock {
+		conditions = append(conditions, "stock > 0")
+	}
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	result := &ProductSearchResult{Products: []Product{}}
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products "+where, args...).Scan(&result.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the matches per category and tag
+	if result.Facets.Categories, err = r.facetCounts(ctx, "category_path", where, args); err != nil {
+		return nil, err
+	}
+	if result.Facets.Tags, err = r.facetCounts(ctx, "tags", where, args); err != nil {
+		return nil, err
+	}
+
+	// Get the page of products from skip to take. The order may add arguments, which the queries above
+	// mustn't be given.
+	order := productOrder(query.Sort, rank)
+	if m := query.Merchandising; m.Reorders() {
+		order = merchandisedOrder(m, rank, add)
+	}
+	pageArgs := append(append([]interface{}{}, args...), query.Skip, query.Take)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+productColumns+` FROM products
+		`+where+`
+		ORDER BY `+order+`
+		OFFSET $`+fmt.Sprint(len(args)+1)+` LIMIT $`+fmt.Sprint(len(args)+2),
+		pageArgs...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if result.Products, err = scanProducts(rows); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// The ORDER BY of a product sort; relevance is the rank of the query, if there is one. Ties are broken by ID
+// so that pages don't overlap.
+func productOrder(sort ProductSort, rank string) string {
+	switch sort {
+	case ProductSortPriceAsc:
+		return "price ASC, id"
+	case ProductSortPriceDesc:
+		return "price DESC, id"
+	case ProductSortNewest:
+		// Products stored without a creation time come last
+		return "created_at DESC NULLS LAST, id"
+	case ProductSortName:
+		// Names are compared byte by byte, like keywords are in Elasticsearch
+		return `name COLLATE "C", id`
+	case ProductSortRating:
+		return "average_rating DESC, rating_count DESC, id"
+	}
+	if rank != "" {
+		return rank + " DESC, id"
+	}
+	return "id"
+}
+
+// The ORDER BY of a search sorted by relevance with merchandising: pinned products first in their order, then
+// the others by their rank multiplied by the boosts that apply to them, and the buried products last
+func merchandisedOrder(m Merchandising, rank string, add func(condition string, arg interface{}) string) string {
+	if rank == "" {
+		rank = "1"
+	}
+	for _, b := range m.Boosts {
+		column := "tags"
+		if b.Attribute == BoostAttributeCategory {
+			column = "category_path"
+		}
+		rank = fmt.Sprintf("%s * (CASE WHEN %s = ANY(%s) THEN %s ELSE 1 END)",
+			rank, add("$%d::text", b.Value), column, add("$%d::double precision", b.Factor))
+	}
+	return fmt.Sprintf("array_position(%s, id::text) NULLS LAST, id = ANY(%s) ASC, %s DESC, id",
+		add("$%d::text[]", pq.Array(m.Pinned)), add("$%d::text[]", pq.Array(m.Buried)), rank)
+}
+
+// Counts the matching products per value of an array column, most frequent values first
+func (r *postgresRepository) facetCounts(ctx context.Context, column string, where string, args []interface{}) ([]FacetCount, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT f.value, COUNT(*) FROM products, unnest(`+column+`) AS f(value)
+		`+where+`
+		GROUP BY f.value
+		ORDER BY COUNT(*) DESC, f.value
+		LIMIT `+fmt.Sprint(facetSize),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []FacetCount{}
+	for rows.Next() {
+		c := FacetCount{}
+		if err := rows.Scan(&c.Value, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// Escapes the wildcards of LIKE patterns
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *postgresRepository) SuggestProducts(ctx context.Context, prefix string, size uint64) ([]ProductSuggestion, error) {
+	// Names starting with the prefix score higher than those with a later word starting with it
+	prefix = likeEscaper.Replace(strings.ToLower(prefix))
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, CASE WHEN lower(name) LIKE $1 THEN 1.0 ELSE 0.5 END::float8 AS score
+		FROM products
+		WHERE NOT archived AND (lower(name) LIKE $1 OR lower(name) LIKE $2)
+		ORDER BY score DESC, name, id
+		LIMIT $3
+		`, prefix+"%", "% "+prefix+"%", size,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []ProductSuggestion{}
+	for rows.Next() {
+		s := ProductSuggestion{}
+		if err := rows.Scan(&s.ID, &s.Name, &s.Score); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
+
+// Runs an update of a single product, reporting a product that doesn't exist
+func (r *postgresRepository) updateProduct(ctx context.Context, query string, args ...interface{}) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+func (r *postgresRepository) AdjustStock(ctx context.Context, id string, variantID string, delta int64) error {
+	// Increments the stock in place so concurrent adjustments don't overwrite each other, keeping the
+	// product's stock the sum of its variants'. Stock is only taken out while enough is left.
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE products SET
+			stock = stock + $3::bigint,
+			variants = CASE WHEN $2::text = '' THEN variants ELSE (
+				SELECT COALESCE(jsonb_agg(
+					CASE WHEN v.value->>'id' = $2::text
+					THEN jsonb_set(v.value, '{stock}', to_jsonb(COALESCE((v.value->>'stock')::bigint, 0) + $3::bigint))
+					ELSE v.value END
+					ORDER BY v.i
+				), '[]'::jsonb)
+				FROM jsonb_array_elements(variants) WITH ORDINALITY AS v(value, i)
+			) END
+		WHERE id = $1 AND ($3::bigint >= 0 OR (
+			stock + $3::bigint >= 0 AND NOT EXISTS (
+				SELECT 1 FROM jsonb_array_elements(variants) AS v(value)
+				WHERE v.value->>'id' = $2::text AND COALESCE((v.value->>'stock')::bigint, 0) + $3::bigint < 0
+			)
+		))
+		`, id, variantID, delta,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	if _, err := r.GetProductByID(ctx, id); err != nil {
+		return err
+	}
+	return ErrOutOfStock
+}
+
+func (r *postgresRepository) UpdateProductDetails(ctx context.Context, p Product, change *PriceChange) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Only replaces the details, leaving e.g. the stock to concurrent updates. A change of the price is
+	// recorded in the same transaction, as long as the product still has the price it changes from.
+	res, err := tx.ExecContext(ctx, `
+		UPDATE products SET name = $2, description = $3, price = $4
+		WHERE id = $1 AND ($5::float8 IS NULL OR price = $5::float8)
+		`, p.ID, p.Name, p.Description, p.Price, changeOldPrice(change),
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", p.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrProductNotFound
+		}
+		return ErrProductChanged
+	}
+	if change == nil {
+		return nil
+	}
+	return insertPriceChange(ctx, tx, *change)
+}
+
+func insertPriceChange(ctx context.Context, tx *sql.Tx, c PriceChange) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO price_changes(product_id, variant_id, old_price, new_price, changed_by, changed_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+		`, c.ProductID, c.VariantID, c.OldPrice, c.NewPrice, c.ChangedBy, c.ChangedAt,
+	)
+	return err
+}
+
+// The price a change starts from, or NULL without one
+func changeOldPrice(change *PriceChange) sql.NullFloat64 {
+	if change == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: change.OldPrice, Valid: true}
+}
+
+func (r *postgresRepository) AddVariant(ctx context.Context, productID string, v Variant) (*Product, error) {
+	return r.modifyVariants(ctx, productID, func(p *Product) (*PriceChange, error) {
+		addVariant(p, v)
+		return nil, nil
+	})
+}
+
+func (r *postgresRepository) UpdateVariant(ctx context.Context, productID string, v Variant, change *PriceChange) (*Product, error) {
+	return r.modifyVariants(ctx, productID, func(p *Product) (*PriceChange, error) {
+		return change, updateVariant(p, v, change)
+	})
+}
+
+func (r *postgresRepository) RemoveVariant(ctx context.Context, productID string, variantID string) (*Product, error) {
+	return r.modifyVariants(ctx, productID, func(p *Product) (*PriceChange, error) {
+		return nil, removeVariant(p, variantID)
+	})
+}
+
+// Changes the variants of a product while it is locked, so that neither concurrent stock adjustments nor
+// changes of other variants get lost, and records the price change modify returns in the same transaction
+func (r *postgresRepository) modifyVariants(ctx context.Context, id string, modify func(p *Product) (*PriceChange, error)) (_ *Product, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	p, err := scanProduct(tx.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = $1 FOR UPDATE", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	change, err := modify(&p)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stock of a product with variants follows from theirs, so both are replaced together
+	variants, err := variantsJSON(p.Variants)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, "UPDATE products SET variants = $2, stock = $3 WHERE id = $1", id, variants, p.Stock); err != nil {
+		return nil, err
+	}
+	if change != nil {
+		if err = insertPriceChange(ctx, tx, *change); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+func (r *postgresRepository) ArchiveProduct(ctx context.Context, id string) error {
+	return r.updateProduct(ctx, "UPDATE products SET archived = TRUE WHERE id = $1", id)
+}
+
+func (r *postgresRepository) UpdateProductCategory(ctx context.Context, p Product) error {
+	// Only replaces the category and tag fields, leaving e.g. the stock to concurrent updates
+	return r.updateProduct(ctx, `
+		UPDATE products SET category_id = $2, category_path = $3, tags = $4 WHERE id = $1
+		`, p.ID, p.CategoryID, pq.Array(stringsOrEmpty(p.CategoryPath)), pq.Array(stringsOrEmpty(p.Tags)),
+	)
+}
+
+func (r *postgresRepository) UpdateProductRating(ctx context.Context, p Product) error {
+	return r.updateProduct(ctx, `
+		UPDATE products SET average_rating = $2, rating_count = $3 WHERE id = $1
+		`, p.ID, p.AverageRating, p.RatingCount,
+	)
+}
+
+func (r *postgresRepository) UpdateProductTranslations(ctx context.Context, p Product) error {
+	translations, err := translationsJSON(p.Translations)
+	if err != nil {
+		return err
+	}
+	return r.updateProduct(ctx, "UPDATE products SET translations = $2 WHERE id = $1", p.ID, translations)
+}
+
+func (r *postgresRepository) AddProductImage(ctx context.Context, productID string, img ProductImage, position *int) (*Product, error) {
+	return r.modifyImages(ctx, productID, func(p *Product) error {
+		addImage(p, img, position)
+		return nil
+	})
+}
+
+func (r *postgresRepository) UpdateProductImage(ctx context.Context, productID string, imageID string, update ImageUpdate) (*Product, error) {
+	return r.modifyImages(ctx, productID, func(p *Product) error {
+		return updateImage(p, imageID, update)
+	})
+}
+
+func (r *postgresRepository) RemoveProductImage(ctx context.Context, productID string, imageID string) (*Product, error) {
+	return r.modifyImages(ctx, productID, func(p *Product) error {
+		return removeImage(p, imageID)
+	})
+}
+
+// Changes the images of a product while it is locked, so that images uploaded or changed at the same time
+// aren't dropped
+func (r *postgresRepository) modifyImages(ctx context.Context, id string, modify func(p *Product) error) (_ *Product, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	p, err := scanProduct(tx.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = $1 FOR UPDATE", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = modify(&p); err != nil {
+		return nil, err
+	}
+	images, err := imagesJSON(p.Images)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, "UPDATE products SET images = $2 WHERE id = $1", id, images); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Lists the products, archived ones included, that have a variant with the SKU
+func (r *postgresRepository) ListProductsWithSKU(ctx context.Context, sku string) ([]Product, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+productColumns+` FROM products
+		WHERE variants @> jsonb_build_array(jsonb_build_object('sku', $1::text))
+		`, sku,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanProducts(rows)
+}
+
+func (r *postgresRepository) PutPriceChanges(ctx context.Context, changes []PriceChange) (err error) {
+	if len(changes) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("price_changes", "product_id", "variant_id", "old_price", "new_price", "changed_by", "changed_at"))
+	if err != nil {
+		return
+	}
+	for _, c := range changes {
+		_, err = stmt.ExecContext(ctx, c.ProductID, c.VariantID, c.OldPrice, c.NewPrice, c.ChangedBy, c.ChangedAt)
+		if err != nil {
+			return
+		}
+	}
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		return
+	}
+	stmt.Close()
+	return
+}
+
+func (r *postgresRepository) ListPriceChanges(ctx context.Context, productID string, skip uint64, take uint64) ([]PriceChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id::text, product_id, variant_id, old_price, new_price, changed_by, changed_at
+		FROM price_changes
+		WHERE product_id = $1
+		ORDER BY changed_at DESC, id DESC
+		OFFSET $2 LIMIT $3
+		`, productID, skip, take,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []PriceChange{}
+	for rows.Next() {
+		c := PriceChange{}
+		if err := rows.Scan(&c.ID, &c.ProductID, &c.VariantID, &c.OldPrice, &c.NewPrice, &c.ChangedBy, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		c.ChangedAt = c.ChangedAt.UTC()
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func (r *postgresRepository) PutCategory(ctx context.Context, c Category) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO categories(id, name, parent_id, path)
+		VALUES($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, parent_id = EXCLUDED.parent_id, path = EXCLUDED.path
+		`, c.ID, c.Name, c.ParentID, pq.Array(stringsOrEmpty(c.Path)),
+	)
+	return err
+}
+
+func scanCategory(row rowScanner) (Category, error) {
+	c := Category{}
+	var path pq.StringArray
+	if err := row.Scan(&c.ID, &c.Name, &c.ParentID, &path); err != nil {
+		return c, err
+	}
+	c.Path = stringsOrEmpty(path)
+	return c, nil
+}
+
+func (r *postgresRepository) GetCategoryByID(ctx context.Context, id string) (*Category, error) {
+	c, err := scanCategory(r.db.QueryRowContext(ctx, "SELECT id, name, parent_id, path FROM categories WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *postgresRepository) ListCategories(ctx context.Context) ([]Category, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, parent_id, path FROM categories ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []Category{}
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// A category is in use while it has subcategories or products in it
+func (r *postgresRepository) CategoryInUse(ctx context.Context, id string) (bool, error) {
+	var inUse bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)
+			OR EXISTS (SELECT 1 FROM products WHERE category_id = $1)
+		`, id,
+	).Scan(&inUse)
+	return inUse, err
+}
+
+func (r *postgresRepository) DeleteCategory(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM categories WHERE id = $1", id)
+	return err
+}
+
+const merchandisingRuleColumns = "id, query, category_id, pinned, boosts, buried, hidden"
+
+func scanMerchandisingRule(row rowScanner) (MerchandisingRule, error) {
+	rule := MerchandisingRule{}
+	var pinned, buried, hidden pq.StringArray
+	var boosts []byte
+	if err := row.Scan(&rule.ID, &rule.Query, &rule.CategoryID, &pinned, &boosts, &buried, &hidden); err != nil {
+		return rule, err
+	}
+	if err := json.Unmarshal(boosts, &rule.Boosts); err != nil {
+		return rule, err
+	}
+	rule.Pinned = stringsOrEmpty(pinned)
+	rule.Boosts = boostsOrEmpty(rule.Boosts)
+	rule.Buried = stringsOrEmpty(buried)
+	rule.Hidden = stringsOrEmpty(hidden)
+	return rule, nil
+}
+
+func (r *postgresRepository) PutMerchandisingRule(ctx context.Context, rule MerchandisingRule) error {
+	boosts, err := json.Marshal(boostsOrEmpty(rule.Boosts))
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO merchandising_rules(`+merchandisingRuleColumns+`)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			query = EXCLUDED.query,
+			category_id = EXCLUDED.category_id,
+			pinned = EXCLUDED.pinned,
+			boosts = EXCLUDED.boosts,
+			buried = EXCLUDED.buried,
+			hidden = EXCLUDED.hidden
+		`, rule.ID, rule.Query, rule.CategoryID, pq.Array(stringsOrEmpty(rule.Pinned)), boosts,
+		pq.Array(stringsOrEmpty(rule.Buried)), pq.Array(stringsOrEmpty(rule.Hidden)),
+	)
+	return err
+}
+
+func (r *postgresRepository) GetMerchandisingRule(ctx context.Context, id string) (*MerchandisingRule, error) {
+	rule, err := scanMerchandisingRule(r.db.QueryRowContext(ctx, "SELECT "+merchandisingRuleColumns+" FROM merchandising_rules WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *postgresRepository) ListMerchandisingRules(ctx context.Context) ([]MerchandisingRule, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+merchandisingRuleColumns+" FROM merchandising_rules ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []MerchandisingRule{}
+	for rows.Next() {
+		rule, err := scanMerchandisingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *postgresRepository) DeleteMerchandisingRule(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM merchandising_rules WHERE id = $1", id)
+	return err
+}
+
+func (r *postgresRepository) GetSearchVocabulary(ctx context.Context) (*SearchVocabulary, error) {
+	v := &SearchVocabulary{Synonyms: [][]string{}, Stopwords: []string{}}
+	var synonyms []byte
+	var stopwords pq.StringArray
+	err := r.db.QueryRowContext(ctx, "SELECT synonyms, stopwords FROM search_vocabulary").Scan(&synonyms, &stopwords)
+	if errors.Is(err, sql.ErrNoRows) {
+		return v, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(synonyms, &v.Synonyms); err != nil {
+		return nil, err
+	}
+	v.Stopwords = stringsOrEmpty(stopwords)
+	return v, nil
+}
+
+func (r *postgresRepository) PutSearchVocabulary(ctx context.Context, v SearchVocabulary) error {
+	synonyms, err := json.Marshal(v.Synonyms)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO search_vocabulary(synonyms, stopwords)
+		VALUES($1, $2)
+		ON CONFLICT (id) DO UPDATE SET synonyms = EXCLUDED.synonyms, stopwords = EXCLUDED.stopwords
+		`, synonyms, pq.Array(stringsOrEmpty(v.Stopwords)),
+	)
+	return err
+}
+
+func (r *postgresRepository) LogSearch(ctx context.Context, entry SearchLogEntry) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO search_log(query, locale, category_id, hits, searched_at)
+		VALUES($1, $2, $3, $4, $5)
+		`, entry.Query, entry.Locale, entry.CategoryID, entry.Hits, entry.SearchedAt,
+	)
+	return err
+}
+
+func (r *postgresRepository) GetSearchReport(ctx context.Context, since time.Time, size uint64) (*SearchReport, error) {
+	report := &SearchReport{Since: since}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT count(*), count(*) FILTER (WHERE hits = 0)
+		FROM search_log
+		WHERE searched_at >= $1
+		`, since,
+	).Scan(&report.Searches, &report.ZeroResultSearches)
+	if err != nil {
+		return nil, err
+	}
+	if report.TopQueries, err = r.topQueries(ctx, since, size, false); err != nil {
+		return nil, err
+	}
+	if report.ZeroResultQueries, err = r.topQueries(ctx, since, size, true); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Lists the most searched queries since a time, of all searches or only of those that found nothing
+func (r *postgresRepository) topQueries(ctx context.Context, since time.Time, size uint64, zeroResults bool) ([]QueryStats, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT query, count(*) AS searches
+		FROM search_log
+		WHERE searched_at >= $1 AND query <> '' AND (NOT $2 OR hits = 0)
+		GROUP BY query
+		ORDER BY searches DESC, query
+		LIMIT $3
+		`, since, zeroResults, size,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	queries := []QueryStats{}
+	for rows.Next() {
+		q := QueryStats{}
+		if err := rows.Scan(&q.Query, &q.Searches); err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	return queries, rows.Err()
+}
@@ -3,27 +3,139 @@ package catalog
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
 
 	elastic "gopkg.in/olivere/elastic.v5"
 )
 
+// How many categories and tags a product search counts products for
+const facetSize = 50
+
+// Name of the completion suggester used for product suggestions
+const productSuggester = "product-suggest"
+
+// How many products are read per round trip when going through all of them
+const scrollSize = 500
+
 type Repository interface {
 	Close()
 	PutProduct(ctx context.Context, p Product) error
+	// PutProducts creates the products that have no version and replaces the others while the stored product
+	// still has their version. Products created or changed meanwhile fail with ErrProductChanged.
+	PutProducts(ctx context.Context, products []Product) ([]error, error)
 	GetProductByID(ctx context.Context, id string) (*Product, error)
 	ListProducts(ctx context.Context, skip uint64, take uint64) ([]Product, error)
 	ListProductsWithIDs(ctx context.Context, ids []string, skip uint64, take uint64) ([]Product, error)
-	SearchProducts(ctx context.Context, query string, skip uint64, take uint64) ([]Product, error)
+	ScrollProducts(ctx context.Context, fn func(products []Product) error) error
+	SearchProducts(ctx context.Context, query ProductQuery) (*ProductSearchResult, error)
+	ListProductsWithSKU(ctx context.Context, sku string) ([]Product, error)
+	AdjustStock(ctx context.Context, id string, variantID string, delta int64) error
+	AddVariant(ctx context.Context, productID string, v Variant) (*Product, error)
+	UpdateVariant(ctx context.Context, productID string, v Variant, change *PriceChange) (*Product, error)
+	RemoveVariant(ctx context.Context, productID string, variantID string) (*Product, error)
+	UpdateProductDetails(ctx context.Context, p Product, change *PriceChange) error
+	ArchiveProduct(ctx context.Context, id string) error
+	PutPriceChanges(ctx context.Context, changes []PriceChange) error
+	ListPriceChanges(ctx context.Context, productID string, skip uint64, take uint64) ([]PriceChange, error)
+	UpdateProductCategory(ctx context.Context, p Product) error
+	UpdateProductRating(ctx context.Context, p Product) error
+	UpdateProductTranslations(ctx context.Context, p Product) error
+	AddProductImage(ctx context.Context, productID string, img ProductImage, position *int) (*Product, error)
+	UpdateProductImage(ctx context.Context, productID string, imageID string, update ImageUpdate) (*Product, error)
+	RemoveProductImage(ctx context.Context, productID string, imageID string) (*Product, error)
+	PutMerchandisingRule(ctx context.Context, rule MerchandisingRule) error
+	GetMerchandisingRule(ctx context.Context, id string) (*MerchandisingRule, error)
+	ListMerchandisingRules(ctx context.Context) ([]MerchandisingRule, error)
+	DeleteMerchandisingRule(ctx context.Context, id string) error
+	GetSearchVocabulary(ctx context.Context) (*SearchVocabulary, error)
+	PutSearchVocabulary(ctx context.Context, v SearchVocabulary) error
+	LogSearch(ctx context.Context, entry SearchLogEntry) error
+	GetSearchReport(ctx context.Context, since time.Time, size uint64) (*SearchReport, error)
+	PutCategory(ctx context.Context, c Category) error
+	GetCategoryByID(ctx context.Context, id string) (*Category, error)
+	ListCategories(ctx context.Context) ([]Category, error)
+	CategoryInUse(ctx context.Context, id string) (bool, error)
+	DeleteCategory(ctx context.Context, id string) error
+	SuggestProducts(ctx context.Context, prefix string, size uint64) ([]ProductSuggestion, error)
 }
 
 type elasticRepository struct {
 	client *elastic.Client
+	// Held while the catalog is reindexed for a new search vocabulary
+	reindexing sync.Mutex
 }
 
 type productDocument struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Price        float64   `json:"price"`
+	Stock        int64     `json:"stock"`
+	CreatedAt    time.Time `json:"createdAt"`
+	CategoryID   string    `json:"categoryId"`
+	CategoryPath []string  `json:"categoryPath"`
+	Tags         []string  `json:"tags"`
+	Archived     bool      `json:"archived"`
+	Variants     []Variant `json:"variants,omitempty"`
+	// Average rating and number of ratings, both 0 for products without any
+	AverageRating float64 `json:"averageRating"`
+	RatingCount   uint64  `json:"ratingCount"`
+	// Name and description per locale other than the default one, each analyzed for its language
+	Translations map[string]ProductTranslation `json:"translations,omitempty"`
+	// Images are only stored, in the order they are shown in
+	Images []ProductImage `json:"images,omitempty"`
+	// Price changes recorded along with the price, until they are copied into the price_changes index
+	PendingPriceChanges []pendingPriceChange `json:"pendingPriceChanges,omitempty"`
+	// Inputs of the completion suggester, derived from the name
+	Suggest []string `json:"suggest,omitempty"`
+}
+
+type priceChangeDocument struct {
+	ProductID string    `json:"productId"`
+	VariantID string    `json:"variantId,omitempty"`
+	OldPrice  float64   `json:"oldPrice"`
+	NewPrice  float64   `json:"newPrice"`
+	ChangedBy string    `json:"changedBy"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+type pendingPriceChange struct {
+	ID string `json:"id"`
+	priceChangeDocument
+}
+
+func priceChangeDocumentFrom(c PriceChange) priceChangeDocument {
+	return priceChangeDocument{
+		ProductID: c.ProductID,
+		VariantID: c.VariantID,
+		OldPrice:  c.OldPrice,
+		NewPrice:  c.NewPrice,
+		ChangedBy: c.ChangedBy,
+		ChangedAt: c.ChangedAt,
+	}
+}
+
+func (d priceChangeDocument) priceChange(id string) PriceChange {
+	return PriceChange{
+		ID:        id,
+		ProductID: d.ProductID,
+		VariantID: d.VariantID,
+		OldPrice:  d.OldPrice,
+		NewPrice:  d.NewPrice,
+		ChangedBy: d.ChangedBy,
+		ChangedAt: d.ChangedAt,
+	}
+}
+
+type categoryDocument struct {
+	Name     string   `json:"name"`
+	ParentID string   `json:"parentId"`
+	Path     []string `json:"path"`
 }
 
 func NewElasticRepository(url string) (Repository, error) {
@@ -32,44 +144,172 @@ func NewElasticRepository(url string) (Repository, error) {
 		return nil, err
 	}
 
-	return &elasticRepository{client}, nil
+	r := &elasticRepository{client: client}
+	if err := r.ensureIndices(context.Background()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Convert between products and the documents they are stored as
+func productFromDocument(id string, d productDocument) Product {
+	p := Product{
+		ID:           id,
+		Name:         d.Name,
+		Description:  d.Description,
+		Price:        d.Price,
+		Stock:        d.Stock,
+		CreatedAt:    d.CreatedAt,
+		CategoryID:   d.CategoryID,
+		CategoryPath: d.CategoryPath,
+		Tags:         d.Tags,
+		Archived:     d.Archived,
+		Variants:     d.Variants,
+		// Products stored before ratings were introduced don't have them, which reads as unrated
+		AverageRating: d.AverageRating,
+		RatingCount:   d.RatingCount,
+		Translations:  d.Translations,
+		Images:        d.Images,
+	}
+	for _, c := range d.PendingPriceChanges {
+		p.pendingPriceChanges = append(p.pendingPriceChanges, c.priceChange(c.ID))
+	}
+	if p.CategoryPath == nil {
+		p.CategoryPath = []string{}
+	}
+	if p.Tags == nil {
+		p.Tags = []string{}
+	}
+	if p.Variants == nil {
+		p.Variants = []Variant{}
+	}
+	if p.Translations == nil {
+		p.Translations = map[string]ProductTranslation{}
+	}
+	if p.Images == nil {
+		p.Images = []ProductImage{}
+	}
+	return p
+}
+
+func documentFromProduct(p Product) productDocument {
+	d := productDocument{
+		Name:          p.Name,
+		Description:   p.Description,
+		Price:         p.Price,
+		Stock:         p.Stock,
+		CreatedAt:     p.CreatedAt,
+		CategoryID:    p.CategoryID,
+		CategoryPath:  p.CategoryPath,
+		Tags:          p.Tags,
+		Archived:      p.Archived,
+		Variants:      p.Variants,
+		AverageRating: p.AverageRating,
+		RatingCount:   p.RatingCount,
+		Translations:  p.Translations,
+		Images:        p.Images,
+	}
+	for _, c := range p.pendingPriceChanges {
+		d.PendingPriceChanges = append(d.PendingPriceChanges, pendingPriceChange{c.ID, priceChangeDocumentFrom(c)})
+	}
+	// The completion suggester cannot filter, so archived products are left without inputs instead
+	if !p.Archived {
+		d.Suggest = suggestInputs(p.Name)
+	}
+	return d
+}
+
+// Only products for sale are listed and searched
+func notArchived() elastic.Query {
+	return elastic.NewBoolQuery().MustNot(elastic.NewTermQuery("archived", true))
+}
+
+// The completion suggester only matches the start of its inputs, so to suggest a product by any word
+// of its name, the name is input from each of its words on, e.g. "red running shoe", "running shoe" and "shoe"
+func suggestInputs(name string) []string {
+	words := strings.Fields(name)
+	if len(words) > 10 {
+		words = words[:10]
+	}
+	inputs := []string{}
+	for i := range words {
+		inputs = append(inputs, strings.Join(words[i:], " "))
+	}
+	return inputs
 }
 
 func (r *elasticRepository) Close() {
-	// r.client.CloseIndex("catalog")
+	// r.client.CloseIndex(catalogAlias)
 }
 
 func (r *elasticRepository) PutProduct(ctx context.Context, p Product) error {
 	// Starts a new indexing request using the elastic search client
 	_, err := r.client.Index().
 	// Puts the product in the catalog index
-		Index("catalog").
+		Index(catalogAlias).
 		// Type Product
 		Type("product").
 		// Sets the document ID as product ID
 		Id(p.ID).
 		// Converts Product struct to productDocument struct and then to BodyJson for elasticsearch
-		BodyJson(productDocument{
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-		}).
+		BodyJson(documentFromProduct(p)).
 		// Executes the request
 		Do(ctx)
 	return err
 
 }
 
+// PutProducts stores the products with a single bulk request. Products can fail one by one, so besides an error
+// for the request as a whole, it returns the error of each product, nil for those that were stored. The versions
+// of the products are those Elasticsearch keeps of every document.
+func (r *elasticRepository) PutProducts(ctx context.Context, products []Product) ([]error, error) {
+	errs := make([]error, len(products))
+	if len(products) == 0 {
+		return errs, nil
+	}
+	bulk := r.client.Bulk().Index(catalogAlias).Type("product")
+	for _, p := range products {
+		req := elastic.NewBulkIndexRequest().Id(p.ID).Doc(documentFromProduct(p))
+		if p.version == 0 {
+			req = req.OpType("create")
+		} else {
+			req = req.Version(p.version)
+		}
+		bulk.Add(req)
+	}
+	res, err := bulk.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The items of the response are in the order of the requests
+	for i, item := range res.Items {
+		for _, result := range item {
+			switch {
+			case i >= len(errs) || result.Error == nil:
+			case result.Error.Type == "version_conflict_engine_exception":
+				errs[i] = ErrProductChanged
+			default:
+				errs[i] = fmt.Errorf("%s: %s", result.Error.Type, result.Error.Reason)
+			}
+		}
+	}
+	return errs, nil
+}
+
 func (r *elasticRepository) GetProductByID(ctx context.Context, id string) (*Product, error) {
 	// Get the data from the CATALOG index of type product with the provided ID
-	res, err := r.client.Get().Index("catalog").Type("product").Id(id).Do(ctx)
+	res, err := r.client.Get().Index(catalogAlias).Type("product").Id(id).Do(ctx)
+	if elastic.IsNotFound(err) {
+		return nil, ErrProductNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// Checks if the product was found
 	if !res.Found {
-		return nil, err
+		return nil, ErrProductNotFound
 	}
 
 	// Unmarshal the res.Source from elasticSearch and store in p
@@ -77,18 +317,17 @@ func (r *elasticRepository) GetProductByID(ctx context.Context, id string) (*Pro
 	if err = json.Unmarshal(*res.Source, &p); err != nil {
 		return nil, err
 	}
-	return &Product{
-		ID:          id,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-	}, err
+	product := productFromDocument(id, p)
+	if res.Version != nil {
+		product.version = *res.Version
+	}
+	return &product, err
 
 }
 
 func (r *elasticRepository) ListProducts(ctx context.Context, skip uint64, take uint64) ([]Product, error) {
-	// Searches in the catalog index, of product type, and runs the query to match all, from skip to take
-	res, err := r.client.Search().Index("catalog").Type("product").Query(elastic.NewMatchAllQuery()).From(int(skip)).Size(int(take)).Do(ctx)
+	// Searches in the catalog index, of product type, and runs the query to match all for sale, from skip to take
+	res, err := r.client.Search().Index(catalogAlias).Type("product").Query(notArchived()).From(int(skip)).Size(int(take)).Do(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -100,12 +339,7 @@ func (r *elasticRepository) ListProducts(ctx context.Context, skip uint64, take
 	for _, hit := range res.Hits.Hits {
 		p := productDocument{}
 		if err = json.Unmarshal(*hit.Source, &p); err == nil {
-			products = append(products, Product{
-				ID:          hit.Id,
-				Name:        p.Name,
-				Description: p.Description,
-				Price:       p.Price,
-			})
+			products = append(products, productFromDocument(hit.Id, p))
 		}
 	}
 	return products, nil
@@ -118,7 +352,7 @@ func (r *elasticRepository) ListProductsWithIDs(ctx context.Context, ids []strin
 	// Range over the productIDs
 	for _, id := range ids {
 		// Get Multiple items from catalog index of type product
-		items = append(items, elastic.NewMultiGetItem().Index("catalog").Type("product").Id(id))
+		items = append(items, elastic.NewMultiGetItem().Index(catalogAlias).Type("product").Id(id))
 	}
 
 	// Sends multi get request to elastic search
@@ -132,36 +366,969 @@ func (r *elasticRepository) ListProductsWithIDs(ctx context.Context, ids []strin
 
 	// range over the docs and append in the above slice
 	for _, doc := range res.Docs {
+		if !doc.Found {
+			continue
+		}
 		p := productDocument{}
 		if err = json.Unmarshal(*doc.Source, &p); err == nil {
-			products = append(products, Product{
-				ID:          doc.Id,
-				Name:        p.Name,
-				Description: p.Description,
-				Price:       p.Price,
-			})
+			product := productFromDocument(doc.Id, p)
+			if doc.Version != nil {
+				product.version = *doc.Version
+			}
+			products = append(products, product)
 		}
 	}
 	return products, nil
 }
 
-func (r *elasticRepository) SearchProducts(ctx context.Context, query string, skip uint64, take uint64) ([]Product, error) {
-	// Searches in the index catalog of type product with the given query from skip to take
-	res, err := r.client.Search().Index("catalog").Type("products").Query(elastic.NewMultiMatchQuery(query, "name", "description")).From(int(skip)).Size(int(take)).Do(ctx)
+// ScrollProducts passes all products to fn, a page at a time, stopping at the first error fn returns
+func (r *elasticRepository) ScrollProducts(ctx context.Context, fn func(products []Product) error) error {
+	scroll := r.client.Scroll(catalogAlias).Type("product").Size(scrollSize)
+	defer scroll.Clear(context.Background())
+	for {
+		res, err := scroll.Do(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		products := []Product{}
+		for _, hit := range res.Hits.Hits {
+			p := productDocument{}
+			if err := json.Unmarshal(*hit.Source, &p); err != nil {
+				return err
+			}
+			products = append(products, productFromDocument(hit.Id, p))
+		}
+		if len(products) == 0 {
+			continue
+		}
+		if err := fn(products); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *elasticRepository) SearchProducts(ctx context.Context, query ProductQuery) (*ProductSearchResult, error) {
+	// Match the query against name and description, or match all products without one. Each field is
+	// analyzed for its own language, and products that haven't been translated into the locale yet are
+	// still found by their content in the default one.
+	q := elastic.NewBoolQuery()
+	if query.Query != "" {
+		fields := []string{"name", "description"}
+		if query.Locale != "" && query.Locale != DefaultLocale {
+			fields = append(fields, "translations."+query.Locale+".name", "translations."+query.Locale+".description")
+		}
+		var match elastic.Query = elastic.NewMultiMatchQuery(query.Query, fields...)
+		// Pinned products are found whether they match or not
+		if pinned := query.Merchandising.Pinned; len(pinned) > 0 {
+			match = elastic.NewBoolQuery().Should(match, elastic.NewIdsQuery("product").Ids(pinned...))
+		}
+		q = q.Must(match)
+	} else {
+		q = q.Must(elastic.NewMatchAllQuery())
+	}
+	if hidden := query.Merchandising.Hidden; len(hidden) > 0 {
+		q = q.MustNot(elastic.NewIdsQuery("product").Ids(hidden...))
+	}
+
+	// Filters don't affect the score; a category also matches the products of its subcategories
+	q = q.Filter(notArchived())
+	filter := query.Filter
+	if filter.CategoryID != "" {
+		q = q.Filter(elastic.NewTermQuery("categoryPath", filter.CategoryID))
+	}
+	for _, tag := range filter.Tags {
+		q = q.Filter(elastic.NewTermQuery("tags", tag))
+	}
+	if filter.MinPrice != nil || filter.MaxPrice != nil {
+		price := elastic.NewRangeQuery("price")
+		if filter.MinPrice != nil {
+			price = price.Gte(*filter.MinPrice)
+		}
+		if filter.MaxPrice != nil {
+			price = price.Lte(*filter.MaxPrice)
+		}
+		q = q.Filter(price)
+	}
+	if filter.InStock {
+		q = q.Filter(elastic.NewRangeQuery("stock").Gt(0))
+	}
+
+	// Searches in the index catalog of type product from skip to take, counting the matches per category and tag
+	search := r.client.Search().Index(catalogAlias).Type("product").
+		Query(merchandisedQuery(q, query.Merchandising)).
+		Aggregation("categories", elastic.NewTermsAggregation().Field("categoryPath").Size(facetSize)).
+		Aggregation("tags", elastic.NewTermsAggregation().Field("tags").Size(facetSize)).
+		From(int(query.Skip)).Size(int(query.Take))
+	sorters := productSorters(query.Sort)
+	if len(sorters) == 0 {
+		sorters = merchandisedSorters(query.Merchandising)
+	}
+	if len(sorters) > 0 {
+		search = search.SortBy(sorters...)
+	}
+	res, err := search.Do(ctx)
 	if err != nil {
 		return nil, err
 	}
-	products := []Product{}
+
+	result := &ProductSearchResult{
+		Products: []Product{},
+		Total:    uint64(res.TotalHits()),
+		Facets: ProductFacets{
+			Categories: facetCounts(res.Aggregations, "categories"),
+			Tags:       facetCounts(res.Aggregations, "tags"),
+		},
+	}
 	for _, hits := range res.Hits.Hits {
 		p := productDocument{}
 		if err = json.Unmarshal(*hits.Source, &p); err == nil {
-			products = append(products, Product{
-				ID:          hits.Id,
-				Name:        p.Name,
-				Description: p.Description,
-				Price:       p.Price,
+			result.Products = append(result.Products, productFromDocument(hits.Id, p))
+		}
+	}
+	return result, nil
+}
+
+// merchandisedQuery multiplies the relevance of the products matching q by the boosts of the merchandising
+func merchandisedQuery(q elastic.Query, m Merchandising) elastic.Query {
+	if len(m.Boosts) == 0 {
+		return q
+	}
+	boosted := elastic.NewFunctionScoreQuery().Query(q).ScoreMode("multiply").BoostMode("multiply")
+	for _, b := range m.Boosts {
+		field := "tags"
+		if b.Attribute == BoostAttributeCategory {
+			field = "categoryPath"
+		}
+		boosted = boosted.Add(elastic.NewTermQuery(field, b.Value), elastic.NewWeightFactorFunction(b.Factor))
+	}
+	return boosted
+}
+
+// Sorts pinned products first in their order and buried ones last, like the memory repository's tiers
+const merchandisingTierScript = `
+String id = doc['_id'].value;
+int i = params.pinned.indexOf(id);
+if (i >= 0) {
+	return i - params.pinned.size();
+}
+return params.buried.contains(id) ? 1 : 0;
+`
+
+// merchandisedSorters sorts a search by relevance into the tiers of the merchandising first. The tiers are
+// sorted by on their own rather than added to the relevance, so that no boost can lift a product out of its tier.
+func merchandisedSorters(m Merchandising) []elastic.Sorter {
+	if len(m.Pinned) == 0 && len(m.Buried) == 0 {
+		return nil
+	}
+	tier := elastic.NewScript(merchandisingTierScript).
+		Lang("painless").
+		Param("pinned", stringsOrEmpty(m.Pinned)).
+		Param("buried", stringsOrEmpty(m.Buried))
+	return []elastic.Sorter{elastic.NewScriptSort(tier, "number").Asc(), elastic.NewScoreSort()}
+}
+
+// The field sorts of a product sort; relevance is the search's own order
+func productSorters(sort ProductSort) []elastic.Sorter {
+	switch sort {
+	case ProductSortPriceAsc:
+		return []elastic.Sorter{elastic.NewFieldSort("price").Asc()}
+	case ProductSortPriceDesc:
+		return []elastic.Sorter{elastic.NewFieldSort("price").Desc()}
+	case ProductSortNewest:
+		// Products from before creation times were stored come last
+		return []elastic.Sorter{elastic.NewFieldSort("createdAt").Desc().Missing("_last")}
+	case ProductSortName:
+		return []elastic.Sorter{elastic.NewFieldSort("name.keyword").Asc()}
+	case ProductSortRating:
+		return []elastic.Sorter{
+			elastic.NewFieldSort("averageRating").Desc().Missing("_last"),
+			elastic.NewFieldSort("ratingCount").Desc().Missing("_last"),
+		}
+	}
+	return nil
+}
+
+func (r *elasticRepository) SuggestProducts(ctx context.Context, prefix string, size uint64) ([]ProductSuggestion, error) {
+	// Allow typos in the prefix, more of them the longer it gets
+	suggester := elastic.NewCompletionSuggester(productSuggester).
+		Field("suggest").
+		PrefixWithOptions(prefix, elastic.NewFuzzyCompletionSuggesterOptions().EditDistance("AUTO")).
+		Size(int(size))
+
+	// Only the suggestions are needed, with nothing but the names of their products
+	res, err := r.client.Search().Index(catalogAlias).Type("product").
+		Suggester(suggester).
+		FetchSourceContext(elastic.NewFetchSourceContext(true).Include("name")).
+		Size(0).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []ProductSuggestion{}
+	for _, suggestion := range res.Suggest[productSuggester] {
+		for _, option := range suggestion.Options {
+			p := productDocument{}
+			if option.Source == nil || json.Unmarshal(*option.Source, &p) != nil {
+				continue
+			}
+			suggestions = append(suggestions, ProductSuggestion{
+				ID:    option.Id,
+				Name:  p.Name,
+				Score: option.ScoreUnderscore,
 			})
 		}
 	}
+	return suggestions, nil
+}
+
+// Read the buckets of a terms aggregation
+func facetCounts(aggs elastic.Aggregations, name string) []FacetCount {
+	counts := []FacetCount{}
+	terms, ok := aggs.Terms(name)
+	if !ok {
+		return counts
+	}
+	for _, b := range terms.Buckets {
+		value, ok := b.Key.(string)
+		if !ok {
+			continue
+		}
+		counts = append(counts, FacetCount{Value: value, Count: uint64(b.DocCount)})
+	}
+	return counts
+}
+
+// Increments the stock of a product, and of its variant if one is given, so the product's stock stays the sum
+// of its variants'. Nothing changes if that would take out more than is left.
+const adjustStockScript = `
+long delta = ((Number)params.delta).longValue();
+def variant = null;
+if (params.variantId != '' && ctx._source.variants != null) {
+	for (def v : ctx._source.variants) {
+		if (v.id == params.variantId) {
+			variant = v;
+		}
+	}
+}
+long stock = ctx._source.stock == null ? 0 : ((Number)ctx._source.stock).longValue();
+long variantStock = variant == null || variant.stock == null ? 0 : ((Number)variant.stock).longValue();
+if (delta < 0 && (stock + delta < 0 || (variant != null && variantStock + delta < 0))) {
+	ctx.op = 'none';
+} else {
+	if (variant != null) {
+		variant.stock = variantStock + delta;
+	}
+	ctx._source.stock = stock + delta;
+}
+`
+
+func (r *elasticRepository) AdjustStock(ctx context.Context, id string, variantID string, delta int64) error {
+	// Increments the stock in place so concurrent adjustments don't overwrite each other
+	res, err := r.client.Update().Index(catalogAlias).Type("product").Id(id).
+		Script(elastic.NewScriptInline(adjustStockScript).
+			Lang("painless").
+			Param("variantId", variantID).
+			Param("delta", delta)).
+		RetryOnConflict(3).
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if res.Result == "noop" {
+		return ErrOutOfStock
+	}
+	return nil
+}
+
+// Scripts changing a single variant in place, so that concurrent stock adjustments and changes of other
+// variants are kept. The stock of a product with variants follows from theirs, so it is summed up again.
+const sumVariantStock = `
+long stock = 0;
+for (def v : ctx._source.variants) {
+	stock += v.stock == null ? 0 : ((Number)v.stock).longValue();
+}
+ctx._source.stock = stock;
+`
+
+const addVariantScript = `
+if (ctx._source.variants == null) {
+	ctx._source.variants = [];
+}
+ctx._source.variants.add(params.variant);
+` + sumVariantStock
+
+// The variant keeps its stock, and a change of its price is kept pending in the product like those of the
+// product's own price. Nothing changes if the variant is gone or no longer has the price changed from.
+const updateVariantScript = `
+def found = null;
+if (ctx._source.variants != null) {
+	for (def v : ctx._source.variants) {
+		if (v.id == params.variant.id) {
+			found = v;
+		}
+	}
+}
+def oldPrice = found == null || found.price == null ? ctx._source.price : found.price;
+if (found == null || (params.change != null && ((Number)oldPrice).doubleValue() != ((Number)params.change.oldPrice).doubleValue())) {
+	ctx.op = 'none';
+} else {
+	found.sku = params.variant.sku;
+	found.options = params.variant.options;
+	found.price = params.variant.price;
+	if (params.change != null) {
+		if (ctx._source.pendingPriceChanges == null) {
+			ctx._source.pendingPriceChanges = [];
+		}
+		ctx._source.pendingPriceChanges.add(params.change);
+	}
+}
+`
+
+const removeVariantScript = `
+if (ctx._source.variants == null || !ctx._source.variants.removeIf(v -> v.id == params.id)) {
+	ctx.op = 'none';
+} else {
+` + sumVariantStock + `
+}
+`
+
+func (r *elasticRepository) AddVariant(ctx context.Context, productID string, v Variant) (*Product, error) {
+	p, _, err := r.scriptProduct(ctx, productID, elastic.NewScriptInline(addVariantScript).
+		Lang("painless").
+		Param("variant", v))
+	return p, err
+}
+
+func (r *elasticRepository) UpdateVariant(ctx context.Context, productID string, v Variant, change *PriceChange) (*Product, error) {
+	var pending *pendingPriceChange
+	if change != nil {
+		pending = &pendingPriceChange{change.ID, priceChangeDocumentFrom(*change)}
+	}
+	p, changed, err := r.scriptProduct(ctx, productID, elastic.NewScriptInline(updateVariantScript).
+		Lang("painless").
+		Param("variant", v).
+		Param("change", pending))
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		if _, ok := p.Variant(v.ID); !ok {
+			return nil, ErrVariantNotFound
+		}
+		return nil, ErrProductChanged
+	}
+	return p, nil
+}
+
+func (r *elasticRepository) RemoveVariant(ctx context.Context, productID string, variantID string) (*Product, error) {
+	p, changed, err := r.scriptProduct(ctx, productID, elastic.NewScriptInline(removeVariantScript).
+		Lang("painless").
+		Param("id", variantID))
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, ErrVariantNotFound
+	}
+	return p, nil
+}
+
+// scriptProduct runs an update script on a product and returns the product as the script left it, along
+// with whether the script changed it at all. Price changes left pending in the product are copied into the
+// price history, including those of earlier updates, so that retrying an update completes it.
+func (r *elasticRepository) scriptProduct(ctx context.Context, id string, script *elastic.Script) (*Product, bool, error) {
+	res, err := r.client.Update().Index(catalogAlias).Type("product").Id(id).
+		Script(script).
+		Fields("_source").
+		RetryOnConflict(3).
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return nil, false, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if res.GetResult == nil || res.GetResult.Source == nil {
+		return nil, false, fmt.Errorf("update of product %s returned no product", id)
+	}
+	d := productDocument{}
+	if err := json.Unmarshal(*res.GetResult.Source, &d); err != nil {
+		return nil, false, err
+	}
+	p := productFromDocument(id, d)
+	if _, err := r.flushPriceChanges(ctx, p); err != nil {
+		return nil, false, err
+	}
+	p.pendingPriceChanges = nil
+	return &p, res.Result != "noop", nil
+}
+
+// Lists the products, archived ones included, that have a variant with the SKU
+func (r *elasticRepository) ListProductsWithSKU(ctx context.Context, sku string) ([]Product, error) {
+	res, err := r.client.Search().Index(catalogAlias).Type("product").
+		Query(elastic.NewTermQuery("variants.sku", sku)).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	products := []Product{}
+	for _, hit := range res.Hits.Hits {
+		p := productDocument{}
+		if err = json.Unmarshal(*hit.Source, &p); err == nil {
+			products = append(products, productFromDocument(hit.Id, p))
+		}
+	}
 	return products, nil
 }
+
+// Replaces the details of a product and what is derived from them, leaving e.g. the stock to concurrent
+// updates. A change of the price is kept in the product along with the new price, and only made while the
+// product still has the price it changes from.
+const updateDetailsScript = `
+if (params.change != null && ((Number)ctx._source.price).doubleValue() != ((Number)params.change.oldPrice).doubleValue()) {
+	ctx.op = 'none';
+} else {
+	ctx._source.name = params.name;
+	ctx._source.description = params.description;
+	ctx._source.price = params.price;
+	ctx._source.suggest = params.suggest;
+	if (params.change != null) {
+		if (ctx._source.pendingPriceChanges == null) {
+			ctx._source.pendingPriceChanges = [];
+		}
+		ctx._source.pendingPriceChanges.add(params.change);
+	}
+}
+`
+
+func (r *elasticRepository) UpdateProductDetails(ctx context.Context, p Product, change *PriceChange) error {
+	d := documentFromProduct(p)
+	var pending *pendingPriceChange
+	if change != nil {
+		pending = &pendingPriceChange{change.ID, priceChangeDocumentFrom(*change)}
+	}
+	_, changed, err := r.scriptProduct(ctx, p.ID, elastic.NewScriptInline(updateDetailsScript).
+		Lang("painless").
+		Param("name", d.Name).
+		Param("description", d.Description).
+		Param("price", d.Price).
+		Param("suggest", d.Suggest).
+		Param("change", pending))
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return ErrProductChanged
+	}
+	return nil
+}
+
+// Takes the price changes pending in a product out of it once they are copied
+const removePendingPriceChangesScript = `
+if (ctx._source.pendingPriceChanges != null) {
+	ctx._source.pendingPriceChanges.removeIf(c -> params.ids.contains(c.id));
+}
+`
+
+// flushPriceChanges copies the price changes pending in a product into the price_changes index and then
+// takes them out of the product. Copying a change again only overwrites it, so whatever fails stays pending,
+// for the next update of the product or read of its price history. It reports how many changes were copied.
+func (r *elasticRepository) flushPriceChanges(ctx context.Context, p Product) (int, error) {
+	if len(p.pendingPriceChanges) == 0 {
+		return 0, nil
+	}
+	if err := r.PutPriceChanges(ctx, p.pendingPriceChanges); err != nil {
+		return 0, err
+	}
+	ids := []string{}
+	for _, c := range p.pendingPriceChanges {
+		ids = append(ids, c.ID)
+	}
+	_, err := r.client.Update().Index(catalogAlias).Type("product").Id(p.ID).
+		Script(elastic.NewScriptInline(removePendingPriceChangesScript).
+			Lang("painless").
+			Param("ids", ids)).
+		RetryOnConflict(3).
+		Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+func (r *elasticRepository) UpdateProductRating(ctx context.Context, p Product) error {
+	_, err := r.client.Update().Index(catalogAlias).Type("product").Id(p.ID).
+		Doc(map[string]interface{}{
+			"averageRating": p.AverageRating,
+			"ratingCount":   p.RatingCount,
+		}).
+		RetryOnConflict(3).
+		Do(ctx)
+	return err
+}
+
+func (r *elasticRepository) UpdateProductTranslations(ctx context.Context, p Product) error {
+	// A partial document would be merged into the stored translations, so replace them as a whole
+	translations := p.Translations
+	if translations == nil {
+		translations = map[string]ProductTranslation{}
+	}
+	_, err := r.client.Update().Index(catalogAlias).Type("product").Id(p.ID).
+		Script(elastic.NewScriptInline("ctx._source.translations = params.translations").
+			Lang("painless").
+			Param("translations", translations)).
+		RetryOnConflict(3).
+		Do(ctx)
+	return err
+}
+
+// Scripts changing a single image in place, so that images uploaded or changed at the same time are kept.
+// Positions past the end put the image last.
+const addImageScript = `
+if (ctx._source.images == null) {
+	ctx._source.images = [];
+}
+int position = ctx._source.images.size();
+if (params.position != null && params.position < position) {
+	position = params.position;
+}
+ctx._source.images.add(position, params.image);
+`
+
+const updateImageScript = `
+int i = -1;
+if (ctx._source.images != null) {
+	for (int j = 0; j < ctx._source.images.size(); j++) {
+		if (ctx._source.images[j].id == params.id) {
+			i = j;
+		}
+	}
+}
+if (i < 0) {
+	ctx.op = 'none';
+} else {
+	def image = ctx._source.images[i];
+	ctx._source.images.removeIf(img -> img.id == params.id);
+	if (params.altText != null) {
+		image.altText = params.altText;
+	}
+	int position = i;
+	if (params.position != null) {
+		position = params.position;
+	}
+	if (position > ctx._source.images.size()) {
+		position = ctx._source.images.size();
+	}
+	ctx._source.images.add(position, image);
+}
+`
+
+const removeImageScript = `
+if (ctx._source.images == null || !ctx._source.images.removeIf(img -> img.id == params.id)) {
+	ctx.op = 'none';
+}
+`
+
+func (r *elasticRepository) AddProductImage(ctx context.Context, productID string, img ProductImage, position *int) (*Product, error) {
+	p, _, err := r.scriptProduct(ctx, productID, elastic.NewScriptInline(addImageScript).
+		Lang("painless").
+		Param("image", img).
+		Param("position", position))
+	return p, err
+}
+
+func (r *elasticRepository) UpdateProductImage(ctx context.Context, productID string, imageID string, update ImageUpdate) (*Product, error) {
+	p, changed, err := r.scriptProduct(ctx, productID, elastic.NewScriptInline(updateImageScript).
+		Lang("painless").
+		Param("id", imageID).
+		Param("altText", update.AltText).
+		Param("position", update.Position))
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, ErrImageNotFound
+	}
+	return p, nil
+}
+
+func (r *elasticRepository) RemoveProductImage(ctx context.Context, productID string, imageID string) (*Product, error) {
+	p, changed, err := r.scriptProduct(ctx, productID, elastic.NewScriptInline(removeImageScript).
+		Lang("painless").
+		Param("id", imageID))
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, ErrImageNotFound
+	}
+	return p, nil
+}
+
+func (r *elasticRepository) ArchiveProduct(ctx context.Context, id string) error {
+	_, err := r.client.Update().Index(catalogAlias).Type("product").Id(id).
+		Doc(map[string]interface{}{
+			"archived": true,
+			"suggest":  []string{},
+		}).
+		RetryOnConflict(3).
+		Do(ctx)
+	return err
+}
+
+func (r *elasticRepository) PutPriceChanges(ctx context.Context, changes []PriceChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	bulk := r.client.Bulk().Index("price_changes").Type("priceChange")
+	for _, c := range changes {
+		req := elastic.NewBulkIndexRequest().Doc(priceChangeDocumentFrom(c))
+		if c.ID != "" {
+			req.Id(c.ID)
+		}
+		bulk.Add(req)
+	}
+	res, err := bulk.Do(ctx)
+	if err != nil {
+		return err
+	}
+	if failed := res.Failed(); len(failed) > 0 {
+		return fmt.Errorf("could not record price change: %s", failed[0].Error.Reason)
+	}
+	return nil
+}
+
+func (r *elasticRepository) ListPriceChanges(ctx context.Context, productID string, skip uint64, take uint64) ([]PriceChange, error) {
+	// Changes still pending in the product are copied over first, so that they are listed
+	p, err := r.GetProductByID(ctx, productID)
+	if err != nil && !errors.Is(err, ErrProductNotFound) {
+		return nil, err
+	}
+	if p != nil {
+		copied, err := r.flushPriceChanges(ctx, *p)
+		if err != nil {
+			return nil, err
+		}
+		if copied > 0 {
+			if _, err := r.client.Refresh("price_changes").Do(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	res, err := r.client.Search().Index("price_changes").Type("priceChange").
+		Query(elastic.NewTermQuery("productId", productID)).
+		Sort("changedAt", false).
+		From(int(skip)).Size(int(take)).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []PriceChange{}
+	for _, hit := range res.Hits.Hits {
+		c := priceChangeDocument{}
+		if err = json.Unmarshal(*hit.Source, &c); err == nil {
+			changes = append(changes, c.priceChange(hit.Id))
+		}
+	}
+	return changes, nil
+}
+
+func (r *elasticRepository) UpdateProductCategory(ctx context.Context, p Product) error {
+	// Only replaces the category and tag fields, leaving e.g. the stock to concurrent updates
+	_, err := r.client.Update().Index(catalogAlias).Type("product").Id(p.ID).
+		Doc(map[string]interface{}{
+			"categoryId":   p.CategoryID,
+			"categoryPath": p.CategoryPath,
+			"tags":         p.Tags,
+		}).
+		RetryOnConflict(3).
+		Do(ctx)
+	return err
+}
+
+func (r *elasticRepository) PutCategory(ctx context.Context, c Category) error {
+	_, err := r.client.Index().Index("categories").Type("category").Id(c.ID).
+		BodyJson(categoryDocument{
+			Name:     c.Name,
+			ParentID: c.ParentID,
+			Path:     c.Path,
+		}).
+		// Make the category visible to searches right away, e.g. when listing the tree
+		Refresh("wait_for").
+		Do(ctx)
+	return err
+}
+
+func (r *elasticRepository) GetCategoryByID(ctx context.Context, id string) (*Category, error) {
+	res, err := r.client.Get().Index("categories").Type("category").Id(id).Do(ctx)
+	if elastic.IsNotFound(err) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !res.Found {
+		return nil, ErrCategoryNotFound
+	}
+
+	c := categoryDocument{}
+	if err = json.Unmarshal(*res.Source, &c); err != nil {
+		return nil, err
+	}
+	return &Category{
+		ID:       id,
+		Name:     c.Name,
+		ParentID: c.ParentID,
+		Path:     c.Path,
+	}, nil
+}
+
+func (r *elasticRepository) ListCategories(ctx context.Context) ([]Category, error) {
+	// Category trees are small, so they are listed in a single page
+	res, err := r.client.Search().Index("categories").Type("category").
+		Query(elastic.NewMatchAllQuery()).
+		Size(10000).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []Category{}
+	for _, hit := range res.Hits.Hits {
+		c := categoryDocument{}
+		if err = json.Unmarshal(*hit.Source, &c); err == nil {
+			categories = append(categories, Category{
+				ID:       hit.Id,
+				Name:     c.Name,
+				ParentID: c.ParentID,
+				Path:     c.Path,
+			})
+		}
+	}
+	return categories, nil
+}
+
+// A category is in use while it has subcategories or products in it
+func (r *elasticRepository) CategoryInUse(ctx context.Context, id string) (bool, error) {
+	children, err := r.client.Count("categories").Type("category").Query(elastic.NewTermQuery("parentId", id)).Do(ctx)
+	if err != nil {
+		return false, err
+	}
+	if children > 0 {
+		return true, nil
+	}
+	products, err := r.client.Count(catalogAlias).Type("product").Query(elastic.NewTermQuery("categoryId", id)).Do(ctx)
+	if err != nil {
+		return false, err
+	}
+	return products > 0, nil
+}
+
+func (r *elasticRepository) DeleteCategory(ctx context.Context, id string) error {
+	_, err := r.client.Delete().Index("categories").Type("category").Id(id).Refresh("wait_for").Do(ctx)
+	return err
+}
+
+// Rules are stored as they are, apart from their ID, which is that of their document
+type merchandisingRuleDocument struct {
+	Query      string           `json:"query"`
+	CategoryID string           `json:"categoryId"`
+	Pinned     []string         `json:"pinned"`
+	Boosts     []AttributeBoost `json:"boosts"`
+	Buried     []string         `json:"buried"`
+	Hidden     []string         `json:"hidden"`
+}
+
+func ruleFromDocument(id string, d merchandisingRuleDocument) MerchandisingRule {
+	return MerchandisingRule{
+		ID:         id,
+		Query:      d.Query,
+		CategoryID: d.CategoryID,
+		Pinned:     stringsOrEmpty(d.Pinned),
+		Boosts:     boostsOrEmpty(d.Boosts),
+		Buried:     stringsOrEmpty(d.Buried),
+		Hidden:     stringsOrEmpty(d.Hidden),
+	}
+}
+
+func (r *elasticRepository) PutMerchandisingRule(ctx context.Context, rule MerchandisingRule) error {
+	_, err := r.client.Index().Index("merchandising_rules").Type("rule").Id(rule.ID).
+		BodyJson(merchandisingRuleDocument{
+			Query:      rule.Query,
+			CategoryID: rule.CategoryID,
+			Pinned:     rule.Pinned,
+			Boosts:     rule.Boosts,
+			Buried:     rule.Buried,
+			Hidden:     rule.Hidden,
+		}).
+		// Rules are listed for every search, which should follow them right away
+		Refresh("wait_for").
+		Do(ctx)
+	return err
+}
+
+func (r *elasticRepository) GetMerchandisingRule(ctx context.Context, id string) (*MerchandisingRule, error) {
+	res, err := r.client.Get().Index("merchandising_rules").Type("rule").Id(id).Do(ctx)
+	if elastic.IsNotFound(err) {
+		return nil, ErrRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !res.Found {
+		return nil, ErrRuleNotFound
+	}
+
+	d := merchandisingRuleDocument{}
+	if err = json.Unmarshal(*res.Source, &d); err != nil {
+		return nil, err
+	}
+	rule := ruleFromDocument(id, d)
+	return &rule, nil
+}
+
+func (r *elasticRepository) ListMerchandisingRules(ctx context.Context) ([]MerchandisingRule, error) {
+	// There are few enough rules to list them in a single page
+	res, err := r.client.Search().Index("merchandising_rules").Type("rule").
+		Query(elastic.NewMatchAllQuery()).
+		Size(10000).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []MerchandisingRule{}
+	for _, hit := range res.Hits.Hits {
+		d := merchandisingRuleDocument{}
+		if err = json.Unmarshal(*hit.Source, &d); err == nil {
+			rules = append(rules, ruleFromDocument(hit.Id, d))
+		}
+	}
+	return rules, nil
+}
+
+func (r *elasticRepository) DeleteMerchandisingRule(ctx context.Context, id string) error {
+	_, err := r.client.Delete().Index("merchandising_rules").Type("rule").Id(id).Refresh("wait_for").Do(ctx)
+	return err
+}
+
+// The vocabulary is stored as a single document, the one the catalog indices are created with
+const vocabularyID = "current"
+
+func (r *elasticRepository) GetSearchVocabulary(ctx context.Context) (*SearchVocabulary, error) {
+	v := &SearchVocabulary{}
+	res, err := r.client.Get().Index("search_vocabulary").Type("vocabulary").Id(vocabularyID).Do(ctx)
+	switch {
+	case elastic.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	case res.Found:
+		if err := json.Unmarshal(*res.Source, v); err != nil {
+			return nil, err
+		}
+	}
+	if v.Synonyms == nil {
+		v.Synonyms = [][]string{}
+	}
+	v.Stopwords = stringsOrEmpty(v.Stopwords)
+	return v, nil
+}
+
+// The analyzers of an open index can't change, and closing the serving index would fail searches meanwhile,
+// so the catalog is reindexed with the new ones. The next index is created before the vocabulary is stored,
+// so that a vocabulary Elasticsearch rejects isn't kept for the next reindex, and the products are copied
+// into it in the background. Searches use the previous vocabulary until the alias moves over.
+func (r *elasticRepository) PutSearchVocabulary(ctx context.Context, v SearchVocabulary) error {
+	if !r.reindexing.TryLock() {
+		return ErrVocabularyPending
+	}
+	indices, index, err := r.createNextIndex(ctx, &v)
+	if err != nil {
+		r.reindexing.Unlock()
+		return err
+	}
+	_, err = r.client.Index().Index("search_vocabulary").Type("vocabulary").Id(vocabularyID).
+		BodyJson(v).
+		Refresh("wait_for").
+		Do(ctx)
+	if err != nil {
+		r.reindexing.Unlock()
+		return err
+	}
+
+	// The copy outlives the request, and a failed one is left for the next reindex to clean up
+	go func() {
+		defer r.reindexing.Unlock()
+		if copied, err := r.fillIndex(context.WithoutCancel(ctx), indices, index); err != nil {
+			log.Printf("could not reindex the catalog for the new search vocabulary, run the reindex command: %v", err)
+		} else {
+			log.Printf("reindexed %d products into %s for the new search vocabulary", copied, index)
+		}
+	}()
+	return nil
+}
+
+type searchLogDocument struct {
+	Query      string    `json:"query"`
+	Locale     string    `json:"locale"`
+	CategoryID string    `json:"categoryId"`
+	Hits       uint64    `json:"hits"`
+	SearchedAt time.Time `json:"searchedAt"`
+}
+
+// Searches are logged without waiting for a refresh, as reports don't need to be up to the second
+func (r *elasticRepository) LogSearch(ctx context.Context, entry SearchLogEntry) error {
+	_, err := r.client.Index().Index("search_log").Type("search").
+		BodyJson(searchLogDocument(entry)).
+		Do(ctx)
+	return err
+}
+
+func (r *elasticRepository) GetSearchReport(ctx context.Context, since time.Time, size uint64) (*SearchReport, error) {
+	// Searches without a query are counted, but left out of the lists of queries
+	queries := func() *elastic.TermsAggregation {
+		return elastic.NewTermsAggregation().Field("query").ExcludeValues("").Size(int(size))
+	}
+	res, err := r.client.Search().Index("search_log").Type("search").
+		Query(elastic.NewBoolQuery().Filter(elastic.NewRangeQuery("searchedAt").Gte(since))).
+		Aggregation("queries", queries()).
+		Aggregation("zero_results", elastic.NewFilterAggregation().
+			Filter(elastic.NewTermQuery("hits", 0)).
+			SubAggregation("queries", queries())).
+		Size(0).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SearchReport{
+		Since:             since,
+		Searches:          uint64(res.Hits.TotalHits),
+		TopQueries:        queryStats(res.Aggregations, "queries"),
+		ZeroResultQueries: []QueryStats{},
+	}
+	if zeroResults, ok := res.Aggregations.Filter("zero_results"); ok {
+		report.ZeroResultSearches = uint64(zeroResults.DocCount)
+		report.ZeroResultQueries = queryStats(zeroResults.Aggregations, "queries")
+	}
+	return report, nil
+}
+
+func queryStats(aggs elastic.Aggregations, name string) []QueryStats {
+	queries := []QueryStats{}
+	for _, c := range facetCounts(aggs, name) {
+		queries = append(queries, QueryStats{Query: c.Value, Searches: c.Count})
+	}
+	return queries
+}
@@ -0,0 +1,81 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+var ErrInvalidReport = errors.New("search reports list between 1 and 100 queries, from a time in the past")
+
+const (
+	defaultReportSize = 20
+	maxReportSize     = 100
+	// Reports without a start cover this far back
+	defaultReportPeriod = 7 * 24 * time.Hour
+)
+
+// SearchLogEntry records a search and how many products it found, to learn what customers look for
+type SearchLogEntry struct {
+	// The query as it is compared to those of other searches, empty for searches that only filter
+	Query      string
+	Locale     string
+	CategoryID string
+	Hits       uint64
+	SearchedAt time.Time
+}
+
+// QueryStats counts the searches for a query
+type QueryStats struct {
+	Query    string
+	Searches uint64
+}
+
+// SearchReport summarizes the searches since a time. Searches without a query are counted, but not
+// listed as a query.
+type SearchReport struct {
+	Since              time.Time
+	Searches           uint64
+	ZeroResultSearches uint64
+	// The most searched queries, and those most searched that didn't find anything, most searched first
+	TopQueries        []QueryStats
+	ZeroResultQueries []QueryStats
+}
+
+// How long recording a search can take once the search itself has returned
+const logSearchTimeout = 5 * time.Second
+
+// Records a search in the log in the background, so that searches neither wait for it nor fail if the
+// search can't be recorded
+func (s *catalogService) logSearch(ctx context.Context, query ProductQuery, hits uint64) {
+	entry := SearchLogEntry{
+		Query:      normalizeQuery(query.Query),
+		Locale:     query.Locale,
+		CategoryID: query.Filter.CategoryID,
+		Hits:       hits,
+		SearchedAt: time.Now().UTC(),
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), logSearchTimeout)
+	go func() {
+		defer cancel()
+		if err := s.repository.LogSearch(ctx, entry); err != nil {
+			log.Println(err)
+		}
+	}()
+}
+
+// Reports on the searches since a time, or of the last week if it is zero, listing up to size queries
+func (s *catalogService) GetSearchReport(ctx context.Context, since time.Time, size uint64) (*SearchReport, error) {
+	if size == 0 {
+		size = defaultReportSize
+	}
+	now := time.Now().UTC()
+	if since.IsZero() {
+		since = now.Add(-defaultReportPeriod)
+	}
+	if size > maxReportSize || since.After(now) {
+		return nil, ErrInvalidReport
+	}
+	return s.repository.GetSearchReport(ctx, since.UTC(), size)
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"github.com/PranavTrip/go-grpc-graphql-ms/catalog/pb"
+	"io"
 	"log"
 	"net"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type grpcServer struct {
@@ -21,7 +25,11 @@ func ListenGRPC(s Service, port int) error {
 	if err != nil {
 		return err
 	}
+	return ServeGRPC(s, lis)
+}
 
+// ServeGRPC serves the catalog service on lis until it is closed
+func ServeGRPC(s Service, lis net.Listener) error {
 	serv := grpc.NewServer()
 	pb.RegisterCatalogServiceServer(serv, &grpcServer{UnimplementedCatalogServiceServer: pb.UnimplementedCatalogServiceServer{}, service: s})
 	reflection.Register(serv)
@@ -30,18 +38,13 @@ func ListenGRPC(s Service, port int) error {
 
 func (s *grpcServer) PostProduct(ctx context.Context, r *pb.PostProductRequest) (*pb.PostProductResponse, error) {
 	// Calls the service function to create product
-	p, err := s.service.PostProduct(ctx, r.Name, r.Description, r.Price)
+	p, err := s.service.PostProduct(ctx, r.Name, r.Description, r.Price, r.Stock, r.CategoryId, r.Tags)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 	// return the response in the form of protobuf
-	return &pb.PostProductResponse{Product: &pb.Product{
-		Id:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-	}}, nil
+	return &pb.PostProductResponse{Product: productToProto(p)}, nil
 }
 
 func (s *grpcServer) GetProduct(ctx context.Context, r *pb.GetProductRequest) (*pb.GetProductResponse, error) {
@@ -52,43 +55,513 @@ func (s *grpcServer) GetProduct(ctx context.Context, r *pb.GetProductRequest) (*
 		return nil, err
 	}
 
-	return &pb.GetProductResponse{Product: &pb.Product{
-		Id:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-	}}, nil
+	return &pb.GetProductResponse{Product: productToProto(p)}, nil
 }
 
 func (s *grpcServer) GetProducts(ctx context.Context, r *pb.GetProductsRequest) (*pb.GetProductsResponse, error) {
-	// Variable to store the response
-	var res []Product
-	var err error
+	// If the length of product ids is not 0, call the service GetProductsByID method
+	if len(r.Ids) != 0 {
+		res, err := s.service.GetProductsByIDs(ctx, r.Ids, r.Skip, r.Take)
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		products := []*pb.Product{}
+		for _, p := range res {
+			products = append(products, productToProto(&p))
+		}
+		return &pb.GetProductsResponse{Products: products, TotalCount: uint64(len(products)), Facets: &pb.ProductFacets{}}, nil
+	}
+
+	// Else search the products, matching all of them without a query, narrowed down by the filter
+	res, err := s.service.SearchProducts(ctx, ProductQuery{
+		Query: r.Query,
+		Filter: ProductFilter{
+			CategoryID: r.CategoryId,
+			Tags:       r.Tags,
+			MinPrice:   r.MinPrice,
+			MaxPrice:   r.MaxPrice,
+			InStock:    r.InStock,
+		},
+		Sort:      ProductSort(r.Sort),
+		Locale:    r.Locale,
+		Skip:      r.Skip,
+		Take:      r.Take,
+		AccountID: r.AccountId,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	products := []*pb.Product{}
+	for _, p := range res.Products {
+		products = append(products, productToProto(&p))
+	}
+	return &pb.GetProductsResponse{
+		Products:   products,
+		TotalCount: res.Total,
+		Facets: &pb.ProductFacets{
+			Categories: facetCountsToProto(res.Facets.Categories),
+			Tags:       facetCountsToProto(res.Facets.Tags),
+		},
+	}, nil
+}
 
-	// If query is not empty then call the service SearchProducts method
-	if r.Query != "" {
-		res, err = s.service.SearchProducts(ctx, r.Query, r.Skip, r.Take)
+func (s *grpcServer) UpdateProduct(ctx context.Context, r *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
+	// Calls the service function to change the given details of the product
+	p, err := s.service.UpdateProduct(ctx, r.Id, ProductUpdate{
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       r.Price,
+	}, r.ChangedBy)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.UpdateProductResponse{Product: productToProto(p)}, nil
+}
 
-		// if the length of product ids is not 0, call the service GetProductsByID method
-	} else if len(r.Ids) != 0 {
-		res, err = s.service.GetProductsByIDs(ctx, r.Ids, r.Skip, r.Take)
-		// Else call the service GetProducts method
-	} else {
-		res, err = s.service.GetProducts(ctx, r.Skip, r.Take)
+func (s *grpcServer) ArchiveProduct(ctx context.Context, r *pb.ArchiveProductRequest) (*pb.ArchiveProductResponse, error) {
+	p, err := s.service.ArchiveProduct(ctx, r.Id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
 	}
+	return &pb.ArchiveProductResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) GetPriceHistory(ctx context.Context, r *pb.GetPriceHistoryRequest) (*pb.GetPriceHistoryResponse, error) {
+	res, err := s.service.GetPriceHistory(ctx, r.ProductId, r.Skip, r.Take)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
-	products := []*pb.Product{}
+	changes := []*pb.PriceChange{}
+	for _, c := range res {
+		changedAt, _ := c.ChangedAt.MarshalBinary()
+		changes = append(changes, &pb.PriceChange{
+			ProductId: c.ProductID,
+			VariantId: c.VariantID,
+			OldPrice:  c.OldPrice,
+			NewPrice:  c.NewPrice,
+			ChangedBy: c.ChangedBy,
+			ChangedAt: changedAt,
+		})
+	}
+	return &pb.GetPriceHistoryResponse{Changes: changes}, nil
+}
+
+func (s *grpcServer) AdjustStock(ctx context.Context, r *pb.AdjustStockRequest) (*pb.AdjustStockResponse, error) {
+	// Calls the service function to add the delta to the stock of the product or its variant
+	p, err := s.service.AdjustStock(ctx, r.ProductId, r.VariantId, r.Delta)
+	if err == ErrOutOfStock {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &pb.AdjustStockResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) UpdateRating(ctx context.Context, r *pb.UpdateRatingRequest) (*pb.UpdateRatingResponse, error) {
+	// Calls the service function to store the rating the review service computed for the product
+	p, err := s.service.UpdateRating(ctx, r.ProductId, r.AverageRating, r.RatingCount)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &pb.UpdateRatingResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) TranslateProduct(ctx context.Context, r *pb.TranslateProductRequest) (*pb.TranslateProductResponse, error) {
+	// Calls the service function to set or remove the product's name and description in the locale
+	p, err := s.service.TranslateProduct(ctx, r.ProductId, r.Locale, r.Name, r.Description)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &pb.TranslateProductResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) PutVariant(ctx context.Context, r *pb.PutVariantRequest) (*pb.PutVariantResponse, error) {
+	if r.Variant == nil {
+		return nil, ErrInvalidVariant
+	}
+	p, err := s.service.PutVariant(ctx, r.ProductId, variantFromProto(r.Variant), r.ChangedBy)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.PutVariantResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) DeleteVariant(ctx context.Context, r *pb.DeleteVariantRequest) (*pb.DeleteVariantResponse, error) {
+	p, err := s.service.DeleteVariant(ctx, r.ProductId, r.VariantId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.DeleteVariantResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) CategorizeProduct(ctx context.Context, r *pb.CategorizeProductRequest) (*pb.CategorizeProductResponse, error) {
+	// Calls the service function to move the product into the category and replace its tags
+	p, err := s.service.CategorizeProduct(ctx, r.ProductId, r.CategoryId, r.Tags)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.CategorizeProductResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) PostCategory(ctx context.Context, r *pb.PostCategoryRequest) (*pb.PostCategoryResponse, error) {
+	c, err := s.service.PostCategory(ctx, r.Name, r.ParentId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.PostCategoryResponse{Category: categoryToProto(c)}, nil
+}
 
+func (s *grpcServer) GetCategories(ctx context.Context, r *pb.GetCategoriesRequest) (*pb.GetCategoriesResponse, error) {
+	res, err := s.service.GetCategories(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	categories := []*pb.Category{}
+	for _, c := range res {
+		categories = append(categories, categoryToProto(&c))
+	}
+	return &pb.GetCategoriesResponse{Categories: categories}, nil
+}
+
+func (s *grpcServer) DeleteCategory(ctx context.Context, r *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
+	if err := s.service.DeleteCategory(ctx, r.Id); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.DeleteCategoryResponse{}, nil
+}
+
+func (s *grpcServer) SuggestProducts(ctx context.Context, r *pb.SuggestProductsRequest) (*pb.SuggestProductsResponse, error) {
+	res, err := s.service.SuggestProducts(ctx, r.Prefix, r.Size)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	suggestions := []*pb.ProductSuggestion{}
 	for _, p := range res {
-		products = append(products, &pb.Product{
-			Id:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
+		suggestions = append(suggestions, &pb.ProductSuggestion{
+			Id:    p.ID,
+			Name:  p.Name,
+			Score: p.Score,
+		})
+	}
+	return &pb.SuggestProductsResponse{Suggestions: suggestions}, nil
+}
+
+func (s *grpcServer) ImportProducts(stream pb.CatalogService_ImportProductsServer) error {
+	// Import the rows batch by batch as they arrive, adding up the results
+	res := &pb.ImportProductsResponse{}
+	first := true
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(res)
+		}
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		if first {
+			res.DryRun = r.DryRun
+			first = false
+		}
+
+		rows := []ProductRow{}
+		for _, row := range r.Rows {
+			rows = append(rows, ProductRow{
+				Line:        row.Line,
+				ID:          row.Id,
+				Name:        row.Name,
+				Description: row.Description,
+				Price:       row.Price,
+				Stock:       row.Stock,
+				CategoryID:  row.CategoryId,
+				Tags:        row.Tags,
+			})
+		}
+		result, err := s.service.ImportProducts(stream.Context(), rows, res.DryRun)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		res.Imported += result.Imported
+		for _, e := range result.Errors {
+			res.Errors = append(res.Errors, &pb.RowError{Line: e.Line, Message: e.Message})
+		}
+	}
+}
+
+func (s *grpcServer) ExportProducts(r *pb.ExportProductsRequest, stream pb.CatalogService_ExportProductsServer) error {
+	// Send the products a page at a time
+	err := s.service.ExportProducts(stream.Context(), func(products []Product) error {
+		res := &pb.ExportProductsResponse{}
+		for _, p := range products {
+			res.Products = append(res.Products, productToProto(&p))
+		}
+		return stream.Send(res)
+	})
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+func (s *grpcServer) UploadProductImage(stream pb.CatalogService_UploadProductImageServer) error {
+	// Collect the file from its chunks, which follow the description of the image
+	var info *pb.ImageUploadInfo
+	data := []byte{}
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		if info == nil {
+			if info = r.GetInfo(); info == nil {
+				return ErrInvalidImage
+			}
+			continue
+		}
+		data = append(data, r.GetChunk()...)
+		if len(data) > MaxImageSize {
+			return ErrInvalidImage
+		}
+	}
+	if info == nil {
+		return ErrInvalidImage
+	}
+
+	upload := ImageUpload{Data: data, AltText: info.AltText, Position: optionalInt(info.Position)}
+	p, err := s.service.UploadProductImage(stream.Context(), info.ProductId, upload)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return stream.SendAndClose(&pb.UploadProductImageResponse{Product: productToProto(p)})
+}
+
+func (s *grpcServer) UpdateProductImage(ctx context.Context, r *pb.UpdateProductImageRequest) (*pb.UpdateProductImageResponse, error) {
+	update := ImageUpdate{AltText: r.AltText, Position: optionalInt(r.Position)}
+	p, err := s.service.UpdateProductImage(ctx, r.ProductId, r.ImageId, update)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.UpdateProductImageResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) DeleteProductImage(ctx context.Context, r *pb.DeleteProductImageRequest) (*pb.DeleteProductImageResponse, error) {
+	p, err := s.service.DeleteProductImage(ctx, r.ProductId, r.ImageId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.DeleteProductImageResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) PutMerchandisingRule(ctx context.Context, r *pb.PutMerchandisingRuleRequest) (*pb.PutMerchandisingRuleResponse, error) {
+	if r.Rule == nil {
+		return nil, ErrInvalidRule
+	}
+	rule, err := s.service.PutMerchandisingRule(ctx, merchandisingRuleFromProto(r.Rule))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.PutMerchandisingRuleResponse{Rule: merchandisingRuleToProto(*rule)}, nil
+}
+
+func (s *grpcServer) GetMerchandisingRules(ctx context.Context, r *pb.GetMerchandisingRulesRequest) (*pb.GetMerchandisingRulesResponse, error) {
+	res, err := s.service.GetMerchandisingRules(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	rules := []*pb.MerchandisingRule{}
+	for _, rule := range res {
+		rules = append(rules, merchandisingRuleToProto(rule))
+	}
+	return &pb.GetMerchandisingRulesResponse{Rules: rules}, nil
+}
+
+func (s *grpcServer) DeleteMerchandisingRule(ctx context.Context, r *pb.DeleteMerchandisingRuleRequest) (*pb.DeleteMerchandisingRuleResponse, error) {
+	if err := s.service.DeleteMerchandisingRule(ctx, r.Id); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.DeleteMerchandisingRuleResponse{}, nil
+}
+
+func (s *grpcServer) GetSearchVocabulary(ctx context.Context, r *pb.GetSearchVocabularyRequest) (*pb.GetSearchVocabularyResponse, error) {
+	v, err := s.service.GetSearchVocabulary(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.GetSearchVocabularyResponse{Vocabulary: vocabularyToProto(*v)}, nil
+}
+
+func (s *grpcServer) PutSearchVocabulary(ctx context.Context, r *pb.PutSearchVocabularyRequest) (*pb.PutSearchVocabularyResponse, error) {
+	if r.Vocabulary == nil {
+		return nil, ErrInvalidVocabulary
+	}
+	v, err := s.service.PutSearchVocabulary(ctx, vocabularyFromProto(r.Vocabulary))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.PutSearchVocabularyResponse{Vocabulary: vocabularyToProto(*v)}, nil
+}
+
+func (s *grpcServer) GetSearchReport(ctx context.Context, r *pb.GetSearchReportRequest) (*pb.GetSearchReportResponse, error) {
+	var since time.Time
+	if len(r.Since) > 0 {
+		if err := since.UnmarshalBinary(r.Since); err != nil {
+			return nil, ErrInvalidReport
+		}
+	}
+	report, err := s.service.GetSearchReport(ctx, since, r.Size)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	res := &pb.GetSearchReportResponse{
+		Searches:           report.Searches,
+		ZeroResultSearches: report.ZeroResultSearches,
+		TopQueries:         queryStatsToProto(report.TopQueries),
+		ZeroResultQueries:  queryStatsToProto(report.ZeroResultQueries),
+	}
+	res.Since, _ = report.Since.MarshalBinary()
+	return res, nil
+}
+
+func optionalInt(n *uint32) *int {
+	if n == nil {
+		return nil
+	}
+	i := int(*n)
+	return &i
+}
+
+// Convert the product to protobuf format for grpc
+func productToProto(p *Product) *pb.Product {
+	protoProduct := &pb.Product{
+		Id:            p.ID,
+		Name:          p.Name,
+		Description:   p.Description,
+		Price:         p.Price,
+		Stock:         p.Stock,
+		CategoryId:    p.CategoryID,
+		CategoryPath:  p.CategoryPath,
+		Tags:          p.Tags,
+		Archived:      p.Archived,
+		AverageRating: p.AverageRating,
+		RatingCount:   p.RatingCount,
+	}
+	if !p.CreatedAt.IsZero() {
+		protoProduct.CreatedAt, _ = p.CreatedAt.MarshalBinary()
+	}
+	for _, v := range p.Variants {
+		protoProduct.Variants = append(protoProduct.Variants, variantToProto(v))
+	}
+	if len(p.Translations) > 0 {
+		protoProduct.Translations = map[string]*pb.ProductTranslation{}
+		for locale, t := range p.Translations {
+			protoProduct.Translations[locale] = &pb.ProductTranslation{Name: t.Name, Description: t.Description}
+		}
+	}
+	for _, img := range p.Images {
+		protoProduct.Images = append(protoProduct.Images, &pb.ProductImage{
+			Id:           img.ID,
+			Url:          img.URL,
+			ThumbnailUrl: img.ThumbnailURL,
+			AltText:      img.AltText,
+			ContentType:  img.ContentType,
+			Width:        uint32(img.Width),
+			Height:       uint32(img.Height),
 		})
 	}
-	return &pb.GetProductsResponse{Products: products}, nil
+	return protoProduct
+}
+
+func variantToProto(v Variant) *pb.Variant {
+	protoVariant := &pb.Variant{
+		Id:    v.ID,
+		Sku:   v.SKU,
+		Price: v.Price,
+		Stock: v.Stock,
+	}
+	for _, o := range v.Options {
+		protoVariant.Options = append(protoVariant.Options, &pb.VariantOption{Name: o.Name, Value: o.Value})
+	}
+	return protoVariant
+}
+
+func categoryToProto(c *Category) *pb.Category {
+	return &pb.Category{
+		Id:       c.ID,
+		Name:     c.Name,
+		ParentId: c.ParentID,
+		Path:     c.Path,
+	}
+}
+
+func facetCountsToProto(counts []FacetCount) []*pb.FacetCount {
+	protoCounts := []*pb.FacetCount{}
+	for _, c := range counts {
+		protoCounts = append(protoCounts, &pb.FacetCount{Value: c.Value, Count: c.Count})
+	}
+	return protoCounts
+}
+
+func merchandisingRuleToProto(rule MerchandisingRule) *pb.MerchandisingRule {
+	boosts := []*pb.AttributeBoost{}
+	for _, b := range rule.Boosts {
+		boosts = append(boosts, &pb.AttributeBoost{Attribute: string(b.Attribute), Value: b.Value, Factor: b.Factor})
+	}
+	return &pb.MerchandisingRule{
+		Id:         rule.ID,
+		Query:      rule.Query,
+		CategoryId: rule.CategoryID,
+		Pinned:     rule.Pinned,
+		Boosts:     boosts,
+		Buried:     rule.Buried,
+		Hidden:     rule.Hidden,
+	}
+}
+
+func vocabularyToProto(v SearchVocabulary) *pb.SearchVocabulary {
+	synonyms := []*pb.SynonymGroup{}
+	for _, group := range v.Synonyms {
+		synonyms = append(synonyms, &pb.SynonymGroup{Terms: group})
+	}
+	return &pb.SearchVocabulary{Synonyms: synonyms, Stopwords: v.Stopwords}
+}
+
+func queryStatsToProto(queries []QueryStats) []*pb.QueryStats {
+	protoQueries := []*pb.QueryStats{}
+	for _, q := range queries {
+		protoQueries = append(protoQueries, &pb.QueryStats{Query: q.Query, Searches: q.Searches})
+	}
+	return protoQueries
 }
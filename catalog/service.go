@@ -2,40 +2,215 @@ package catalog
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
 
+	"github.com/PranavTrip/go-grpc-graphql-ms/blob"
 	"github.com/segmentio/ksuid"
 )
 
+var (
+	ErrProductNotFound     = errors.New("product not found")
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryInUse       = errors.New("category still has subcategories or products")
+	ErrInvalidCategory     = errors.New("category needs a name")
+	ErrInvalidProductQuery = errors.New("invalid product sort or price range")
+	ErrInvalidProduct      = errors.New("product needs a name and a non-negative price")
+	ErrInvalidStock        = errors.New("stock can't be negative")
+	ErrOutOfStock          = errors.New("not enough stock left")
+	ErrInvalidRating       = errors.New("average rating must be between 1 and 5, or 0 without ratings")
+	ErrProductChanged      = errors.New("product was changed by someone else meanwhile, try again")
+)
+
+// How often a change of a product is tried again when the product changed between reading and writing it
+const maxProductRetries = 3
+
 type Service interface {
-	PostProduct(ctx context.Context, name string, description string, price float64) (*Product, error)
+	PostProduct(ctx context.Context, name string, description string, price float64, stock int64, categoryID string, tags []string) (*Product, error)
 	GetProduct(ctx context.Context, id string) (*Product, error)
+	UpdateProduct(ctx context.Context, id string, update ProductUpdate, changedBy string) (*Product, error)
+	ArchiveProduct(ctx context.Context, id string) (*Product, error)
+	GetPriceHistory(ctx context.Context, productID string, skip uint64, take uint64) ([]PriceChange, error)
 	GetProducts(ctx context.Context, skip uint64, take uint64) ([]Product, error)
 	GetProductsByIDs(ctx context.Context, ids []string, skip uint64, take uint64) ([]Product, error)
-	SearchProducts(ctx context.Context, query string, skip uint64, take uint64) ([]Product, error)
+	SearchProducts(ctx context.Context, query ProductQuery) (*ProductSearchResult, error)
+	AdjustStock(ctx context.Context, id string, variantID string, delta int64) (*Product, error)
+	PutVariant(ctx context.Context, productID string, variant Variant, changedBy string) (*Product, error)
+	DeleteVariant(ctx context.Context, productID string, variantID string) (*Product, error)
+	CategorizeProduct(ctx context.Context, id string, categoryID string, tags []string) (*Product, error)
+	PostCategory(ctx context.Context, name string, parentID string) (*Category, error)
+	GetCategories(ctx context.Context) ([]Category, error)
+	DeleteCategory(ctx context.Context, id string) error
+	SuggestProducts(ctx context.Context, prefix string, size uint64) ([]ProductSuggestion, error)
+	UpdateRating(ctx context.Context, id string, averageRating float64, ratingCount uint64) (*Product, error)
+	TranslateProduct(ctx context.Context, id string, locale string, name string, description string) (*Product, error)
+	UploadProductImage(ctx context.Context, productID string, upload ImageUpload) (*Product, error)
+	UpdateProductImage(ctx context.Context, productID string, imageID string, update ImageUpdate) (*Product, error)
+	DeleteProductImage(ctx context.Context, productID string, imageID string) (*Product, error)
+	PutMerchandisingRule(ctx context.Context, rule MerchandisingRule) (*MerchandisingRule, error)
+	GetMerchandisingRules(ctx context.Context) ([]MerchandisingRule, error)
+	DeleteMerchandisingRule(ctx context.Context, id string) error
+	GetSearchVocabulary(ctx context.Context) (*SearchVocabulary, error)
+	PutSearchVocabulary(ctx context.Context, v SearchVocabulary) (*SearchVocabulary, error)
+	GetSearchReport(ctx context.Context, since time.Time, size uint64) (*SearchReport, error)
+	ImportProducts(ctx context.Context, rows []ProductRow, dryRun bool) (*ImportResult, error)
+	ExportProducts(ctx context.Context, fn func(products []Product) error) error
 }
 
 type Product struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Price       float64   `json:"price"`
+	Stock       int64     `json:"stock"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// The category the product is in, and the IDs of all categories from the root down to it
+	CategoryID   string   `json:"categoryId"`
+	CategoryPath []string `json:"categoryPath"`
+	Tags         []string `json:"tags"`
+	// Archived products are no longer sold or listed, but can still be looked up by ID, e.g. for old orders
+	Archived bool `json:"archived"`
+	// Products with variants are sold as one of them; their stock is the sum of the variants' stock
+	Variants []Variant `json:"variants"`
+	// Average star rating of the product's approved reviews and how many there are, kept up to date by the
+	// review service
+	AverageRating float64 `json:"averageRating"`
+	RatingCount   uint64  `json:"ratingCount"`
+	// Name and description in locales other than the default one, by locale
+	Translations map[string]ProductTranslation `json:"translations"`
+	// Pictures of the product, in the order they are shown in
+	Images []ProductImage `json:"images"`
+	// Changes of the price the Elasticsearch repository has yet to copy into the price history
+	pendingPriceChanges []PriceChange
+	// Version of the stored product it was read from, which PutProducts only replaces while it is current.
+	// Zero for products that haven't been stored yet.
+	version int64
+}
+
+// ProductUpdate holds the details of a product to change; nil fields are left as they are
+type ProductUpdate struct {
+	Name        *string
+	Description *string
+	Price       *float64
+}
+
+// PriceChange records who changed the price of a product, or of one of its variants, and when
+type PriceChange struct {
+	// Identifies the change, so that storing it again doesn't record it twice
+	ID        string
+	ProductID string
+	VariantID string
+	OldPrice  float64
+	NewPrice  float64
+	ChangedBy string
+	ChangedAt time.Time
+}
+
+// Categories form a tree; Path holds the IDs of all categories from the root down to and including this one
+type Category struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	ParentID string   `json:"parentId"`
+	Path     []string `json:"path"`
+}
+
+// ProductQuery describes a product search: the text to match, if any, what to narrow the matches down to,
+// how to sort them and which page of them to return. The query text is analyzed like the language of Locale,
+// and matched against the product content in that locale as well as in the default one.
+type ProductQuery struct {
+	Query  string
+	Locale string
+	Filter ProductFilter
+	Sort   ProductSort
+	Skip   uint64
+	Take   uint64
+	// Account to rank the results for by its purchases, if it opted in
+	AccountID string
+	// Set by the service from the merchandising rules that apply to the search, and the personalization
+	Merchandising Merchandising
+}
+
+// ProductFilter narrows down a product search. A category matches the products of all of its subcategories
+// too, and a product has to carry all of the tags.
+type ProductFilter struct {
+	CategoryID string
+	Tags       []string
+	MinPrice   *float64
+	MaxPrice   *float64
+	// Only products with stock left
+	InStock bool
+}
+
+type ProductSort string
+
+const (
+	// Best matches of the query first; without a query the order is unspecified
+	ProductSortRelevance ProductSort = "RELEVANCE"
+	ProductSortPriceAsc  ProductSort = "PRICE_ASC"
+	ProductSortPriceDesc ProductSort = "PRICE_DESC"
+	ProductSortNewest    ProductSort = "NEWEST"
+	ProductSortName      ProductSort = "NAME"
+	// Best rated first, the more ratings the better among equally rated products
+	ProductSortRating ProductSort = "RATING"
+)
+
+type ProductSearchResult struct {
+	Products []Product
+	// How many products match in total, over all pages
+	Total  uint64
+	Facets ProductFacets
+}
+
+// ProductFacets counts the products matching a search per category and per tag.
+// Products count towards their category and all of its ancestors.
+type ProductFacets struct {
+	Categories []FacetCount
+	Tags       []FacetCount
+}
+
+type FacetCount struct {
+	Value string
+	Count uint64
+}
+
+// ProductSuggestion is a product whose name completes what has been typed so far
+type ProductSuggestion struct {
+	ID    string
+	Name  string
+	Score float64
 }
 
 type catalogService struct {
 	repository Repository
+	// Where product images are stored
+	media           blob.Store
+	personalization Personalization
 }
 
-func NewService(r Repository) Service {
-	return &catalogService{r}
+func NewService(r Repository, media blob.Store, personalization Personalization) Service {
+	return &catalogService{r, media, personalization}
 }
 
-func (s *catalogService) PostProduct(ctx context.Context, name string, description string, price float64) (*Product, error) {
+func (s *catalogService) PostProduct(ctx context.Context, name string, description string, price float64, stock int64, categoryID string, tags []string) (*Product, error) {
+	if stock < 0 {
+		return nil, ErrInvalidStock
+	}
 	// Creates a product of Product struct to call the PutProduct from repository
 	product := &Product{
-		ID:          ksuid.New().String(),
-		Name:        name,
-		Description: description,
-		Price:       price,
+		ID:           ksuid.New().String(),
+		Name:         name,
+		Description:  description,
+		Price:        price,
+		Stock:        stock,
+		CreatedAt:    time.Now().UTC(),
+		Tags:         normalizeTags(tags),
+		Translations: map[string]ProductTranslation{},
+		Images:       []ProductImage{},
+	}
+	if err := s.setCategory(ctx, product, categoryID); err != nil {
+		return nil, err
 	}
 	if err := s.repository.PutProduct(ctx, *product); err != nil {
 		return nil, err
@@ -61,9 +236,256 @@ func (s *catalogService) GetProductsByIDs(ctx context.Context, ids []string, ski
 	return s.repository.ListProductsWithIDs(ctx, ids, skip, take)
 }
 
-func (s *catalogService) SearchProducts(ctx context.Context, query string, skip uint64, take uint64) ([]Product, error) {
+// Search the products matching the query text, or all of them if it is empty, narrowed down by the filter
+func (s *catalogService) SearchProducts(ctx context.Context, query ProductQuery) (*ProductSearchResult, error) {
+	if query.Take > 100 || (query.Skip == 0 && query.Take == 0) {
+		query.Take = 100
+	}
+	switch query.Sort {
+	case "":
+		query.Sort = ProductSortRelevance
+	case ProductSortRelevance, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortNewest, ProductSortName, ProductSortRating:
+	default:
+		return nil, ErrInvalidProductQuery
+	}
+	if query.Locale == "" {
+		query.Locale = DefaultLocale
+	}
+	if !IsLocale(query.Locale) {
+		return nil, ErrInvalidLocale
+	}
+	f := query.Filter
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return nil, ErrInvalidProductQuery
+	}
+	query.Filter.Tags = normalizeTags(f.Tags)
+	m, err := s.merchandising(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	// Like boosts of merchandising rules, personalization only reorders results sorted by relevance
+	if query.Sort == ProductSortRelevance {
+		m.Boosts = append(m.Boosts, s.personalBoosts(ctx, query.AccountID)...)
+	}
+	query.Merchandising = m
+	result, err := s.repository.SearchProducts(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s.logSearch(ctx, query, result.Total)
+	return result, nil
+}
+
+// Changes the details of a product, recording the change if its price changed
+func (s *catalogService) UpdateProduct(ctx context.Context, id string, update ProductUpdate, changedBy string) (*Product, error) {
+	for attempt := 1; ; attempt++ {
+		product, err := s.updateProduct(ctx, id, update, changedBy)
+		if !errors.Is(err, ErrProductChanged) || attempt == maxProductRetries {
+			return product, err
+		}
+	}
+}
+
+// The price change is stored along with the new price, or not at all if the price changed since the product was read
+func (s *catalogService) updateProduct(ctx context.Context, id string, update ProductUpdate, changedBy string) (*Product, error) {
+	product, err := s.repository.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	oldPrice := product.Price
+	if update.Name != nil {
+		product.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Description != nil {
+		product.Description = *update.Description
+	}
+	if update.Price != nil {
+		product.Price = *update.Price
+	}
+	if product.Name == "" || !validPrice(product.Price) {
+		return nil, ErrInvalidProduct
+	}
+
+	var change *PriceChange
+	if product.Price != oldPrice {
+		change = &PriceChange{
+			ID:        ksuid.New().String(),
+			ProductID: product.ID,
+			OldPrice:  oldPrice,
+			NewPrice:  product.Price,
+			ChangedBy: changedBy,
+			ChangedAt: time.Now().UTC(),
+		}
+	}
+	if err := s.repository.UpdateProductDetails(ctx, *product, change); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// Takes a product out of sale; it stays resolvable by ID for the orders that contain it
+func (s *catalogService) ArchiveProduct(ctx context.Context, id string) (*Product, error) {
+	product, err := s.repository.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product.Archived {
+		return product, nil
+	}
+	product.Archived = true
+	if err := s.repository.ArchiveProduct(ctx, id); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// Lists the price changes of a product, newest first
+func (s *catalogService) GetPriceHistory(ctx context.Context, productID string, skip uint64, take uint64) ([]PriceChange, error) {
 	if take > 100 || (skip == 0 && take == 0) {
 		take = 100
 	}
-	return s.repository.SearchProducts(ctx, query, skip, take)
+	return s.repository.ListPriceChanges(ctx, productID, skip, take)
+}
+
+// Adds delta (which may be negative) to the stock of a product, or of one of its variants, and returns the
+// updated product. Taking out more than is left fails with ErrOutOfStock and leaves the stock as it is.
+func (s *catalogService) AdjustStock(ctx context.Context, id string, variantID string, delta int64) (*Product, error) {
+	if variantID != "" {
+		product, err := s.repository.GetProductByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := product.Variant(variantID); !ok {
+			return nil, ErrVariantNotFound
+		}
+	}
+	if err := s.repository.AdjustStock(ctx, id, variantID, delta); err != nil {
+		return nil, err
+	}
+	return s.repository.GetProductByID(ctx, id)
+}
+
+// Replaces the rating of a product with what its reviews now add up to
+func (s *catalogService) UpdateRating(ctx context.Context, id string, averageRating float64, ratingCount uint64) (*Product, error) {
+	if (ratingCount == 0) != (averageRating == 0) || (ratingCount > 0 && (averageRating < 1 || averageRating > 5)) {
+		return nil, ErrInvalidRating
+	}
+	product, err := s.repository.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	product.AverageRating = averageRating
+	product.RatingCount = ratingCount
+	if err := s.repository.UpdateProductRating(ctx, *product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// Moves a product into a category, or out of any if categoryID is empty, and replaces its tags
+func (s *catalogService) CategorizeProduct(ctx context.Context, id string, categoryID string, tags []string) (*Product, error) {
+	product, err := s.repository.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setCategory(ctx, product, categoryID); err != nil {
+		return nil, err
+	}
+	product.Tags = normalizeTags(tags)
+	if err := s.repository.UpdateProductCategory(ctx, *product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// setCategory points the product at a category and the path leading to it
+func (s *catalogService) setCategory(ctx context.Context, product *Product, categoryID string) error {
+	product.CategoryID = ""
+	product.CategoryPath = []string{}
+	if categoryID == "" {
+		return nil
+	}
+	category, err := s.repository.GetCategoryByID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	product.CategoryID = category.ID
+	product.CategoryPath = category.Path
+	return nil
+}
+
+// Creates a category, at the root of the tree if parentID is empty
+func (s *catalogService) PostCategory(ctx context.Context, name string, parentID string) (*Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidCategory
+	}
+	category := &Category{
+		ID:       ksuid.New().String(),
+		Name:     name,
+		ParentID: parentID,
+	}
+	category.Path = []string{category.ID}
+	if parentID != "" {
+		parent, err := s.repository.GetCategoryByID(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		category.Path = append(append([]string{}, parent.Path...), category.ID)
+	}
+	if err := s.repository.PutCategory(ctx, *category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Lists all categories; clients build the tree from their parent IDs
+func (s *catalogService) GetCategories(ctx context.Context) ([]Category, error) {
+	return s.repository.ListCategories(ctx)
+}
+
+// Deletes a category, which has to be empty so that no product or subcategory is left pointing at it
+func (s *catalogService) DeleteCategory(ctx context.Context, id string) error {
+	if _, err := s.repository.GetCategoryByID(ctx, id); err != nil {
+		return err
+	}
+	inUse, err := s.repository.CategoryInUse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrCategoryInUse
+	}
+	return s.repository.DeleteCategory(ctx, id)
+}
+
+// Suggest products by the start of any word of their name, tolerating typos
+func (s *catalogService) SuggestProducts(ctx context.Context, prefix string, size uint64) ([]ProductSuggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return []ProductSuggestion{}, nil
+	}
+	if size > 20 || size == 0 {
+		size = 5
+	}
+	return s.repository.SuggestProducts(ctx, prefix, size)
+}
+
+func validPrice(price float64) bool {
+	return !math.IsNaN(price) && !math.IsInf(price, 0) && price >= 0
+}
+
+// Tags are free-form but matched exactly, so store them trimmed, lowercased and without duplicates
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		normalized = append(normalized, t)
+	}
+	return normalized
 }
@@ -0,0 +1,99 @@
+-- Schema of the Postgres catalog repository, used instead of Elasticsearch when CATALOG_BACKEND=postgres
+CREATE TABLE IF NOT EXISTS products (
+  id VARCHAR(64) PRIMARY KEY,
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  price DOUBLE PRECISION NOT NULL,
+  stock BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP WITH TIME ZONE,
+  category_id VARCHAR(64) NOT NULL DEFAULT '',
+  -- IDs of the category and its ancestors, so that a category also matches the products of its subcategories
+  category_path TEXT[] NOT NULL DEFAULT '{}',
+  tags TEXT[] NOT NULL DEFAULT '{}',
+  archived BOOLEAN NOT NULL DEFAULT FALSE,
+  variants JSONB NOT NULL DEFAULT '[]',
+  average_rating DOUBLE PRECISION NOT NULL DEFAULT 0,
+  rating_count BIGINT NOT NULL DEFAULT 0,
+  -- Name and description in other locales than the default one, as {"de": {"name": ..., "description": ...}}
+  translations JSONB NOT NULL DEFAULT '{}',
+  -- Images in the order they are shown in, with where their files are in the blob store
+  images JSONB NOT NULL DEFAULT '[]',
+  -- Full text of the name and description, kept up to date by the trigger below
+  search TSVECTOR
+);
+
+-- Columns added after the table was first created, for databases that already have it
+ALTER TABLE products
+  ADD COLUMN IF NOT EXISTS average_rating DOUBLE PRECISION NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS rating_count BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS translations JSONB NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS images JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS products_search_idx ON products USING GIN (search);
+CREATE INDEX IF NOT EXISTS products_category_path_idx ON products USING GIN (category_path);
+CREATE INDEX IF NOT EXISTS products_tags_idx ON products USING GIN (tags);
+CREATE INDEX IF NOT EXISTS products_variants_idx ON products USING GIN (variants jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS products_category_id_idx ON products (category_id);
+
+-- Matches in the name weigh more than those in the description
+CREATE OR REPLACE FUNCTION products_search_update() RETURNS trigger AS $$
+BEGIN
+  NEW.search := setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(NEW.description, '')), 'B');
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_search_update ON products;
+CREATE TRIGGER products_search_update BEFORE INSERT OR UPDATE OF name, description ON products
+  FOR EACH ROW EXECUTE PROCEDURE products_search_update();
+
+CREATE TABLE IF NOT EXISTS categories (
+  id VARCHAR(64) PRIMARY KEY,
+  name TEXT NOT NULL,
+  parent_id VARCHAR(64) NOT NULL DEFAULT '',
+  path TEXT[] NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories (parent_id);
+
+CREATE TABLE IF NOT EXISTS price_changes (
+  id BIGSERIAL PRIMARY KEY,
+  product_id VARCHAR(64) NOT NULL,
+  variant_id VARCHAR(64) NOT NULL DEFAULT '',
+  old_price DOUBLE PRECISION NOT NULL,
+  new_price DOUBLE PRECISION NOT NULL,
+  changed_by TEXT NOT NULL DEFAULT '',
+  changed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS price_changes_product_id_changed_at_idx ON price_changes (product_id, changed_at);
+
+-- Rules apply to the searches for a query, or to those in a category
+CREATE TABLE IF NOT EXISTS merchandising_rules (
+  id VARCHAR(64) PRIMARY KEY,
+  query TEXT NOT NULL DEFAULT '',
+  category_id VARCHAR(64) NOT NULL DEFAULT '',
+  pinned TEXT[] NOT NULL DEFAULT '{}',
+  boosts JSONB NOT NULL DEFAULT '[]',
+  buried TEXT[] NOT NULL DEFAULT '{}',
+  hidden TEXT[] NOT NULL DEFAULT '{}'
+);
+
+-- A single row with the synonyms and stopwords searches are expanded with
+CREATE TABLE IF NOT EXISTS search_vocabulary (
+  id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+  synonyms JSONB NOT NULL DEFAULT '[]',
+  stopwords TEXT[] NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE IF NOT EXISTS search_log (
+  id BIGSERIAL PRIMARY KEY,
+  query TEXT NOT NULL,
+  locale VARCHAR(16) NOT NULL,
+  category_id VARCHAR(64) NOT NULL DEFAULT '',
+  hits BIGINT NOT NULL,
+  searched_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS search_log_searched_at_idx ON search_log (searched_at);
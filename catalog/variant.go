@@ -0,0 +1,201 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrVariantNotFound = errors.New("variant not found")
+	ErrInvalidVariant  = errors.New("variant needs a SKU, distinct options and a non-negative price and stock")
+	ErrDuplicateSKU    = errors.New("SKU is already used by another variant")
+)
+
+// Variant is a version of a product that is sold on its own, e.g. a shirt in one size and colour
+type Variant struct {
+	ID      string          `json:"id"`
+	SKU     string          `json:"sku"`
+	Options []VariantOption `json:"options"`
+	// Overrides the price of the product if set
+	Price *float64 `json:"price"`
+	Stock int64    `json:"stock"`
+}
+
+type VariantOption struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Variant returns the variant of the product with the given ID
+func (p Product) Variant(id string) (Variant, bool) {
+	for _, v := range p.Variants {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// VariantPrice is what a variant of the product sells for
+func (p Product) VariantPrice(v Variant) float64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return p.Price
+}
+
+// The stock of a product with variants is the sum of theirs
+func variantStock(variants []Variant) int64 {
+	stock := int64(0)
+	for _, v := range variants {
+		stock += v.Stock
+	}
+	return stock
+}
+
+// Adds a variant to a product, or replaces the SKU, options and price of an existing one if the ID is set.
+// The stock of existing variants is only changed through AdjustStock. Changes of the variant's price are
+// recorded like those of the product's.
+func (s *catalogService) PutVariant(ctx context.Context, productID string, variant Variant, changedBy string) (*Product, error) {
+	variant.SKU = strings.TrimSpace(variant.SKU)
+	if variant.SKU == "" || len(variant.Options) == 0 || variant.Stock < 0 {
+		return nil, ErrInvalidVariant
+	}
+	if variant.Price != nil && !validPrice(*variant.Price) {
+		return nil, ErrInvalidVariant
+	}
+	names := map[string]bool{}
+	for i, o := range variant.Options {
+		o.Name, o.Value = strings.TrimSpace(o.Name), strings.TrimSpace(o.Value)
+		if o.Name == "" || o.Value == "" || names[strings.ToLower(o.Name)] {
+			return nil, ErrInvalidVariant
+		}
+		names[strings.ToLower(o.Name)] = true
+		variant.Options[i] = o
+	}
+
+	for attempt := 1; ; attempt++ {
+		product, err := s.putVariant(ctx, productID, variant, changedBy)
+		if !errors.Is(err, ErrProductChanged) || attempt == maxProductRetries {
+			return product, err
+		}
+	}
+}
+
+// The repository adds or changes just the one variant, so that concurrent changes of the product's other
+// variants or stock are kept
+func (s *catalogService) putVariant(ctx context.Context, productID string, variant Variant, changedBy string) (*Product, error) {
+	product, err := s.repository.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// SKUs identify a variant across the whole catalog
+	owners, err := s.repository.ListProductsWithSKU(ctx, variant.SKU)
+	if err != nil {
+		return nil, err
+	}
+	for _, owner := range owners {
+		for _, v := range owner.Variants {
+			if v.SKU == variant.SKU && v.ID != variant.ID {
+				return nil, ErrDuplicateSKU
+			}
+		}
+	}
+
+	var change *PriceChange
+	if variant.ID != "" {
+		old, ok := product.Variant(variant.ID)
+		if !ok {
+			return nil, ErrVariantNotFound
+		}
+		if oldPrice, newPrice := product.VariantPrice(old), product.VariantPrice(variant); oldPrice != newPrice {
+			change = &PriceChange{
+				ID:        ksuid.New().String(),
+				ProductID: product.ID,
+				VariantID: variant.ID,
+				OldPrice:  oldPrice,
+				NewPrice:  newPrice,
+				ChangedBy: changedBy,
+				ChangedAt: time.Now().UTC(),
+			}
+		}
+	}
+	for _, v := range product.Variants {
+		if v.ID != variant.ID && sameOptions(v.Options, variant.Options) {
+			return nil, ErrInvalidVariant
+		}
+	}
+
+	if variant.ID == "" {
+		variant.ID = ksuid.New().String()
+		return s.repository.AddVariant(ctx, productID, variant)
+	}
+	return s.repository.UpdateVariant(ctx, productID, variant, change)
+}
+
+// Removes a variant from a product; orders keep what they recorded of it
+func (s *catalogService) DeleteVariant(ctx context.Context, productID string, variantID string) (*Product, error) {
+	return s.repository.RemoveVariant(ctx, productID, variantID)
+}
+
+// addVariant, updateVariant and removeVariant change the variants of a product in place, for the repositories
+// that can lock the product while they do; the product's stock stays the sum of the variants'
+func addVariant(p *Product, v Variant) {
+	p.Variants = append(p.Variants, v)
+	p.Stock = variantStock(p.Variants)
+}
+
+// The variant keeps its stock, and a price change only applies while the variant still has the old price
+func updateVariant(p *Product, v Variant, change *PriceChange) error {
+	i := variantIndex(p.Variants, v.ID)
+	if i < 0 {
+		return ErrVariantNotFound
+	}
+	if change != nil && p.VariantPrice(p.Variants[i]) != change.OldPrice {
+		return ErrProductChanged
+	}
+	v.Stock = p.Variants[i].Stock
+	p.Variants[i] = v
+	return nil
+}
+
+func removeVariant(p *Product, id string) error {
+	i := variantIndex(p.Variants, id)
+	if i < 0 {
+		return ErrVariantNotFound
+	}
+	p.Variants = append(p.Variants[:i], p.Variants[i+1:]...)
+	p.Stock = variantStock(p.Variants)
+	return nil
+}
+
+func variantIndex(variants []Variant, id string) int {
+	for i, v := range variants {
+		if v.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Whether two variants have the same option values, regardless of the order and case of the options
+func sameOptions(a []VariantOption, b []VariantOption) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	values := map[string]string{}
+	for _, o := range a {
+		values[strings.ToLower(o.Name)] = strings.ToLower(o.Value)
+	}
+	for _, o := range b {
+		if v, ok := values[strings.ToLower(o.Name)]; !ok || v != strings.ToLower(o.Value) {
+			return false
+		}
+	}
+	return true
+}
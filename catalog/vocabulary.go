@@ -0,0 +1,124 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidVocabulary = errors.New("synonym groups need at least two different terms without stopwords, stopwords " +
+	"must be single words, and both may only have letters, digits, spaces, hyphens and apostrophes")
+
+var ErrVocabularyPending = errors.New("the catalog is still being reindexed for the previous search vocabulary")
+
+const (
+	maxSynonymGroups = 1000
+	maxStopwords     = 500
+)
+
+// SearchVocabulary holds the words searches treat specially. Synonyms let e.g. a search for "sneakers" find
+// products described as "trainers", and stopwords are left out of search queries, so that words like "for"
+// don't keep products from being found.
+type SearchVocabulary struct {
+	// Each group lists terms that mean the same; terms may have several words
+	Synonyms  [][]string `json:"synonyms"`
+	Stopwords []string   `json:"stopwords"`
+}
+
+func (s *catalogService) GetSearchVocabulary(ctx context.Context) (*SearchVocabulary, error) {
+	return s.repository.GetSearchVocabulary(ctx)
+}
+
+// Replaces the vocabulary, which applies to the searches made once the catalog has been reindexed for it.
+// Only Elasticsearch needs to reindex, which it does in the background.
+func (s *catalogService) PutSearchVocabulary(ctx context.Context, v SearchVocabulary) (*SearchVocabulary, error) {
+	vocabulary := SearchVocabulary{Synonyms: [][]string{}, Stopwords: []string{}}
+	for _, word := range v.Stopwords {
+		word = normalizeQuery(word)
+		if word == "" || strings.Contains(word, " ") || !isPlainTerm(word) {
+			return nil, ErrInvalidVocabulary
+		}
+		if !slices.Contains(vocabulary.Stopwords, word) {
+			vocabulary.Stopwords = append(vocabulary.Stopwords, word)
+		}
+	}
+	for _, group := range v.Synonyms {
+		terms := []string{}
+		for _, term := range group {
+			term = normalizeQuery(term)
+			if term == "" || !isPlainTerm(term) {
+				return nil, ErrInvalidVocabulary
+			}
+			// A stopword would be gone from the query before the synonym could match it
+			if slices.ContainsFunc(strings.Fields(term), func(w string) bool { return slices.Contains(vocabulary.Stopwords, w) }) {
+				return nil, ErrInvalidVocabulary
+			}
+			if !slices.Contains(terms, term) {
+				terms = append(terms, term)
+			}
+		}
+		if len(terms) < 2 {
+			return nil, ErrInvalidVocabulary
+		}
+		vocabulary.Synonyms = append(vocabulary.Synonyms, terms)
+	}
+	if len(vocabulary.Synonyms) > maxSynonymGroups || len(vocabulary.Stopwords) > maxStopwords {
+		return nil, ErrInvalidVocabulary
+	}
+
+	if err := s.repository.PutSearchVocabulary(ctx, vocabulary); err != nil {
+		return nil, err
+	}
+	return &vocabulary, nil
+}
+
+// Terms can't have the characters Elasticsearch reads synonym rules with, like commas and =>
+func isPlainTerm(term string) bool {
+	for _, c := range term {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != ' ' && c != '-' && c != '\'' {
+			return false
+		}
+	}
+	return true
+}
+
+// expand splits a query into the words it has to match, leaving out the stopwords. Each is given with the
+// terms it means the same as, so that a product matches if it has any of them. Synonyms of several words are
+// matched as a whole, the longest first. Repositories that can't analyze queries with the vocabulary themselves
+// search with this.
+func (v SearchVocabulary) expand(query string) [][]string {
+	words := strings.Fields(normalizeQuery(query))
+	clauses := [][]string{}
+	for i := 0; i < len(words); {
+		if slices.Contains(v.Stopwords, words[i]) {
+			i++
+			continue
+		}
+		var clause []string
+		n := 1
+		for _, group := range v.Synonyms {
+			for _, term := range group {
+				length := len(strings.Fields(term))
+				if length < n || i+length > len(words) || strings.Join(words[i:i+length], " ") != term {
+					continue
+				}
+				if length > n {
+					clause, n = nil, length
+				}
+				for _, t := range group {
+					if !slices.Contains(clause, t) {
+						clause = append(clause, t)
+					}
+				}
+			}
+		}
+		if clause == nil {
+			clause = []string{words[i]}
+		}
+		clauses = append(clauses, clause)
+		i += n
+	}
+	return clauses
+}
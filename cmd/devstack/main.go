@@ -0,0 +1,130 @@
+// Command devstack runs the whole API in a single process, with the account, catalog, order and review
+// services keeping their data in memory, so that it can be started with go run and nothing else. All data
+// is lost when it stops, apart from product images, which are kept in a temporary directory.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/99designs/gqlgen/handler"
+	"github.com/PranavTrip/go-grpc-graphql-ms/account"
+	"github.com/PranavTrip/go-grpc-graphql-ms/blob"
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"github.com/PranavTrip/go-grpc-graphql-ms/graphql"
+	"github.com/PranavTrip/go-grpc-graphql-ms/order"
+	"github.com/PranavTrip/go-grpc-graphql-ms/review"
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// Ports the GraphQL gateway and the gRPC services listen on
+	GraphQLPort int `envconfig:"GRAPHQL_PORT" default:"8000"`
+	AccountPort int `envconfig:"ACCOUNT_PORT" default:"8081"`
+	CatalogPort int `envconfig:"CATALOG_PORT" default:"8082"`
+	OrderPort   int `envconfig:"ORDER_PORT" default:"8083"`
+	ReviewPort  int `envconfig:"REVIEW_PORT" default:"8084"`
+	// Limits every order is validated against
+	MaxProductQuantity uint32  `envconfig:"MAX_PRODUCT_QUANTITY" default:"100"`
+	MaxOrderQuantity   uint32  `envconfig:"MAX_ORDER_QUANTITY" default:"1000"`
+	MinOrderValue      float64 `envconfig:"MIN_ORDER_VALUE" default:"0"`
+	// Key the order links of guests are signed with
+	GuestTokenSecret string `envconfig:"GUEST_TOKEN_SECRET" default:"devstack"`
+	// Key email verification tokens are signed with, which are logged rather than emailed
+	EmailTokenSecret string `envconfig:"EMAIL_TOKEN_SECRET" default:"devstack"`
+	// Directory product images are kept in, a new temporary one if not set
+	MediaDir string `envconfig:"MEDIA_DIR"`
+	// How much searches of accounts that opt in favour the categories and tags they bought from
+	PersonalizationCategoryBoost float64 `envconfig:"PERSONALIZATION_CATEGORY_BOOST" default:"2"`
+	PersonalizationTagBoost      float64 `envconfig:"PERSONALIZATION_TAG_BOOST" default:"1.5"`
+}
+
+func main() {
+	var cfg Config
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	accountURL := fmt.Sprintf("localhost:%d", cfg.AccountPort)
+	catalogURL := fmt.Sprintf("localhost:%d", cfg.CatalogPort)
+	orderURL := fmt.Sprintf("localhost:%d", cfg.OrderPort)
+	reviewURL := fmt.Sprintf("localhost:%d", cfg.ReviewPort)
+	personalization := catalog.Personalization{
+		CategoryBoost: cfg.PersonalizationCategoryBoost,
+		TagBoost:      cfg.PersonalizationTagBoost,
+	}
+	if err := personalization.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Images are served by the gateway, next to the API
+	if cfg.MediaDir == "" {
+		if cfg.MediaDir, err = os.MkdirTemp("", "devstack-media"); err != nil {
+			log.Fatal(err)
+		}
+	}
+	media, err := blob.NewFileStore(cfg.MediaDir, fmt.Sprintf("http://localhost:%d/media", cfg.GraphQLPort))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The services dial each other lazily, so they can be started in any order. The first one to stop
+	// stops the whole stack.
+	errs := make(chan error)
+	go func() {
+		verification := account.EmailVerification{Secret: []byte(cfg.EmailTokenSecret), Mailer: logMailer{}}
+		s := account.NewService(account.NewMemoryRepository(), verification)
+		errs <- fmt.Errorf("account service: %w", account.ListenGRPC(s, cfg.AccountPort))
+	}()
+	go func() {
+		orderClient, err := order.NewClient(orderURL)
+		if err != nil {
+			errs <- fmt.Errorf("catalog service: %w", err)
+			return
+		}
+		defer orderClient.Close()
+		personalization.History = orderClient
+		s := catalog.NewService(catalog.NewMemoryRepository(), media, personalization)
+		errs <- fmt.Errorf("catalog service: %w", catalog.ListenGRPC(s, cfg.CatalogPort))
+	}()
+	go func() {
+		limits := order.OrderLimits{
+			MaxProductQuantity: cfg.MaxProductQuantity,
+			MaxOrderQuantity:   cfg.MaxOrderQuantity,
+			MinOrderValue:      cfg.MinOrderValue,
+		}
+		s := order.NewService(order.NewMemoryRepository())
+		errs <- fmt.Errorf("order service: %w", order.ListenGRPC(s, accountURL, catalogURL, limits, cfg.GuestTokenSecret, cfg.OrderPort))
+	}()
+
+	go func() {
+		s := review.NewService(review.NewMemoryRepository())
+		errs <- fmt.Errorf("review service: %w", review.ListenGRPC(s, orderURL, catalogURL, cfg.ReviewPort))
+	}()
+
+	s, err := graphql.NewGraphQLServer(accountURL, catalogURL, orderURL, reviewURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/graphql", graphql.WithLocale(handler.GraphQL(s.ToExecutableSchema())))
+	mux.Handle("/playground", handler.Playground("pranav", "/graphql"))
+	mux.Handle("/media/", http.StripPrefix("/media", blob.Handler(media)))
+	go func() {
+		errs <- fmt.Errorf("graphql gateway: %w", http.ListenAndServe(fmt.Sprintf(":%d", cfg.GraphQLPort), mux))
+	}()
+
+	log.Printf("Playground at http://localhost:%d/playground", cfg.GraphQLPort)
+	log.Fatal(<-errs)
+}
+
+// logMailer logs emails instead of sending them, so that the devstack doesn't need a mail server
+type logMailer struct{}
+
+func (logMailer) SendEmailVerification(ctx context.Context, a account.Account, token string) error {
+	log.Printf("Email verification token for %s: %s", a.Email, token)
+	return nil
+}
@@ -0,0 +1,872 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/blob"
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"github.com/PranavTrip/go-grpc-graphql-ms/order"
+)
+
+func TestPlaceOrder(t *testing.T) {
+	stack := New(t)
+
+	var account struct {
+		CreateAccount struct{ ID string }
+	}
+	stack.MustQuery(`mutation { createAccount(account: {name: "Jane"}) { id } }`, nil, &account)
+	var product struct {
+		CreateProduct struct{ ID string }
+	}
+	stack.MustQuery(`
+		mutation {
+			createProduct(product: {name: "Trail Shoe", description: "Grippy sole", price: 80, stock: 5}) { id }
+		}
+		`, nil, &product,
+	)
+	accountID, productID := account.CreateAccount.ID, product.CreateProduct.ID
+
+	// The product is created with its stock
+	var inStock struct {
+		Products struct{ Products []struct{ ID string } }
+	}
+	stack.MustQuery(`query { products(filter: {inStock: true}) { products { id } } }`, nil, &inStock)
+	if ps := inStock.Products.Products; len(ps) != 1 || ps[0].ID != productID {
+		t.Errorf("got products in stock %+v, want the new one", ps)
+	}
+
+	var order struct {
+		CreateOrder struct {
+			ID         string
+			TotalPrice float64
+		}
+	}
+	stack.MustQuery(`
+		mutation($accountId: String!, $productId: String!) {
+			createOrder(order: {accountId: $accountId, products: [{id: $productId, quantity: 2}]}) { id totalPrice }
+		}
+		`, map[string]interface{}{"accountId": accountID, "productId": productID}, &order,
+	)
+	if order.CreateOrder.TotalPrice != 160 {
+		t.Errorf("got total price %v, want 160", order.CreateOrder.TotalPrice)
+	}
+
+	// The order is listed with the account, its products filled in from the catalog
+	var data struct {
+		Accounts []struct {
+			Name   string
+			Orders struct {
+				Orders []struct {
+					ID       string
+					Status   string
+					Products []struct {
+						ID       string
+						Name     string
+						Price    float64
+						Quantity int
+					}
+				}
+			}
+		}
+	}
+	stack.MustQuery(`
+		query($id: String!) {
+			accounts(id: $id) { name orders { orders { id status products { id name price quantity } } } }
+		}
+		`, map[string]interface{}{"id": accountID}, &data,
+	)
+	if len(data.Accounts) != 1 || len(data.Accounts[0].Orders.Orders) != 1 {
+		t.Fatalf("got accounts %+v, want one with one order", data.Accounts)
+	}
+	o := data.Accounts[0].Orders.Orders[0]
+	if o.ID != order.CreateOrder.ID || o.Status != "PENDING" {
+		t.Errorf("got order %s with status %s, want %s pending", o.ID, o.Status, order.CreateOrder.ID)
+	}
+	if len(o.Products) != 1 {
+		t.Fatalf("got products %+v, want one", o.Products)
+	}
+	if p := o.Products[0]; p.ID != productID || p.Name != "Trail Shoe" || p.Price != 80 || p.Quantity != 2 {
+		t.Errorf("got product %+v", p)
+	}
+}
+
+func TestAccountOrderPages(t *testing.T) {
+	stack := New(t)
+	accountID := stack.CreateAccount("Jane")
+	productID := stack.CreateProduct(Product{Name: "Trail Shoe", Price: 80, Stock: 5})
+	first := stack.CreateOrder(accountID, OrderLine{ProductID: productID, Quantity: 1})
+	second := stack.CreateOrder(accountID, OrderLine{ProductID: productID, Quantity: 2})
+
+	query := `
+		query($id: String!, $after: String, $take: Int) {
+			accounts(id: $id) { orders(pagination: {after: $after, take: $take}) { orders { id } nextCursor } }
+		}
+		`
+	page := func(after *string) ([]string, *string) {
+		var data struct {
+			Accounts []struct {
+				Orders struct {
+					Orders     []struct{ ID string }
+					NextCursor *string
+				}
+			}
+		}
+		stack.MustQuery(query, map[string]interface{}{"id": accountID, "after": after, "take": 1}, &data)
+		ids := []string{}
+		for _, o := range data.Accounts[0].Orders.Orders {
+			ids = append(ids, o.ID)
+		}
+		return ids, data.Accounts[0].Orders.NextCursor
+	}
+
+	// Newest first, with a cursor until the last page
+	ids, next := page(nil)
+	if len(ids) != 1 || ids[0] != second || next == nil {
+		t.Fatalf("got %v with cursor %v, want [%s] and a cursor", ids, next, second)
+	}
+	ids, next = page(next)
+	if len(ids) != 1 || ids[0] != first || next != nil {
+		t.Fatalf("got %v with cursor %v, want [%s] and no cursor", ids, next, first)
+	}
+
+	if res := stack.Query(query, map[string]interface{}{"id": accountID, "take": -1}); len(res.Errors) == 0 {
+		t.Errorf("got %s taking -1 orders, want an error", res.Data)
+	}
+}
+
+func TestPlaceInvalidOrder(t *testing.T) {
+	stack := New(t)
+	accountID := stack.CreateAccount("Jane")
+	productID := stack.CreateProduct(Product{Name: "Trail Shoe", Price: 80})
+
+	res := stack.Query(`
+		mutation($accountId: String!, $productId: String!) {
+			createOrder(order: {accountId: $accountId, products: [{id: $productId, quantity: 101}, {id: "missing", quantity: 1}]}) { id }
+		}
+		`, map[string]interface{}{"accountId": accountID, "productId": productID},
+	)
+
+	// Every problem is reported against the field it is about
+	fields := map[string]bool{}
+	for _, err := range res.Errors {
+		if err.Extensions["code"] != "INVALID_ARGUMENT" {
+			t.Errorf("got error %+v, want an invalid argument", err)
+		}
+		field, _ := err.Extensions["field"].(string)
+		fields[field] = true
+	}
+	for _, field := range []string{"order.products[0].quantity", "order.products[1]"} {
+		if !fields[field] {
+			t.Errorf("got errors %+v, want one for %s", res.Errors, field)
+		}
+	}
+
+	// Nothing is ordered
+	orders, _, err := stack.Orders.GetOrderForAccount(context.Background(), accountID, order.OrderFilter{}, "", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 0 {
+		t.Errorf("got %d orders, want none", len(orders))
+	}
+}
+
+func TestOrderOutOfStock(t *testing.T) {
+	stack := New(t)
+	ctx := context.Background()
+	accountID := stack.CreateAccount("Jane")
+	productID := stack.CreateProduct(Product{Name: "Trail Shoe", Price: 80, Stock: 2})
+	hatID := stack.CreateProduct(Product{Name: "Wool Hat", Price: 20, Stock: 5})
+
+	res := stack.Query(`
+		mutation($accountId: String!, $hatId: String!, $productId: String!) {
+			createOrder(order: {accountId: $accountId, products: [{id: $hatId, quantity: 1}, {id: $productId, quantity: 3}]}) { id }
+		}
+		`, map[string]interface{}{"accountId": accountID, "hatId": hatID, "productId": productID},
+	)
+	if len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "FailedPrecondition") || !strings.Contains(res.Errors[0].Message, productID) {
+		t.Fatalf("got errors %+v, want the shoe to be out of stock", res.Errors)
+	}
+
+	// Nothing is ordered, and the stock taken for the lines before is put back
+	orders, _, err := stack.Orders.GetOrderForAccount(ctx, accountID, order.OrderFilter{}, "", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 0 {
+		t.Errorf("got %d orders, want none", len(orders))
+	}
+	for id, want := range map[string]int64{productID: 2, hatID: 5} {
+		p, err := stack.Catalog.GetProduct(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Stock != want {
+			t.Errorf("got stock %d of %s, want %d", p.Stock, p.Name, want)
+		}
+	}
+
+	// What is left can still be ordered
+	stack.CreateOrder(accountID, OrderLine{ProductID: productID, Quantity: 2})
+}
+
+func TestReorderOrder(t *testing.T) {
+	stack := New(t)
+	ctx := context.Background()
+	accountID := stack.CreateAccount("Jane")
+	shoeID := stack.CreateProduct(Product{Name: "Trail Shoe", Price: 80, Stock: 10})
+	hatID := stack.CreateProduct(Product{Name: "Wool Hat", Price: 20, Stock: 10})
+	socksID := stack.CreateProduct(Product{Name: "Running Socks", Price: 5, Stock: 3})
+	bootID := stack.CreateProduct(Product{Name: "Hiking Boot", Price: 120})
+	variantIDs := map[string]string{}
+	for _, size := range []string{"small", "large"} {
+		variant := catalog.Variant{SKU: "boot-" + size, Options: []catalog.VariantOption{{Name: "Size", Value: size}}, Stock: 5}
+		p, err := stack.Catalog.PutVariant(ctx, bootID, variant, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range p.Variants {
+			variantIDs[v.SKU] = v.ID
+		}
+	}
+
+	// The original order is stored directly, as it also has a product that has since left the catalog
+	original, err := stack.Orders.PostOrder(ctx, accountID, nil, []order.OrderedProduct{
+		{ID: shoeID, Price: 70, Quantity: 2},
+		{ID: hatID, Price: 20, Quantity: 1},
+		{ID: socksID, Price: 5, Quantity: 4},
+		{ID: bootID, VariantID: variantIDs["boot-small"], Price: 120, Quantity: 1},
+		{ID: bootID, VariantID: variantIDs["boot-large"], Price: 120, Quantity: 1},
+		{ID: "deleted", Price: 10, Quantity: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	price := 90.0
+	if _, err := stack.Catalog.UpdateProduct(ctx, shoeID, catalog.ProductUpdate{Price: &price}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stack.Catalog.ArchiveProduct(ctx, hatID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stack.Catalog.DeleteVariant(ctx, bootID, variantIDs["boot-large"]); err != nil {
+		t.Fatal(err)
+	}
+
+	var data struct {
+		Reorder struct {
+			Order struct {
+				TotalPrice float64
+				Products   []struct {
+					ID        string
+					VariantID *string
+					Price     float64
+					Quantity  int
+				}
+			}
+			SkippedLines []struct {
+				ProductID string
+				VariantID *string
+				Quantity  int
+				Reason    string
+			}
+		}
+	}
+	stack.MustQuery(`
+		mutation($orderId: String!) {
+			reorder(orderId: $orderId) {
+				order { totalPrice products { id variantId price quantity } }
+				skippedLines { productId variantId quantity reason }
+			}
+		}
+		`, map[string]interface{}{"orderId": original.ID}, &data,
+	)
+
+	// The lines still available are ordered again at the current catalog prices
+	got := map[string]float64{}
+	for _, p := range data.Reorder.Order.Products {
+		got[p.ID] = p.Price * float64(p.Quantity)
+	}
+	if want := map[string]float64{shoeID: 2 * 90, bootID: 120}; !reflect.DeepEqual(got, want) || data.Reorder.Order.TotalPrice != 300 {
+		t.Errorf("got lines %v totalling %v, want %v totalling 300", got, data.Reorder.Order.TotalPrice, want)
+	}
+
+	reasons := map[string]string{}
+	for _, l := range data.Reorder.SkippedLines {
+		key := l.ProductID
+		if l.VariantID != nil {
+			key += "/" + *l.VariantID
+		}
+		reasons[key] = l.Reason
+	}
+	want := map[string]string{
+		hatID:                                   "product is no longer available",
+		"deleted":                               "product is no longer available",
+		bootID + "/" + variantIDs["boot-large"]: "variant is no longer available",
+		socksID:                                 "not enough stock left",
+	}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("got skipped lines %v, want %v", reasons, want)
+	}
+
+	// Only the reordered lines were taken out of stock
+	p, err := stack.Catalog.GetProduct(ctx, shoeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Stock != 8 {
+		t.Errorf("got %d shoes left, want 8", p.Stock)
+	}
+}
+
+func TestSearchProducts(t *testing.T) {
+	stack := New(t)
+	shoes := stack.CreateCategory("Shoes", "")
+	trail := stack.CreateProduct(Product{Name: "Trail Shoe", Price: 80, Stock: 3, CategoryID: shoes, Tags: []string{"outdoor"}})
+	stack.CreateProduct(Product{Name: "Canvas Sneaker", Price: 40, CategoryID: shoes})
+	stack.CreateProduct(Product{Name: "Wool Hat", Price: 20, Stock: 5})
+
+	var data struct {
+		Products struct {
+			TotalCount int
+			Products   []struct {
+				ID    string
+				Stock int
+			}
+		}
+	}
+	stack.MustQuery(`
+		query($categoryId: String!) {
+			products(filter: {categoryId: $categoryId, inStock: true}) { totalCount products { id stock } }
+		}
+		`, map[string]interface{}{"categoryId": shoes}, &data,
+	)
+	if data.Products.TotalCount != 1 || len(data.Products.Products) != 1 {
+		t.Fatalf("got %+v, want only the trail shoe", data.Products)
+	}
+	if p := data.Products.Products[0]; p.ID != trail || p.Stock != 3 {
+		t.Errorf("got product %+v, want %s with stock 3", p, trail)
+	}
+}
+
+func TestMerchandisedSearch(t *testing.T) {
+	stack := New(t)
+	trail := stack.CreateProduct(Product{Name: "Trail Shoe", Price: 80})
+	sneaker := stack.CreateProduct(Product{Name: "Canvas Sneaker", Description: "A casual shoe", Price: 40})
+	sandal := stack.CreateProduct(Product{Name: "Beach Shoe", Price: 20})
+	boot := stack.CreateProduct(Product{Name: "Winter Boot", Price: 120})
+	_, err := stack.Catalog.PutMerchandisingRule(context.Background(), catalog.MerchandisingRule{
+		Query:  "  Shoe ",
+		Pinned: []string{boot},
+		Buried: []string{trail},
+		Hidden: []string{sandal},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data struct {
+		Products struct {
+			TotalCount int
+			Products   []struct{ ID string }
+		}
+	}
+	search := `query($query: String!) { products(query: $query) { totalCount products { id } } }`
+	stack.MustQuery(search, map[string]interface{}{"query": "shoe"}, &data)
+	got := []string{}
+	for _, p := range data.Products.Products {
+		got = append(got, p.ID)
+	}
+	if want := []string{boot, sneaker, trail}; !reflect.DeepEqual(got, want) || data.Products.TotalCount != 3 {
+		t.Errorf("got %v of %d products, want %v", got, data.Products.TotalCount, want)
+	}
+
+	// Searches for other queries are left alone
+	stack.MustQuery(search, map[string]interface{}{"query": "beach"}, &data)
+	if len(data.Products.Products) != 1 || data.Products.Products[0].ID != sandal {
+		t.Errorf("got %+v, want only the beach shoe", data.Products.Products)
+	}
+}
+
+func TestSearchVocabularyAndReport(t *testing.T) {
+	stack := New(t)
+	sneaker := stack.CreateProduct(Product{Name: "Canvas Trainers", Price: 40})
+	stack.CreateProduct(Product{Name: "Wool Hat", Price: 20})
+	ctx := context.Background()
+	_, err := stack.Catalog.PutSearchVocabulary(ctx, catalog.SearchVocabulary{
+		Synonyms:  [][]string{{"Sneakers", "trainers"}},
+		Stopwords: []string{"cheap"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stack.Catalog.PutSearchVocabulary(ctx, catalog.SearchVocabulary{Synonyms: [][]string{{"sneakers, trainers"}}})
+	if !errors.Is(err, catalog.ErrInvalidVocabulary) {
+		t.Errorf("got error %v, want %v for terms joined by a comma", err, catalog.ErrInvalidVocabulary)
+	}
+
+	var data struct {
+		Products struct {
+			Products []struct{ ID string }
+		}
+	}
+	search := `query($query: String!) { products(query: $query) { products { id } } }`
+	for _, query := range []string{"cheap sneakers", "Cheap  Sneakers", "umbrella"} {
+		stack.MustQuery(search, map[string]interface{}{"query": query}, &data)
+	}
+	if len(data.Products.Products) != 0 {
+		t.Errorf("got %+v, want no umbrellas", data.Products.Products)
+	}
+	stack.MustQuery(search, map[string]interface{}{"query": "sneakers"}, &data)
+	if len(data.Products.Products) != 1 || data.Products.Products[0].ID != sneaker {
+		t.Errorf("got %+v, want the trainers", data.Products.Products)
+	}
+
+	// Searches are recorded in the background, so wait for the last of them
+	var report *catalog.SearchReport
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if report, err = stack.Catalog.GetSearchReport(ctx, time.Time{}, 0); err != nil {
+			t.Fatal(err)
+		}
+		if report.Searches >= 4 || time.Now().After(deadline) {
+			break
+		}
+	}
+	wantTop := []catalog.QueryStats{{Query: "cheap sneakers", Searches: 2}, {Query: "sneakers", Searches: 1}, {Query: "umbrella", Searches: 1}}
+	if report.Searches != 4 || !reflect.DeepEqual(report.TopQueries, wantTop) {
+		t.Errorf("got %d searches for %+v, want 4 for %+v", report.Searches, report.TopQueries, wantTop)
+	}
+	if wantZero := []catalog.QueryStats{{Query: "umbrella", Searches: 1}}; !reflect.DeepEqual(report.ZeroResultQueries, wantZero) {
+		t.Errorf("got zero result queries %+v, want %+v", report.ZeroResultQueries, wantZero)
+	}
+}
+
+func TestPersonalizedSearch(t *testing.T) {
+	stack := New(t)
+	running := stack.CreateCategory("Running", "")
+	sandals := stack.CreateCategory("Sandals", "")
+	trail := stack.CreateProduct(Product{Name: "Trail Shoe", Price: 80, Stock: 5, CategoryID: running})
+	beach := stack.CreateProduct(Product{Name: "Beach Shoe", Price: 20, Stock: 5, CategoryID: sandals})
+	flipFlop := stack.CreateProduct(Product{Name: "Flip Flop", Price: 10, Stock: 5, CategoryID: sandals})
+	socks := stack.CreateProduct(Product{Name: "Running Socks", Price: 5, Stock: 5, CategoryID: running})
+	runner := stack.CreateAccount("Runner")
+	swimmer := stack.CreateAccount("Swimmer")
+	stack.CreateOrder(runner, OrderLine{ProductID: socks, Quantity: 1})
+	stack.CreateOrder(swimmer, OrderLine{ProductID: flipFlop, Quantity: 1})
+
+	search := func(vars map[string]interface{}) []string {
+		var data struct {
+			Products struct {
+				Products []struct{ ID string }
+			}
+		}
+		stack.MustQuery(`
+			query($accountId: String, $sort: ProductSort) {
+				products(query: "shoe", accountId: $accountId, sort: $sort) { products { id } }
+			}
+			`, vars, &data,
+		)
+		ids := []string{}
+		for _, p := range data.Products.Products {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+	if got, want := search(map[string]interface{}{"accountId": runner}), []string{trail, beach}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v for the runner, want the trail shoe first", got)
+	}
+	if got, want := search(map[string]interface{}{"accountId": swimmer}), []string{beach, trail}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v for the swimmer, want the beach shoe first", got)
+	}
+	// Other sorts aren't personalized
+	if got, want := search(map[string]interface{}{"accountId": swimmer, "sort": "PRICE_DESC"}), []string{trail, beach}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v sorted by price, want the trail shoe first", got)
+	}
+}
+
+func TestDeliverOrder(t *testing.T) {
+	stack := New(t)
+	accountID := stack.CreateAccount("Jane")
+	productID := stack.CreateProduct(Product{Name: "Trail Shoe", Price: 80, Stock: 5})
+	orderID := stack.CreateOrder(accountID, OrderLine{ProductID: productID, Quantity: 1})
+	stack.DeliverOrder(orderID)
+
+	var data struct {
+		Accounts []struct {
+			Orders struct {
+				Orders []struct {
+					Status    string
+					Shipments []struct{ DeliveredAt *string }
+				}
+			}
+		}
+	}
+	stack.MustQuery(`
+		query($id: String!) {
+			accounts(id: $id) { orders { orders { status shipments { deliveredAt } } } }
+		}
+		`, map[string]interface{}{"id": accountID}, &data,
+	)
+	o := data.Accounts[0].Orders.Orders[0]
+	if o.Status != "DELIVERED" || len(o.Shipments) != 1 || o.Shipments[0].DeliveredAt == nil {
+		t.Errorf("got order %+v, want it delivered in one shipment", o)
+	}
+}
+
+func TestClaimGuestOrder(t *testing.T) {
+	stack := New(t)
+	productID := stack.CreateProduct(Product{Name: "Trail Shoe", Price: 80, Stock: 10})
+
+	// A rejected checkout doesn't leave a guest account behind
+	res := stack.Query(`
+		mutation($productId: String!) {
+			createGuestOrder(order: {
+				email: "jane@example.com",
+				shippingAddress: {name: "Jane Doe", line1: "1 Main St", city: "Springfield", postalCode: "12345", country: "US"},
+				products: [{id: $productId, quantity: 11}]
+			}) { token }
+		}
+		`, map[string]interface{}{"productId": productID},
+	)
+	if len(res.Errors) == 0 {
+		t.Fatalf("got %s ordering more than is in stock, want an error", res.Data)
+	}
+	var accounts struct {
+		Accounts []struct{ ID string }
+	}
+	stack.MustQuery(`query { accounts { id } }`, nil, &accounts)
+	if len(accounts.Accounts) != 0 {
+		t.Fatalf("got accounts %+v after a rejected checkout, want none", accounts.Accounts)
+	}
+
+	// Each checkout is its own guest, even with the same email address
+	checkout := func() (string, string) {
+		var data struct {
+			CreateGuestOrder struct {
+				Order struct{ ID string }
+				Token string
+			}
+		}
+		stack.MustQuery(`
+			mutation($productId: String!) {
+				createGuestOrder(order: {
+					email: "jane@example.com",
+					shippingAddress: {name: "Jane Doe", line1: "1 Main St", city: "Springfield", postalCode: "12345", country: "US"},
+					products: [{id: $productId, quantity: 1}]
+				}) { order { id } token }
+			}
+			`, map[string]interface{}{"productId": productID}, &data,
+		)
+		return data.CreateGuestOrder.Order.ID, data.CreateGuestOrder.Token
+	}
+	firstID, firstToken := checkout()
+	_, secondToken := checkout()
+
+	register := func(name, email string) (string, string) {
+		var data struct {
+			CreateAccount struct{ ID string }
+		}
+		stack.MustQuery(`
+			mutation($name: String!, $email: String) {
+				createAccount(account: {name: $name, email: $email}) { id }
+			}
+			`, map[string]interface{}{"name": name, "email": email}, &data,
+		)
+		id := data.CreateAccount.ID
+		stack.MustQuery(`mutation($id: String!) { requestEmailVerification(accountId: $id) }`, map[string]interface{}{"id": id}, nil)
+		return id, stack.Mailbox.EmailToken(id)
+	}
+	claim := func(token, emailToken string) Response {
+		return stack.Query(`
+			mutation($token: String!, $emailToken: String!) {
+				claimGuestOrder(token: $token, emailToken: $emailToken) { id }
+			}
+			`, map[string]interface{}{"token": token, "emailToken": emailToken},
+		)
+	}
+
+	// Neither an account with another email address nor a made up email token can claim the order
+	_, malloryToken := register("Mallory", "mallory@example.com")
+	if res := claim(firstToken, malloryToken); len(res.Errors) == 0 {
+		t.Fatalf("got %s claiming into an account with another email address, want an error", res.Data)
+	}
+	janeID, janeToken := register("Jane", "Jane@Example.com")
+	if res := claim(firstToken, janeToken+"x"); len(res.Errors) == 0 {
+		t.Fatalf("got %s claiming with a tampered email token, want an error", res.Data)
+	}
+
+	var claimed struct {
+		ClaimGuestOrder struct{ ID string }
+	}
+	stack.MustQuery(`
+		mutation($token: String!, $emailToken: String!) {
+			claimGuestOrder(token: $token, emailToken: $emailToken) { id }
+		}
+		`, map[string]interface{}{"token": firstToken, "emailToken": janeToken}, &claimed,
+	)
+	if claimed.ClaimGuestOrder.ID != firstID {
+		t.Errorf("got order %s claimed, want %s", claimed.ClaimGuestOrder.ID, firstID)
+	}
+	if res := claim(firstToken, janeToken); len(res.Errors) == 0 {
+		t.Fatalf("got %s claiming the order twice, want an error", res.Data)
+	}
+	// Email tokens only work once, the claim used up Jane's
+	if res := stack.Query(`mutation($token: String!) { verifyEmail(token: $token) { id } }`, map[string]interface{}{"token": janeToken}); len(res.Errors) == 0 {
+		t.Fatalf("got %s verifying with a used email token, want an error", res.Data)
+	}
+
+	// Only the order of the token moved, the other checkout stays with its guest
+	var data struct {
+		Accounts []struct {
+			EmailVerified bool
+			Orders        struct{ Orders []struct{ ID string } }
+		}
+	}
+	stack.MustQuery(`query($id: String!) { accounts(id: $id) { emailVerified orders { orders { id } } } }`, map[string]interface{}{"id": janeID}, &data)
+	if len(data.Accounts) != 1 || !data.Accounts[0].EmailVerified || len(data.Accounts[0].Orders.Orders) != 1 || data.Accounts[0].Orders.Orders[0].ID != firstID {
+		t.Errorf("got account %+v, want it verified with only the claimed order", data.Accounts)
+	}
+	var guest struct {
+		GuestOrder struct{ ID string }
+	}
+	stack.MustQuery(`query($token: String!) { guestOrder(token: $token) { id } }`, map[string]interface{}{"token": secondToken}, &guest)
+	if guest.GuestOrder.ID == "" || guest.GuestOrder.ID == firstID {
+		t.Errorf("got guest order %+v, want the second checkout", guest.GuestOrder)
+	}
+}
+
+func TestReviewProduct(t *testing.T) {
+	stack := New(t)
+	accountID := stack.CreateAccount("Jane")
+	productID := stack.CreateProduct(Product{Name: "Trail Shoe", Price: 80, Stock: 5})
+	orderID := stack.CreateOrder(accountID, OrderLine{ProductID: productID, Quantity: 1})
+	createReview := `
+		mutation($accountId: String!, $productId: String!) {
+			createReview(review: {accountId: $accountId, productId: $productId, rating: 4, text: "Comfy"}) { id status }
+		}
+		`
+	vars := map[string]interface{}{"accountId": accountID, "productId": productID}
+
+	// Products can't be reviewed before they have been delivered
+	if res := stack.Query(createReview, vars); len(res.Errors) == 0 {
+		t.Fatalf("got %s, want an error for a product that hasn't been delivered", res.Data)
+	}
+	stack.DeliverOrder(orderID)
+
+	var created struct {
+		CreateReview struct{ ID, Status string }
+	}
+	stack.MustQuery(createReview, vars, &created)
+	if created.CreateReview.Status != "PENDING" {
+		t.Errorf("got status %s, want the review to wait for moderation", created.CreateReview.Status)
+	}
+	if res := stack.Query(createReview, vars); len(res.Errors) == 0 {
+		t.Errorf("got %s, want an error for a second review of the product", res.Data)
+	}
+
+	var product struct {
+		Products struct {
+			Products []struct {
+				AverageRating *float64
+				RatingCount   int
+				Reviews       struct {
+					TotalCount int
+					Reviews    []struct{ ID, Text string }
+				}
+			}
+		}
+	}
+	productQuery := `
+		query($id: String!) {
+			products(id: $id) { products { averageRating ratingCount reviews { totalCount reviews { id text } } } }
+		}
+		`
+
+	// Pending reviews are left out of the product and its rating
+	stack.MustQuery(productQuery, map[string]interface{}{"id": productID}, &product)
+	p := product.Products.Products[0]
+	if p.AverageRating != nil || p.RatingCount != 0 || p.Reviews.TotalCount != 0 {
+		t.Errorf("got product %+v, want no rating or reviews before moderation", p)
+	}
+
+	stack.MustQuery(`
+		mutation($id: String!) { approveReview(id: $id) { status } }
+		`, map[string]interface{}{"id": created.CreateReview.ID}, nil,
+	)
+	stack.MustQuery(productQuery, map[string]interface{}{"id": productID}, &product)
+	p = product.Products.Products[0]
+	if p.AverageRating == nil || *p.AverageRating != 4 || p.RatingCount != 1 {
+		t.Errorf("got product %+v, want a rating of 4 from 1 review", p)
+	}
+	if len(p.Reviews.Reviews) != 1 || p.Reviews.Reviews[0].Text != "Comfy" {
+		t.Errorf("got reviews %+v, want the approved one", p.Reviews.Reviews)
+	}
+}
+
+func TestLocalizedProducts(t *testing.T) {
+	stack := New(t)
+	productID := stack.CreateProduct(Product{Name: "Rain Jacket", Description: "Keeps you dry", Price: 90})
+	stack.MustQuery(`
+		mutation($id: String!) {
+			translateProduct(productId: $id, locale: "de", translation: {name: "Regenjacke"}) { id }
+		}
+		`, map[string]interface{}{"id": productID}, nil)
+
+	search := `
+		query($query: String, $locale: String) {
+			products(query: $query, locale: $locale) { products { name description locale } }
+		}
+		`
+	type result struct {
+		Products struct {
+			Products []struct{ Name, Description, Locale string }
+		}
+	}
+	for _, tt := range []struct {
+		name           string
+		acceptLanguage string
+		vars           map[string]interface{}
+		wantName       string
+		wantLocale     string
+	}{
+		{"default", "", map[string]interface{}{}, "Rain Jacket", "en"},
+		{"header", "de-CH, de;q=0.9, en;q=0.8", map[string]interface{}{"query": "regenjacke"}, "Regenjacke", "de"},
+		{"unsupported header", "ja", map[string]interface{}{}, "Rain Jacket", "en"},
+		{"argument", "de", map[string]interface{}{"query": "rain", "locale": "en"}, "Rain Jacket", "en"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stack.Header.Set("Accept-Language", tt.acceptLanguage)
+			var data result
+			stack.MustQuery(search, tt.vars, &data)
+			if len(data.Products.Products) != 1 {
+				t.Fatalf("got %+v, want the product", data.Products.Products)
+			}
+			// The description hasn't been translated, so it falls back to the default locale
+			got := data.Products.Products[0]
+			if got.Name != tt.wantName || got.Locale != tt.wantLocale || got.Description != "Keeps you dry" {
+				t.Errorf("got %+v, want name %q in locale %s", got, tt.wantName, tt.wantLocale)
+			}
+		})
+	}
+
+	stack.Header.Del("Accept-Language")
+	if res := stack.Query(`query { products(locale: "xx") { totalCount } }`, nil); len(res.Errors) == 0 {
+		t.Errorf("got %s, want an error for an unsupported locale", res.Data)
+	}
+}
+
+func TestProductImages(t *testing.T) {
+	stack := New(t)
+	productID := stack.CreateProduct(Product{Name: "Hiking Boot", Price: 120})
+	upload := `
+		mutation($id: String!, $file: Upload!, $altText: String, $position: Int) {
+			uploadProductImage(productId: $id, file: $file, altText: $altText, position: $position) {
+				images { id url thumbnailUrl altText width height }
+			}
+		}
+		`
+	type image struct {
+		ID, URL, ThumbnailURL, AltText string
+		Width, Height                  int
+	}
+	var uploaded struct {
+		UploadProductImage struct{ Images []image }
+	}
+
+	res := stack.Upload(upload, map[string]interface{}{"id": productID, "altText": "Side view"}, map[string][]byte{"file": testPNG(t, 600, 300)})
+	if len(res.Errors) > 0 {
+		t.Fatalf("upload failed: %+v", res.Errors)
+	}
+	if err := json.Unmarshal(res.Data, &uploaded); err != nil {
+		t.Fatal(err)
+	}
+	side := uploaded.UploadProductImage.Images[0]
+	if side.Width != 600 || side.Height != 300 || side.AltText != "Side view" {
+		t.Errorf("got image %+v, want the 600x300 side view", side)
+	}
+
+	// The thumbnail keeps the aspect ratio of the image
+	thumbnail := blobImage(t, stack, strings.TrimPrefix(side.ThumbnailURL, "/media/"))
+	if size := thumbnail.Bounds().Size(); size.X != 256 || size.Y != 128 {
+		t.Errorf("got a %v thumbnail, want 256x128", size)
+	}
+
+	// A second image goes in front of the first
+	res = stack.Upload(upload, map[string]interface{}{"id": productID, "position": 0}, map[string][]byte{"file": testPNG(t, 100, 100)})
+	if err := json.Unmarshal(res.Data, &uploaded); err != nil || len(res.Errors) > 0 {
+		t.Fatalf("upload failed: %+v %v", res.Errors, err)
+	}
+	if images := uploaded.UploadProductImage.Images; len(images) != 2 || images[1].ID != side.ID {
+		t.Fatalf("got images %+v, want the new one first", images)
+	}
+
+	var updated struct {
+		UpdateProductImage struct{ Images []image }
+	}
+	stack.MustQuery(`
+		mutation($id: String!, $imageId: String!) {
+			updateProductImage(productId: $id, imageId: $imageId, altText: "Boot from the side", position: 0) {
+				images { id altText }
+			}
+		}
+		`, map[string]interface{}{"id": productID, "imageId": side.ID}, &updated)
+	if images := updated.UpdateProductImage.Images; images[0].ID != side.ID || images[0].AltText != "Boot from the side" {
+		t.Errorf("got images %+v, want the side view moved to the front with its new alt text", images)
+	}
+
+	var deleted struct {
+		DeleteProductImage struct{ Images []image }
+	}
+	stack.MustQuery(`
+		mutation($id: String!, $imageId: String!) {
+			deleteProductImage(productId: $id, imageId: $imageId) { images { id } }
+		}
+		`, map[string]interface{}{"id": productID, "imageId": side.ID}, &deleted)
+	if images := deleted.DeleteProductImage.Images; len(images) != 1 || images[0].ID == side.ID {
+		t.Errorf("got images %+v, want the side view removed", images)
+	}
+	if _, err := stack.Media.Get(context.Background(), strings.TrimPrefix(side.URL, "/media/")); !errors.Is(err, blob.ErrNotFound) {
+		t.Errorf("got %v, want the file of the deleted image to be gone", err)
+	}
+
+	res = stack.Upload(upload, map[string]interface{}{"id": productID}, map[string][]byte{"file": []byte("not an image")})
+	if len(res.Errors) == 0 {
+		t.Errorf("got %s, want an error for a file that isn't an image", res.Data)
+	}
+}
+
+// testPNG encodes an image of the given size
+func testPNG(t *testing.T, width int, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i)
+	}
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// blobImage decodes an image from the media of the stack
+func blobImage(t *testing.T, stack *Stack, key string) image.Image {
+	r, err := stack.Media.Get(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	img, _, err := image.Decode(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
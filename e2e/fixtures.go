@@ -0,0 +1,103 @@
+package e2e
+
+import (
+	"context"
+	"time"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/order"
+)
+
+// Fixtures seed data straight through the services, failing the test if that isn't possible. Orders are the
+// exception: they are placed through GraphQL, as they are validated and priced by the order gRPC server.
+
+func (s *Stack) CreateAccount(name string) string {
+	s.t.Helper()
+	a, err := s.Accounts.PostAccount(context.Background(), name, "")
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return a.ID
+}
+
+func (s *Stack) CreateCategory(name string, parentID string) string {
+	s.t.Helper()
+	c, err := s.Catalog.PostCategory(context.Background(), name, parentID)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return c.ID
+}
+
+// Product describes a product to seed
+type Product struct {
+	Name        string
+	Description string
+	Price       float64
+	Stock       int64
+	CategoryID  string
+	Tags        []string
+}
+
+func (s *Stack) CreateProduct(p Product) string {
+	s.t.Helper()
+	ctx := context.Background()
+	product, err := s.Catalog.PostProduct(ctx, p.Name, p.Description, p.Price, p.Stock, p.CategoryID, p.Tags)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return product.ID
+}
+
+// OrderLine is a line of an order to place; VariantID is only needed for products with variants
+type OrderLine struct {
+	ProductID string
+	VariantID string
+	Quantity  int
+}
+
+// CreateOrder places an order for the account through the createOrder mutation
+func (s *Stack) CreateOrder(accountID string, lines ...OrderLine) string {
+	s.t.Helper()
+	products := []map[string]interface{}{}
+	for _, l := range lines {
+		p := map[string]interface{}{"id": l.ProductID, "quantity": l.Quantity}
+		if l.VariantID != "" {
+			p["variantId"] = l.VariantID
+		}
+		products = append(products, p)
+	}
+
+	var data struct {
+		CreateOrder struct{ ID string }
+	}
+	s.MustQuery(`
+		mutation($order: OrderInput!) {
+			createOrder(order: $order) { id }
+		}
+		`, map[string]interface{}{"order": map[string]interface{}{"accountId": accountID, "products": products}}, &data,
+	)
+	return data.CreateOrder.ID
+}
+
+// DeliverOrder ships all lines of an order in one shipment and marks it delivered
+func (s *Stack) DeliverOrder(orderID string) {
+	s.t.Helper()
+	ctx := context.Background()
+	o, err := s.Orders.GetOrder(ctx, orderID)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	products := []order.ShippedProduct{}
+	for _, p := range o.Products {
+		products = append(products, order.ShippedProduct{ID: p.ID, VariantID: p.VariantID, Quantity: p.Quantity})
+	}
+
+	now := time.Now().UTC()
+	shipment, _, err := s.Orders.CreateShipment(ctx, orderID, "e2e", "TRACK-"+orderID, &now, products)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if _, _, err := s.Orders.UpdateShipment(ctx, shipment.ID, order.ShipmentUpdate{DeliveredAt: &now}); err != nil {
+		s.t.Fatal(err)
+	}
+}
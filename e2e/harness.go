@@ -0,0 +1,243 @@
+// Package e2e runs the whole API in-process for end-to-end tests: the account, catalog, order and review
+// services with in-memory repositories, served over in-memory gRPC connections, and the GraphQL gateway on top of them.
+//
+//	stack := e2e.New(t)
+//	accountID := stack.CreateAccount("Jane")
+//	var data struct{ Accounts []struct{ Name string } }
+//	stack.MustQuery(`query { accounts { name } }`, nil, &data)
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/99designs/gqlgen/handler"
+	"github.com/PranavTrip/go-grpc-graphql-ms/account"
+	"github.com/PranavTrip/go-grpc-graphql-ms/blob"
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"github.com/PranavTrip/go-grpc-graphql-ms/graphql"
+	"github.com/PranavTrip/go-grpc-graphql-ms/order"
+	"github.com/PranavTrip/go-grpc-graphql-ms/review"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Size of the in-memory buffer of each gRPC connection
+const bufferSize = 1 << 20
+
+// Secret the order links of guests are signed with
+const GuestTokenSecret = "e2e"
+
+// Secret the email verification tokens of accounts are signed with
+const EmailTokenSecret = "e2e-email"
+
+// Limits every order is validated against, the defaults of the order service
+var Limits = order.OrderLimits{
+	MaxProductQuantity: 100,
+	MaxOrderQuantity:   1000,
+}
+
+// Stack is a running API. The services are exposed to seed data or check the outcome of operations without
+// going through GraphQL.
+type Stack struct {
+	Accounts account.Service
+	Catalog  catalog.Service
+	Orders   order.Service
+	Reviews  review.Service
+	// Media holds the product images
+	Media blob.Store
+	// Mailbox holds the emails of the account service
+	Mailbox *Mailbox
+	// Header is sent along with every GraphQL request, e.g. to pick a locale with Accept-Language
+	Header http.Header
+
+	t       testing.TB
+	handler http.Handler
+}
+
+// New starts the services and the gateway with empty repositories, stopping them when the test ends
+func New(t testing.TB) *Stack {
+	t.Helper()
+	media, err := blob.NewFileStore(t.TempDir(), "/media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailbox := &Mailbox{}
+	verification := account.EmailVerification{Secret: []byte(EmailTokenSecret), Mailer: mailbox}
+	s := &Stack{
+		Accounts: account.NewService(account.NewMemoryRepository(), verification),
+		Orders:   order.NewService(order.NewMemoryRepository()),
+		Reviews:  review.NewService(review.NewMemoryRepository()),
+		Media:    media,
+		Mailbox:  mailbox,
+		Header:   http.Header{},
+		t:        t,
+	}
+
+	// Every service listens on its own buffer, dialed by the name of the service
+	listeners := map[string]*bufconn.Listener{
+		"account": bufconn.Listen(bufferSize),
+		"catalog": bufconn.Listen(bufferSize),
+		"order":   bufconn.Listen(bufferSize),
+		"review":  bufconn.Listen(bufferSize),
+	}
+	dialer := grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		lis, ok := listeners[addr]
+		if !ok {
+			return nil, fmt.Errorf("no service named %q", addr)
+		}
+		return lis.DialContext(ctx)
+	})
+	for _, lis := range listeners {
+		t.Cleanup(func() { lis.Close() })
+	}
+
+	// Searches are personalized with the purchase history of the order service, with the default boosts of
+	// the catalog service
+	orderClient, err := order.NewClient("order", dialer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(orderClient.Close)
+	personalization := catalog.Personalization{History: orderClient, CategoryBoost: 2, TagBoost: 1.5}
+	s.Catalog = catalog.NewService(catalog.NewMemoryRepository(), media, personalization)
+
+	// Serving stops with an error once the listeners are closed, which is how every test ends
+	go account.ServeGRPC(s.Accounts, listeners["account"])
+	go catalog.ServeGRPC(s.Catalog, listeners["catalog"])
+	go order.ServeGRPC(s.Orders, "account", "catalog", Limits, GuestTokenSecret, listeners["order"], dialer)
+	go review.ServeGRPC(s.Reviews, "order", "catalog", listeners["review"], dialer)
+
+	gateway, err := graphql.NewGraphQLServer("account", "catalog", "order", "review", dialer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(gateway.Close)
+	s.handler = graphql.WithLocale(handler.GraphQL(gateway.ToExecutableSchema()))
+	return s
+}
+
+// Mailbox keeps the emails of the account service instead of sending them
+type Mailbox struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (m *Mailbox) SendEmailVerification(ctx context.Context, a account.Account, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tokens == nil {
+		m.tokens = map[string]string{}
+	}
+	m.tokens[a.ID] = token
+	return nil
+}
+
+// EmailToken returns the last email verification token sent to an account, if any
+func (m *Mailbox) EmailToken(accountID string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tokens[accountID]
+}
+
+// Response is the result of a GraphQL operation
+type Response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []Error         `json:"errors"`
+}
+
+type Error struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+// Query runs a GraphQL query or mutation, returning its data and errors as they are
+func (s *Stack) Query(query string, variables map[string]interface{}) Response {
+	s.t.Helper()
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return s.post(bytes.NewReader(body), "application/json")
+}
+
+// Upload runs a mutation as a multipart request, sending each of the files as the variable it is keyed by
+func (s *Stack) Upload(query string, variables map[string]interface{}, files map[string][]byte) Response {
+	s.t.Helper()
+	vars := map[string]interface{}{}
+	for k, v := range variables {
+		vars[k] = v
+	}
+	// Files are null in the operation, and mapped to the parts they are sent in
+	fileMap := map[string][]string{}
+	for name := range files {
+		vars[name] = nil
+		fileMap[name] = []string{"variables." + name}
+	}
+	operations, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	mapping, err := json.Marshal(fileMap)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	w.WriteField("operations", string(operations))
+	w.WriteField("map", string(mapping))
+	for name, data := range files {
+		part, err := w.CreateFormFile(name, name)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	if err := w.Close(); err != nil {
+		s.t.Fatal(err)
+	}
+	return s.post(body, w.FormDataContentType())
+}
+
+func (s *Stack) post(body io.Reader, contentType string) Response {
+	s.t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/graphql", body)
+	for k, v := range s.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+
+	res := Response{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		s.t.Fatalf("decoding response %q with status %d: %v", rec.Body.String(), rec.Code, err)
+	}
+	return res
+}
+
+// MustQuery runs a GraphQL query or mutation and decodes its data into out, failing the test on any error
+func (s *Stack) MustQuery(query string, variables map[string]interface{}, out interface{}) {
+	s.t.Helper()
+	res := s.Query(query, variables)
+	if len(res.Errors) > 0 {
+		s.t.Fatalf("query failed: %+v", res.Errors)
+	}
+	if out == nil {
+		return
+	}
+	if err := json.Unmarshal(res.Data, out); err != nil {
+		s.t.Fatalf("decoding data %s: %v", res.Data, err)
+	}
+}
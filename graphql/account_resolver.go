@@ -1,44 +1,91 @@
-package main
+package graphql
 
 import (
 	"context"
 	"log"
 	"time"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/account"
+	"github.com/PranavTrip/go-grpc-graphql-ms/order"
 )
 
 type accountResolver struct {
 	server *Server
 }
 
-func (r *accountResolver) Orders(ctx context.Context, obj *Account) ([]*Order, error) {
+func (r *accountResolver) Orders(ctx context.Context, obj *Account, filter *OrderFilter, sort *OrderSort, pagination *OrderPaginationInput) (*OrderConnection, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	orderList, err := r.server.orderClient.GetOrdersForAccount(ctx, obj.ID)
+	f := order.OrderFilter{}
+	if filter != nil {
+		f = filter.toFilter()
+	}
+	orderSort := order.OrderSort("")
+	if sort != nil {
+		orderSort = order.OrderSort(*sort)
+	}
+	after, take := "", uint64(0)
+	if pagination != nil {
+		var err error
+		if after, take, err = pagination.bounds(); err != nil {
+			return nil, err
+		}
+	}
+
+	orderList, next, err := r.server.orderClient.GetOrdersForAccount(ctx, obj.ID, f, orderSort, after, take)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	var orders []*Order
+	connection := &OrderConnection{Orders: []*Order{}}
 	for _, o := range orderList {
-		var products []*OrderedProducts
-		for _, p := range o.Products {
-			products = append(products, &OrderedProducts{
-				ID:          p.ID,
-				Name:        p.Name,
-				Description: p.Description,
-				Price:       p.Price,
-				Quantity:    int(p.Quantity),
-			})
+		connection.Orders = append(connection.Orders, toOrder(o))
+	}
+	if next != "" {
+		connection.NextCursor = &next
+	}
+	return connection, nil
+}
+
+func (f OrderFilter) toFilter() order.OrderFilter {
+	filter := order.OrderFilter{
+		CreatedAfter:  f.CreatedAfter,
+		CreatedBefore: f.CreatedBefore,
+		MinTotal:      f.MinTotal,
+		MaxTotal:      f.MaxTotal,
+	}
+	for _, status := range f.Statuses {
+		filter.Statuses = append(filter.Statuses, order.OrderStatus(status))
+	}
+	return filter
+}
+
+func (p OrderPaginationInput) bounds() (string, uint64, error) {
+	afterValue := ""
+	takeValue := uint64(100)
+	if p.After != nil {
+		afterValue = *p.After
+	}
+	if p.Take != nil {
+		if *p.Take < 0 {
+			return "", 0, ErrInvalidParameter
 		}
-		orders = append(orders, &Order{
-			ID:         o.ID,
-			CreatedAt:  o.CreatedAt,
-			TotalPrice: o.TotalPrice,
-			Products:   products,
-		})
+		takeValue = uint64(*p.Take)
 	}
+	return afterValue, takeValue, nil
+}
 
-	return orders, nil
+func toAccount(a account.Account) *Account {
+	acc := &Account{
+		ID:            a.ID,
+		Name:          a.Name,
+		EmailVerified: a.EmailVerified,
+		Guest:         a.Guest,
+	}
+	if a.Email != "" {
+		acc.Email = &a.Email
+	}
+	return acc
 }
@@ -1,12 +1,11 @@
 package main
 
 import (
-	"fmt"
 	"log"
 	"net/http"
-	"os"
 
 	"github.com/99designs/gqlgen/handler"
+	"github.com/PranavTrip/go-grpc-graphql-ms/graphql"
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -14,23 +13,22 @@ type AppConfig struct {
 	AccountURL string `envconfig:"ACCOUNT_SERVICE_URL"`
 	CatalogURL string `envconfig:"CATALOG_SERVICE_URL"`
 	OrderURL   string `envconfig:"ORDER_SERVICE_URL"`
+	ReviewURL  string `envconfig:"REVIEW_SERVICE_URL"`
 }
 
 func main() {
-	fmt.Println("ACCOUNT_SERVICE_URL =", os.Getenv("ACCOUNT_SERVICE_URL"))
-	fmt.Println("CATALOG_SERVICE_URL =", os.Getenv("CATALOG_SERVICE_URL"))
-	fmt.Println("ORDER_SERVICE_URL =", os.Getenv("ORDER_SERVICE_URL"))
 	var cfg AppConfig
 	err := envconfig.Process("", &cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
-	s, err := NewGraphQLServer(cfg.AccountURL, cfg.CatalogURL, cfg.OrderURL)
+	log.Printf("Services: account %s, catalog %s, order %s, review %s", cfg.AccountURL, cfg.CatalogURL, cfg.OrderURL, cfg.ReviewURL)
+	s, err := graphql.NewGraphQLServer(cfg.AccountURL, cfg.CatalogURL, cfg.OrderURL, cfg.ReviewURL)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	http.Handle("/graphql",handler.GraphQL(s.ToExecutableSchema()))
+	http.Handle("/graphql",graphql.WithLocale(handler.GraphQL(s.ToExecutableSchema())))
 	http.Handle("/playground", handler.Playground("pranav","/graphql"))
 
 	log.Fatal(http.ListenAndServe(":8080", nil))
@@ -1,31 +1,35 @@
-package main
+package graphql
 
 import (
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/PranavTrip/go-grpc-graphql-ms/account"
 	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
 	"github.com/PranavTrip/go-grpc-graphql-ms/order"
+	"github.com/PranavTrip/go-grpc-graphql-ms/review"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
 	accountClient *account.Client
 	catalogClient *catalog.Client
 	orderClient   *order.Client
+	reviewClient  *review.Client
 }
 
-func NewGraphQLServer(accountURL, catalogURL, orderURL string) (*Server, error) {
-	accountClient, err := account.NewClient(accountURL)
+// NewGraphQLServer connects to the services the gateway resolves queries with, dialing them with opts
+func NewGraphQLServer(accountURL, catalogURL, orderURL, reviewURL string, opts ...grpc.DialOption) (*Server, error) {
+	accountClient, err := account.NewClient(accountURL, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	catalogClient, err := catalog.NewClient(catalogURL)
+	catalogClient, err := catalog.NewClient(catalogURL, opts...)
 	if err != nil {
 		accountClient.Close()
 		return nil, err
 	}
 
-	orderClient, err := order.NewClient(orderURL)
+	orderClient, err := order.NewClient(orderURL, opts...)
 
 	if err != nil {
 		accountClient.Close()
@@ -33,11 +37,27 @@ func NewGraphQLServer(accountURL, catalogURL, orderURL string) (*Server, error)
 		return nil, err
 	}
 
+	reviewClient, err := review.NewClient(reviewURL, opts...)
+	if err != nil {
+		accountClient.Close()
+		catalogClient.Close()
+		orderClient.Close()
+		return nil, err
+	}
+
 	return &Server{
-		accountClient, catalogClient, orderClient,
+		accountClient, catalogClient, orderClient, reviewClient,
 	}, nil
 }
 
+// Close closes the connections to the services
+func (s *Server) Close() {
+	s.accountClient.Close()
+	s.catalogClient.Close()
+	s.orderClient.Close()
+	s.reviewClient.Close()
+}
+
 func (s *Server) Mutation() MutationResolver {
 	return &mutationResolver{
 		server: s,
@@ -56,6 +76,18 @@ func (s *Server) Account() AccountResolver {
 	}
 }
 
+func (s *Server) Order() OrderResolver {
+	return &orderResolver{
+		server: s,
+	}
+}
+
+func (s *Server) Product() ProductResolver {
+	return &productResolver{
+		server: s,
+	}
+}
+
 func (s *Server) ToExecutableSchema() graphql.ExecutableSchema {
 	return NewExecutableSchema(Config{
 		Resolvers: s,
@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"golang.org/x/text/language"
+)
+
+type localeKey struct{}
+
+// Matches the languages of requests against the locales of the catalog, the default one first so that it
+// is chosen when nothing else matches
+var localeMatcher, matcherLocales = newLocaleMatcher()
+
+func newLocaleMatcher() (language.Matcher, []string) {
+	codes := []string{catalog.DefaultLocale}
+	tags := []language.Tag{language.Make(catalog.DefaultLocale)}
+	for _, code := range catalog.Locales() {
+		if code != catalog.DefaultLocale {
+			codes = append(codes, code)
+			tags = append(tags, language.Make(code))
+		}
+	}
+	return language.NewMatcher(tags), codes
+}
+
+// WithLocale picks the locale product content is shown in from the Accept-Language header of each request,
+// falling back to the default locale of the catalog. The locale argument of a query overrides it.
+func WithLocale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := catalog.DefaultLocale
+		if header := r.Header.Get("Accept-Language"); header != "" {
+			tags, _, _ := language.ParseAcceptLanguage(header)
+			_, i, confidence := localeMatcher.Match(tags...)
+			if confidence != language.No {
+				locale = matcherLocales[i]
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), localeKey{}, locale)))
+	})
+}
+
+// localeFromContext returns the locale picked for the request, or the default locale outside of WithLocale
+func localeFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return catalog.DefaultLocale
+}
+
+// Returns the locale argument of a query if there is one, else the locale of the request
+func requestedLocale(ctx context.Context, locale *string) (string, error) {
+	if locale == nil {
+		return localeFromContext(ctx), nil
+	}
+	if !catalog.IsLocale(*locale) {
+		return "", catalog.ErrInvalidLocale
+	}
+	return *locale, nil
+}
+
+func toProductTranslations(p catalog.Product) []*ProductTranslation {
+	locales := []string{}
+	for l := range p.Translations {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+
+	translations := []*ProductTranslation{}
+	for _, l := range locales {
+		t := p.Translations[l]
+		translations = append(translations, &ProductTranslation{
+			Locale:      l,
+			Name:        optionalString(t.Name),
+			Description: optionalString(t.Description),
+		})
+	}
+	return translations
+}
@@ -1,7 +1,10 @@
-package main
+package graphql
 
 type Account struct {
-	ID     string  `json:"id"`
-	Name   string  `json:"name"`
-	Orders []Order `json:"orders"`
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Email         *string `json:"email"`
+	EmailVerified bool    `json:"emailVerified"`
+	Guest         bool    `json:"guest"`
+	Orders        []Order `json:"orders"`
 }
\ No newline at end of file
@@ -1,23 +1,94 @@
 // Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
 
-package main
+package graphql
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 )
 
 type AccountInput struct {
-	Name string `json:"name"`
+	Name  string  `json:"name"`
+	Email *string `json:"email,omitempty"`
+}
+
+type Address struct {
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postalCode"`
+	Country    string `json:"country"`
+}
+
+type AddressInput struct {
+	Name       string  `json:"name"`
+	Line1      string  `json:"line1"`
+	Line2      *string `json:"line2,omitempty"`
+	City       string  `json:"city"`
+	Region     *string `json:"region,omitempty"`
+	PostalCode string  `json:"postalCode"`
+	Country    string  `json:"country"`
+}
+
+type Category struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	ParentID *string  `json:"parentId,omitempty"`
+	Path     []string `json:"path"`
+}
+
+type CategoryInput struct {
+	Name     string  `json:"name"`
+	ParentID *string `json:"parentId,omitempty"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type GuestOrderInput struct {
+	Email           string               `json:"email"`
+	ShippingAddress *AddressInput        `json:"shippingAddress"`
+	Products        []*OrderProductInput `json:"products,omitempty"`
+}
+
+type GuestOrderResult struct {
+	Order *Order `json:"order"`
+	Token string `json:"token"`
 }
 
 type Mutation struct {
 }
 
 type Order struct {
-	ID         string             `json:"id"`
-	CreatedAt  time.Time          `json:"createdAt"`
-	TotalPrice float64            `json:"totalPrice"`
-	Products   []*OrderedProducts `json:"products"`
+	ID              string             `json:"id"`
+	AccountID       string             `json:"accountId"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	TotalPrice      float64            `json:"totalPrice"`
+	Status          OrderStatus        `json:"status"`
+	Products        []*OrderedProducts `json:"products"`
+	Returns         []*Return          `json:"returns"`
+	Shipments       []*Shipment        `json:"shipments"`
+	ShippingAddress *Address           `json:"shippingAddress,omitempty"`
+}
+
+type OrderConnection struct {
+	Orders     []*Order `json:"orders"`
+	NextCursor *string  `json:"nextCursor,omitempty"`
+}
+
+type OrderFilter struct {
+	CreatedAfter  *time.Time    `json:"createdAfter,omitempty"`
+	CreatedBefore *time.Time    `json:"createdBefore,omitempty"`
+	Statuses      []OrderStatus `json:"statuses,omitempty"`
+	MinTotal      *float64      `json:"minTotal,omitempty"`
+	MaxTotal      *float64      `json:"maxTotal,omitempty"`
 }
 
 type OrderInput struct {
@@ -25,17 +96,43 @@ type OrderInput struct {
 	Products  []*OrderProductInput `json:"products,omitempty"`
 }
 
+type OrderPaginationInput struct {
+	After *string `json:"after,omitempty"`
+	Take  *int    `json:"take,omitempty"`
+}
+
 type OrderProductInput struct {
-	ID       string `json:"id"`
-	Quantity int    `json:"quantity"`
+	ID        string  `json:"id"`
+	VariantID *string `json:"variantId,omitempty"`
+	Quantity  int     `json:"quantity"`
+}
+
+type OrderSearchFilter struct {
+	IDPrefix      *string       `json:"idPrefix,omitempty"`
+	AccountID     *string       `json:"accountId,omitempty"`
+	ProductID     *string       `json:"productId,omitempty"`
+	CreatedAfter  *time.Time    `json:"createdAfter,omitempty"`
+	CreatedBefore *time.Time    `json:"createdBefore,omitempty"`
+	Statuses      []OrderStatus `json:"statuses,omitempty"`
+	MinTotal      *float64      `json:"minTotal,omitempty"`
+	MaxTotal      *float64      `json:"maxTotal,omitempty"`
+}
+
+type OrderSearchResult struct {
+	Orders     []*Order `json:"orders"`
+	TotalCount int      `json:"totalCount"`
+	NextCursor *string  `json:"nextCursor,omitempty"`
 }
 
 type OrderedProducts struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
+	ID          string           `json:"id"`
+	VariantID   *string          `json:"variantId,omitempty"`
+	Sku         *string          `json:"sku,omitempty"`
+	Options     []*VariantOption `json:"options"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Price       float64          `json:"price"`
+	Quantity    int              `json:"quantity"`
 }
 
 type PaginationInput struct {
@@ -43,18 +140,670 @@ type PaginationInput struct {
 	Take *int `json:"take,omitempty"`
 }
 
+type PriceChange struct {
+	VariantID *string   `json:"variantId,omitempty"`
+	OldPrice  float64   `json:"oldPrice"`
+	NewPrice  float64   `json:"newPrice"`
+	ChangedBy string    `json:"changedBy"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
 type Product struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	ID                   string                `json:"id"`
+	Name                 string                `json:"name"`
+	Description          string                `json:"description"`
+	Locale               string                `json:"locale"`
+	Translations         []*ProductTranslation `json:"translations"`
+	Images               []*ProductImage       `json:"images"`
+	Price                float64               `json:"price"`
+	Stock                int                   `json:"stock"`
+	CreatedAt            *time.Time            `json:"createdAt,omitempty"`
+	CategoryID           *string               `json:"categoryId,omitempty"`
+	CategoryPath         []string              `json:"categoryPath"`
+	Tags                 []string              `json:"tags"`
+	Archived             bool                  `json:"archived"`
+	Variants             []*Variant            `json:"variants"`
+	FrequentlyBoughtWith []*Product            `json:"frequentlyBoughtWith"`
+	PriceHistory         []*PriceChange        `json:"priceHistory"`
+	AverageRating        *float64              `json:"averageRating,omitempty"`
+	RatingCount          int                   `json:"ratingCount"`
+	Reviews              *ReviewConnection     `json:"reviews"`
+}
+
+type ProductFacets struct {
+	Categories []*FacetCount `json:"categories"`
+	Tags       []*FacetCount `json:"tags"`
+}
+
+type ProductFilter struct {
+	CategoryID *string  `json:"categoryId,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	MinPrice   *float64 `json:"minPrice,omitempty"`
+	MaxPrice   *float64 `json:"maxPrice,omitempty"`
+	InStock    *bool    `json:"inStock,omitempty"`
+}
+
+type ProductImage struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+	AltText      string `json:"altText"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
 }
 
 type ProductInput struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Price       float64  `json:"price"`
+	Stock       *int     `json:"stock,omitempty"`
+	CategoryID  *string  `json:"categoryId,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+type ProductSales struct {
+	ProductID string  `json:"productId"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	Revenue   float64 `json:"revenue"`
+}
+
+type ProductSearchResult struct {
+	Products   []*Product     `json:"products"`
+	TotalCount int            `json:"totalCount"`
+	Facets     *ProductFacets `json:"facets"`
+}
+
+type ProductSuggestion struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+type ProductTranslation struct {
+	Locale      string  `json:"locale"`
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type ProductTranslationInput struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type ProductUpdateInput struct {
+	Name        *string  `json:"name,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Price       *float64 `json:"price,omitempty"`
+	ChangedBy   *string  `json:"changedBy,omitempty"`
 }
 
 type Query struct {
 }
+
+type Refund struct {
+	ID        string    `json:"id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ReorderResult struct {
+	Order        *Order              `json:"order,omitempty"`
+	SkippedLines []*SkippedOrderLine `json:"skippedLines"`
+}
+
+type Return struct {
+	ID        string             `json:"id"`
+	OrderID   string             `json:"orderId"`
+	Status    ReturnStatus       `json:"status"`
+	Reason    string             `json:"reason"`
+	Note      string             `json:"note"`
+	CreatedAt time.Time          `json:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+	Products  []*ReturnedProduct `json:"products"`
+	Refund    *Refund            `json:"refund,omitempty"`
+}
+
+type ReturnInput struct {
+	OrderID  string                `json:"orderId"`
+	Reason   string                `json:"reason"`
+	Products []*ReturnProductInput `json:"products"`
+}
+
+type ReturnProductInput struct {
+	ID        string  `json:"id"`
+	VariantID *string `json:"variantId,omitempty"`
+	Quantity  int     `json:"quantity"`
+}
+
+type ReturnedProduct struct {
+	ID        string  `json:"id"`
+	VariantID *string `json:"variantId,omitempty"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+	Restocked bool    `json:"restocked"`
+}
+
+type Review struct {
+	ID        string       `json:"id"`
+	ProductID string       `json:"productId"`
+	AccountID string       `json:"accountId"`
+	Rating    int          `json:"rating"`
+	Text      string       `json:"text"`
+	Status    ReviewStatus `json:"status"`
+	Note      string       `json:"note"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+type ReviewConnection struct {
+	Reviews    []*Review `json:"reviews"`
+	TotalCount int       `json:"totalCount"`
+	NextCursor *string   `json:"nextCursor,omitempty"`
+}
+
+type ReviewInput struct {
+	AccountID string  `json:"accountId"`
+	ProductID string  `json:"productId"`
+	Rating    int     `json:"rating"`
+	Text      *string `json:"text,omitempty"`
+}
+
+type ReviewPaginationInput struct {
+	After *string `json:"after,omitempty"`
+	Take  *int    `json:"take,omitempty"`
+}
+
+type SalesBucket struct {
+	Start      time.Time `json:"start"`
+	Revenue    float64   `json:"revenue"`
+	OrderCount int       `json:"orderCount"`
+}
+
+type SalesReport struct {
+	Buckets           []*SalesBucket `json:"buckets"`
+	Revenue           float64        `json:"revenue"`
+	OrderCount        int            `json:"orderCount"`
+	AverageOrderValue float64        `json:"averageOrderValue"`
+}
+
+type Shipment struct {
+	ID             string            `json:"id"`
+	OrderID        string            `json:"orderId"`
+	Carrier        string            `json:"carrier"`
+	TrackingNumber string            `json:"trackingNumber"`
+	ShippedAt      *time.Time        `json:"shippedAt,omitempty"`
+	DeliveredAt    *time.Time        `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	Products       []*ShippedProduct `json:"products"`
+}
+
+type ShipmentInput struct {
+	OrderID        string                  `json:"orderId"`
+	Carrier        string                  `json:"carrier"`
+	TrackingNumber string                  `json:"trackingNumber"`
+	ShippedAt      *time.Time              `json:"shippedAt,omitempty"`
+	Products       []*ShipmentProductInput `json:"products"`
+}
+
+type ShipmentProductInput struct {
+	ID        string  `json:"id"`
+	VariantID *string `json:"variantId,omitempty"`
+	Quantity  int     `json:"quantity"`
+}
+
+type ShipmentUpdateInput struct {
+	Carrier        *string    `json:"carrier,omitempty"`
+	TrackingNumber *string    `json:"trackingNumber,omitempty"`
+	ShippedAt      *time.Time `json:"shippedAt,omitempty"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+type ShippedProduct struct {
+	ID        string  `json:"id"`
+	VariantID *string `json:"variantId,omitempty"`
+	Quantity  int     `json:"quantity"`
+}
+
+type SkippedOrderLine struct {
+	ProductID string  `json:"productId"`
+	VariantID *string `json:"variantId,omitempty"`
+	Quantity  int     `json:"quantity"`
+	Reason    string  `json:"reason"`
+}
+
+type Variant struct {
+	ID            string           `json:"id"`
+	Sku           string           `json:"sku"`
+	Options       []*VariantOption `json:"options"`
+	Price         float64          `json:"price"`
+	PriceOverride *float64         `json:"priceOverride,omitempty"`
+	Stock         int              `json:"stock"`
+}
+
+type VariantInput struct {
+	Sku       string                `json:"sku"`
+	Options   []*VariantOptionInput `json:"options"`
+	Price     *float64              `json:"price,omitempty"`
+	Stock     *int                  `json:"stock,omitempty"`
+	ChangedBy *string               `json:"changedBy,omitempty"`
+}
+
+type VariantOption struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type VariantOptionInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type OrderSort string
+
+const (
+	OrderSortCreatedAtDesc  OrderSort = "CREATED_AT_DESC"
+	OrderSortCreatedAtAsc   OrderSort = "CREATED_AT_ASC"
+	OrderSortTotalPriceDesc OrderSort = "TOTAL_PRICE_DESC"
+	OrderSortTotalPriceAsc  OrderSort = "TOTAL_PRICE_ASC"
+)
+
+var AllOrderSort = []OrderSort{
+	OrderSortCreatedAtDesc,
+	OrderSortCreatedAtAsc,
+	OrderSortTotalPriceDesc,
+	OrderSortTotalPriceAsc,
+}
+
+func (e OrderSort) IsValid() bool {
+	switch e {
+	case OrderSortCreatedAtDesc, OrderSortCreatedAtAsc, OrderSortTotalPriceDesc, OrderSortTotalPriceAsc:
+		return true
+	}
+	return false
+}
+
+func (e OrderSort) String() string {
+	return string(e)
+}
+
+func (e *OrderSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderSort", str)
+	}
+	return nil
+}
+
+func (e OrderSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OrderSort) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OrderSort) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusPending          OrderStatus = "PENDING"
+	OrderStatusPartiallyShipped OrderStatus = "PARTIALLY_SHIPPED"
+	OrderStatusShipped          OrderStatus = "SHIPPED"
+	OrderStatusDelivered        OrderStatus = "DELIVERED"
+)
+
+var AllOrderStatus = []OrderStatus{
+	OrderStatusPending,
+	OrderStatusPartiallyShipped,
+	OrderStatusShipped,
+	OrderStatusDelivered,
+}
+
+func (e OrderStatus) IsValid() bool {
+	switch e {
+	case OrderStatusPending, OrderStatusPartiallyShipped, OrderStatusShipped, OrderStatusDelivered:
+		return true
+	}
+	return false
+}
+
+func (e OrderStatus) String() string {
+	return string(e)
+}
+
+func (e *OrderStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderStatus", str)
+	}
+	return nil
+}
+
+func (e OrderStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OrderStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OrderStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ProductSalesSort string
+
+const (
+	ProductSalesSortQuantity ProductSalesSort = "QUANTITY"
+	ProductSalesSortRevenue  ProductSalesSort = "REVENUE"
+)
+
+var AllProductSalesSort = []ProductSalesSort{
+	ProductSalesSortQuantity,
+	ProductSalesSortRevenue,
+}
+
+func (e ProductSalesSort) IsValid() bool {
+	switch e {
+	case ProductSalesSortQuantity, ProductSalesSortRevenue:
+		return true
+	}
+	return false
+}
+
+func (e ProductSalesSort) String() string {
+	return string(e)
+}
+
+func (e *ProductSalesSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductSalesSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductSalesSort", str)
+	}
+	return nil
+}
+
+func (e ProductSalesSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ProductSalesSort) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ProductSalesSort) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ProductSort string
+
+const (
+	ProductSortRelevance ProductSort = "RELEVANCE"
+	ProductSortPriceAsc  ProductSort = "PRICE_ASC"
+	ProductSortPriceDesc ProductSort = "PRICE_DESC"
+	ProductSortNewest    ProductSort = "NEWEST"
+	ProductSortName      ProductSort = "NAME"
+	ProductSortRating    ProductSort = "RATING"
+)
+
+var AllProductSort = []ProductSort{
+	ProductSortRelevance,
+	ProductSortPriceAsc,
+	ProductSortPriceDesc,
+	ProductSortNewest,
+	ProductSortName,
+	ProductSortRating,
+}
+
+func (e ProductSort) IsValid() bool {
+	switch e {
+	case ProductSortRelevance, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortNewest, ProductSortName, ProductSortRating:
+		return true
+	}
+	return false
+}
+
+func (e ProductSort) String() string {
+	return string(e)
+}
+
+func (e *ProductSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductSort", str)
+	}
+	return nil
+}
+
+func (e ProductSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ProductSort) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ProductSort) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "REQUESTED"
+	ReturnStatusApproved  ReturnStatus = "APPROVED"
+	ReturnStatusRejected  ReturnStatus = "REJECTED"
+	ReturnStatusReceived  ReturnStatus = "RECEIVED"
+)
+
+var AllReturnStatus = []ReturnStatus{
+	ReturnStatusRequested,
+	ReturnStatusApproved,
+	ReturnStatusRejected,
+	ReturnStatusReceived,
+}
+
+func (e ReturnStatus) IsValid() bool {
+	switch e {
+	case ReturnStatusRequested, ReturnStatusApproved, ReturnStatusRejected, ReturnStatusReceived:
+		return true
+	}
+	return false
+}
+
+func (e ReturnStatus) String() string {
+	return string(e)
+}
+
+func (e *ReturnStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReturnStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReturnStatus", str)
+	}
+	return nil
+}
+
+func (e ReturnStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReturnStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReturnStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "PENDING"
+	ReviewStatusApproved ReviewStatus = "APPROVED"
+	ReviewStatusRejected ReviewStatus = "REJECTED"
+)
+
+var AllReviewStatus = []ReviewStatus{
+	ReviewStatusPending,
+	ReviewStatusApproved,
+	ReviewStatusRejected,
+}
+
+func (e ReviewStatus) IsValid() bool {
+	switch e {
+	case ReviewStatusPending, ReviewStatusApproved, ReviewStatusRejected:
+		return true
+	}
+	return false
+}
+
+func (e ReviewStatus) String() string {
+	return string(e)
+}
+
+func (e *ReviewStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReviewStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReviewStatus", str)
+	}
+	return nil
+}
+
+func (e ReviewStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReviewStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReviewStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SalesInterval string
+
+const (
+	SalesIntervalDay   SalesInterval = "DAY"
+	SalesIntervalWeek  SalesInterval = "WEEK"
+	SalesIntervalMonth SalesInterval = "MONTH"
+)
+
+var AllSalesInterval = []SalesInterval{
+	SalesIntervalDay,
+	SalesIntervalWeek,
+	SalesIntervalMonth,
+}
+
+func (e SalesInterval) IsValid() bool {
+	switch e {
+	case SalesIntervalDay, SalesIntervalWeek, SalesIntervalMonth:
+		return true
+	}
+	return false
+}
+
+func (e SalesInterval) String() string {
+	return string(e)
+}
+
+func (e *SalesInterval) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SalesInterval(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SalesInterval", str)
+	}
+	return nil
+}
+
+func (e SalesInterval) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SalesInterval) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SalesInterval) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -1,12 +1,16 @@
-package main
+package graphql
 
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
 	"github.com/PranavTrip/go-grpc-graphql-ms/order"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 var (
@@ -21,34 +25,247 @@ func (r *mutationResolver) CreateAccount(ctx context.Context, in AccountInput) (
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	a, err := r.server.accountClient.PostAccount(ctx, in.Name)
+	email := ""
+	if in.Email != nil {
+		email = *in.Email
+	}
+	a, err := r.server.accountClient.PostAccount(ctx, in.Name, email)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	return &Account{
-		ID:   a.ID,
-		Name: a.Name,
-	}, nil
+	return toAccount(*a), nil
+}
+
+func (r *mutationResolver) RequestEmailVerification(ctx context.Context, accountID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := r.server.accountClient.RequestEmailVerification(ctx, accountID); err != nil {
+		log.Println(err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *mutationResolver) VerifyEmail(ctx context.Context, token string) (*Account, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, err := r.server.accountClient.VerifyEmail(ctx, token)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toAccount(*a), nil
 }
 
 func (r *mutationResolver) CreateProduct(ctx context.Context, in ProductInput) (*Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	p, err := r.server.catalogClient.PostProduct(ctx, in.Name, in.Description, in.Price)
+	categoryID := ""
+	if in.CategoryID != nil {
+		categoryID = *in.CategoryID
+	}
+	stock := int64(0)
+	if in.Stock != nil {
+		stock = int64(*in.Stock)
+	}
+	p, err := r.server.catalogClient.PostProduct(ctx, in.Name, in.Description, in.Price, stock, categoryID, in.Tags)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	return &Product{
-		ID:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-	}, nil
+	return toProduct(*p, localeFromContext(ctx)), nil
+}
+
+func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, in ProductUpdateInput) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	changedBy := ""
+	if in.ChangedBy != nil {
+		changedBy = *in.ChangedBy
+	}
+	p, err := r.server.catalogClient.UpdateProduct(ctx, id, catalog.ProductUpdate{
+		Name:        in.Name,
+		Description: in.Description,
+		Price:       in.Price,
+	}, changedBy)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toProduct(*p, localeFromContext(ctx)), nil
+}
+
+func (r *mutationResolver) ArchiveProduct(ctx context.Context, id string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	p, err := r.server.catalogClient.ArchiveProduct(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toProduct(*p, localeFromContext(ctx)), nil
+}
+
+func (r *mutationResolver) AddVariant(ctx context.Context, productID string, in VariantInput) (*Product, error) {
+	return r.putVariant(ctx, productID, "", in)
+}
+
+func (r *mutationResolver) UpdateVariant(ctx context.Context, productID string, id string, in VariantInput) (*Product, error) {
+	return r.putVariant(ctx, productID, id, in)
+}
+
+func (r *mutationResolver) putVariant(ctx context.Context, productID string, id string, in VariantInput) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	variant := catalog.Variant{
+		ID:      id,
+		SKU:     in.Sku,
+		Options: []catalog.VariantOption{},
+		Price:   in.Price,
+	}
+	for _, o := range in.Options {
+		variant.Options = append(variant.Options, catalog.VariantOption{Name: o.Name, Value: o.Value})
+	}
+	if in.Stock != nil {
+		variant.Stock = int64(*in.Stock)
+	}
+	p, err := r.server.catalogClient.PutVariant(ctx, productID, variant, stringValue(in.ChangedBy))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toProduct(*p, localeFromContext(ctx)), nil
+}
+
+func (r *mutationResolver) DeleteVariant(ctx context.Context, productID string, id string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	p, err := r.server.catalogClient.DeleteVariant(ctx, productID, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toProduct(*p, localeFromContext(ctx)), nil
+}
+
+func (r *mutationResolver) CategorizeProduct(ctx context.Context, productID string, categoryID *string, tags []string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	c := ""
+	if categoryID != nil {
+		c = *categoryID
+	}
+	p, err := r.server.catalogClient.CategorizeProduct(ctx, productID, c, tags)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toProduct(*p, localeFromContext(ctx)), nil
+}
+
+func (r *mutationResolver) TranslateProduct(ctx context.Context, productID string, locale string, in ProductTranslationInput) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	name, description := "", ""
+	if in.Name != nil {
+		name = *in.Name
+	}
+	if in.Description != nil {
+		description = *in.Description
+	}
+	p, err := r.server.catalogClient.TranslateProduct(ctx, productID, locale, name, description)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toProduct(*p, locale), nil
+}
+
+func (r *mutationResolver) UploadProductImage(ctx context.Context, productID string, file graphql.Upload, altText *string, position *int) (*Product, error) {
+	// Uploads get longer than other mutations, as the catalog also makes a thumbnail of the image
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if file.Size > catalog.MaxImageSize {
+		return nil, catalog.ErrInvalidImage
+	}
+	alt := ""
+	if altText != nil {
+		alt = *altText
+	}
+	p, err := r.server.catalogClient.UploadProductImage(ctx, productID, file.File, alt, position)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toProduct(*p, localeFromContext(ctx)), nil
+}
+
+func (r *mutationResolver) UpdateProductImage(ctx context.Context, productID string, imageID string, altText *string, position *int) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	p, err := r.server.catalogClient.UpdateProductImage(ctx, productID, imageID, catalog.ImageUpdate{AltText: altText, Position: position})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toProduct(*p, localeFromContext(ctx)), nil
+}
+
+func (r *mutationResolver) DeleteProductImage(ctx context.Context, productID string, imageID string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	p, err := r.server.catalogClient.DeleteProductImage(ctx, productID, imageID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toProduct(*p, localeFromContext(ctx)), nil
+}
+
+func (r *mutationResolver) CreateCategory(ctx context.Context, in CategoryInput) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	parentID := ""
+	if in.ParentID != nil {
+		parentID = *in.ParentID
+	}
+	c, err := r.server.catalogClient.PostCategory(ctx, in.Name, parentID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toCategory(*c), nil
+}
+
+func (r *mutationResolver) DeleteCategory(ctx context.Context, id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := r.server.catalogClient.DeleteCategory(ctx, id); err != nil {
+		log.Println(err)
+		return false, err
+	}
+	return true, nil
 }
 
 func (r *mutationResolver) CreateOrder(ctx context.Context, in OrderInput) (*Order, error) {
@@ -56,24 +273,298 @@ func (r *mutationResolver) CreateOrder(ctx context.Context, in OrderInput) (*Ord
 	defer cancel()
 
 	var products []order.OrderedProduct
-	for _, p := range in.Products {
+	invalid := &order.ValidationError{}
+	for i, p := range in.Products {
 		if p.Quantity <= 0 {
-			return nil, ErrInvalidParameter
+			invalid.Violations = append(invalid.Violations, order.FieldViolation{
+				Field:       fmt.Sprintf("products[%d].quantity", i),
+				Description: "quantity must be positive",
+			})
 		}
 		products = append(products, order.OrderedProduct{
-			ID:       p.ID,
-			Quantity: uint32(p.Quantity),
+			ID:        p.ID,
+			VariantID: stringValue(p.VariantID),
+			Quantity:  uint32(max(p.Quantity, 0)),
 		})
 	}
+	if len(invalid.Violations) > 0 {
+		return nil, orderInputErrors(ctx, invalid)
+	}
 	o, err := r.server.orderClient.PostOrder(ctx, in.AccountID, products)
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return nil, orderInputErrors(ctx, err)
 	}
 
 	return &Order{
 		ID:         o.ID,
+		AccountID:  o.AccountID,
 		CreatedAt:  o.CreatedAt,
 		TotalPrice: o.TotalPrice,
+		Status:     OrderStatus(o.Status),
 	}, nil
 }
+
+// orderInputErrors reports each violation of a rejected order as an error of its own,
+// pointing at the offending field of the order argument, e.g. order.products[1].quantity.
+// Other errors are returned unchanged.
+func orderInputErrors(ctx context.Context, err error) error {
+	var invalid *order.ValidationError
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	for _, v := range invalid.Violations {
+		graphql.AddError(ctx, &gqlerror.Error{
+			Message: v.Description,
+			Extensions: map[string]interface{}{
+				"code":  "INVALID_ARGUMENT",
+				"field": "order." + v.Field,
+			},
+		})
+	}
+	return nil
+}
+
+func (r *mutationResolver) CreateGuestOrder(ctx context.Context, in GuestOrderInput) (*GuestOrderResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var products []order.OrderedProduct
+	invalid := &order.ValidationError{}
+	for i, p := range in.Products {
+		if p.Quantity <= 0 {
+			invalid.Violations = append(invalid.Violations, order.FieldViolation{
+				Field:       fmt.Sprintf("products[%d].quantity", i),
+				Description: "quantity must be positive",
+			})
+		}
+		products = append(products, order.OrderedProduct{
+			ID:        p.ID,
+			VariantID: stringValue(p.VariantID),
+			Quantity:  uint32(max(p.Quantity, 0)),
+		})
+	}
+	if len(invalid.Violations) > 0 {
+		return nil, orderInputErrors(ctx, invalid)
+	}
+	o, token, err := r.server.orderClient.PostGuestOrder(ctx, in.Email, in.ShippingAddress.toAddress(), products)
+	if err != nil {
+		log.Println(err)
+		return nil, orderInputErrors(ctx, err)
+	}
+
+	return &GuestOrderResult{
+		Order: toOrder(*o),
+		Token: token,
+	}, nil
+}
+
+func (a AddressInput) toAddress() order.Address {
+	address := order.Address{
+		Name:       a.Name,
+		Line1:      a.Line1,
+		City:       a.City,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+	if a.Line2 != nil {
+		address.Line2 = *a.Line2
+	}
+	if a.Region != nil {
+		address.Region = *a.Region
+	}
+	return address
+}
+
+func (r *mutationResolver) ClaimGuestOrder(ctx context.Context, token string, emailToken string) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	o, err := r.server.orderClient.ClaimGuestOrder(ctx, token, emailToken)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toOrder(*o), nil
+}
+
+func (r *mutationResolver) Reorder(ctx context.Context, orderID string) (*ReorderResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	o, skipped, err := r.server.orderClient.ReorderOrder(ctx, orderID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	result := &ReorderResult{SkippedLines: []*SkippedOrderLine{}}
+	if o != nil {
+		result.Order = toOrder(*o)
+	}
+	for _, l := range skipped {
+		result.SkippedLines = append(result.SkippedLines, &SkippedOrderLine{
+			ProductID: l.ProductID,
+			VariantID: optionalString(l.VariantID),
+			Quantity:  int(l.Quantity),
+			Reason:    l.Reason,
+		})
+	}
+	return result, nil
+}
+
+func (r *mutationResolver) RequestReturn(ctx context.Context, in ReturnInput) (*Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var products []order.ReturnedProduct
+	for _, p := range in.Products {
+		if p.Quantity <= 0 {
+			return nil, ErrInvalidParameter
+		}
+		products = append(products, order.ReturnedProduct{
+			ID:        p.ID,
+			VariantID: stringValue(p.VariantID),
+			Quantity:  uint32(p.Quantity),
+		})
+	}
+	ret, err := r.server.orderClient.RequestReturn(ctx, in.OrderID, in.Reason, products)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toReturn(ret), nil
+}
+
+func (r *mutationResolver) ApproveReturn(ctx context.Context, id string) (*Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	ret, err := r.server.orderClient.ApproveReturn(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toReturn(ret), nil
+}
+
+func (r *mutationResolver) RejectReturn(ctx context.Context, id string, note *string) (*Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	n := ""
+	if note != nil {
+		n = *note
+	}
+	ret, err := r.server.orderClient.RejectReturn(ctx, id, n)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toReturn(ret), nil
+}
+
+func (r *mutationResolver) ReceiveReturn(ctx context.Context, id string) (*Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	ret, err := r.server.orderClient.ReceiveReturn(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toReturn(ret), nil
+}
+
+func (r *mutationResolver) CreateShipment(ctx context.Context, in ShipmentInput) (*Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var products []order.ShippedProduct
+	for _, p := range in.Products {
+		if p.Quantity <= 0 {
+			return nil, ErrInvalidParameter
+		}
+		products = append(products, order.ShippedProduct{
+			ID:        p.ID,
+			VariantID: stringValue(p.VariantID),
+			Quantity:  uint32(p.Quantity),
+		})
+	}
+	s, _, err := r.server.orderClient.CreateShipment(ctx, in.OrderID, in.Carrier, in.TrackingNumber, in.ShippedAt, products)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toShipment(s), nil
+}
+
+func (r *mutationResolver) UpdateShipment(ctx context.Context, id string, in ShipmentUpdateInput) (*Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	update := order.ShipmentUpdate{
+		ShippedAt:   in.ShippedAt,
+		DeliveredAt: in.DeliveredAt,
+	}
+	if in.Carrier != nil {
+		update.Carrier = *in.Carrier
+	}
+	if in.TrackingNumber != nil {
+		update.TrackingNumber = *in.TrackingNumber
+	}
+	s, _, err := r.server.orderClient.UpdateShipment(ctx, id, update)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toShipment(s), nil
+}
+
+func (r *mutationResolver) CreateReview(ctx context.Context, in ReviewInput) (*Review, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if in.Rating < 1 || in.Rating > 5 {
+		return nil, ErrInvalidParameter
+	}
+	rev, err := r.server.reviewClient.PostReview(ctx, in.AccountID, in.ProductID, uint32(in.Rating), stringValue(in.Text))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toReview(*rev), nil
+}
+
+func (r *mutationResolver) ApproveReview(ctx context.Context, id string) (*Review, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rev, err := r.server.reviewClient.ApproveReview(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toReview(*rev), nil
+}
+
+func (r *mutationResolver) RejectReview(ctx context.Context, id string, note *string) (*Review, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rev, err := r.server.reviewClient.RejectReview(ctx, id, stringValue(note))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toReview(*rev), nil
+}
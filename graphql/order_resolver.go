@@ -0,0 +1,160 @@
+package graphql
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/order"
+)
+
+type orderResolver struct {
+	server *Server
+}
+
+func (r *orderResolver) Returns(ctx context.Context, obj *Order) ([]*Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	returnList, err := r.server.orderClient.GetReturnsForOrder(ctx, obj.ID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	returns := []*Return{}
+	for i := range returnList {
+		returns = append(returns, toReturn(&returnList[i]))
+	}
+	return returns, nil
+}
+
+func (r *orderResolver) Shipments(ctx context.Context, obj *Order) ([]*Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	shipmentList, err := r.server.orderClient.GetShipmentsForOrder(ctx, obj.ID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	shipments := []*Shipment{}
+	for i := range shipmentList {
+		shipments = append(shipments, toShipment(&shipmentList[i]))
+	}
+	return shipments, nil
+}
+
+func toOrder(o order.Order) *Order {
+	var products []*OrderedProducts
+	for _, p := range o.Products {
+		options := []*VariantOption{}
+		for _, o := range p.Options {
+			options = append(options, &VariantOption{Name: o.Name, Value: o.Value})
+		}
+		products = append(products, &OrderedProducts{
+			ID:          p.ID,
+			VariantID:   optionalString(p.VariantID),
+			Sku:         optionalString(p.SKU),
+			Options:     options,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       p.Price,
+			Quantity:    int(p.Quantity),
+		})
+	}
+	return &Order{
+		ID:              o.ID,
+		AccountID:       o.AccountID,
+		CreatedAt:       o.CreatedAt,
+		TotalPrice:      o.TotalPrice,
+		Status:          OrderStatus(o.Status),
+		Products:        products,
+		ShippingAddress: toAddress(o.ShippingAddress),
+	}
+}
+
+func toAddress(a *order.Address) *Address {
+	if a == nil {
+		return nil
+	}
+	return &Address{
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+func toReturn(r *order.Return) *Return {
+	var products []*ReturnedProduct
+	for _, p := range r.Products {
+		products = append(products, &ReturnedProduct{
+			ID:        p.ID,
+			VariantID: optionalString(p.VariantID),
+			Quantity:  int(p.Quantity),
+			Price:     p.Price,
+			Restocked: p.Restocked,
+		})
+	}
+
+	ret := &Return{
+		ID:        r.ID,
+		OrderID:   r.OrderID,
+		Status:    ReturnStatus(r.Status),
+		Reason:    r.Reason,
+		Note:      r.Note,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+		Products:  products,
+	}
+	if r.Refund != nil {
+		ret.Refund = &Refund{
+			ID:        r.Refund.ID,
+			Amount:    r.Refund.Amount,
+			CreatedAt: r.Refund.CreatedAt,
+		}
+	}
+	return ret
+}
+
+func toShipment(s *order.Shipment) *Shipment {
+	var products []*ShippedProduct
+	for _, p := range s.Products {
+		products = append(products, &ShippedProduct{
+			ID:        p.ID,
+			VariantID: optionalString(p.VariantID),
+			Quantity:  int(p.Quantity),
+		})
+	}
+
+	return &Shipment{
+		ID:             s.ID,
+		OrderID:        s.OrderID,
+		Carrier:        s.Carrier,
+		TrackingNumber: s.TrackingNumber,
+		ShippedAt:      s.ShippedAt,
+		DeliveredAt:    s.DeliveredAt,
+		CreatedAt:      s.CreatedAt,
+		Products:       products,
+	}
+}
+
+// Empty strings, e.g. the variant of a line of a product without variants, are returned as null
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
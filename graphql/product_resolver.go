@@ -0,0 +1,181 @@
+package graphql
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"github.com/PranavTrip/go-grpc-graphql-ms/review"
+)
+
+type productResolver struct {
+	server *Server
+}
+
+func (r *productResolver) FrequentlyBoughtWith(ctx context.Context, obj *Product, limit *int) ([]*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	l := uint64(0)
+	if limit != nil {
+		l = uint64(*limit)
+	}
+	productList, err := r.server.orderClient.RecommendProducts(ctx, obj.ID, "", l)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	// Recommendations carry the product's own name and description, which are in the default locale
+	products := []*Product{}
+	for _, p := range productList {
+		products = append(products, &Product{
+			ID:           p.ID,
+			Name:         p.Name,
+			Description:  p.Description,
+			Locale:       catalog.DefaultLocale,
+			Translations: []*ProductTranslation{},
+			Images:       []*ProductImage{},
+			Price:        p.Price,
+		})
+	}
+	return products, nil
+}
+
+func (r *productResolver) PriceHistory(ctx context.Context, obj *Product, pagination *PaginationInput) ([]*PriceChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	skip, take := uint64(0), uint64(0)
+	if pagination != nil {
+		skip, take = pagination.bounds()
+	}
+	changeList, err := r.server.catalogClient.GetPriceHistory(ctx, obj.ID, skip, take)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	changes := []*PriceChange{}
+	for _, c := range changeList {
+		changes = append(changes, &PriceChange{
+			VariantID: optionalString(c.VariantID),
+			OldPrice:  c.OldPrice,
+			NewPrice:  c.NewPrice,
+			ChangedBy: c.ChangedBy,
+			ChangedAt: c.ChangedAt,
+		})
+	}
+	return changes, nil
+}
+
+func (r *productResolver) Reviews(ctx context.Context, obj *Product, pagination *ReviewPaginationInput) (*ReviewConnection, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	after, take := "", uint64(0)
+	if pagination != nil {
+		after, take = pagination.bounds()
+	}
+	filter := review.ReviewFilter{ProductID: obj.ID, Status: review.ReviewStatusApproved}
+	reviewList, next, total, err := r.server.reviewClient.GetReviews(ctx, filter, after, take)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toReviewConnection(reviewList, next, total), nil
+}
+
+// toProduct converts a product with its name and description in the given locale
+func toProduct(p catalog.Product, locale string) *Product {
+	localized := p.Localized(locale)
+	product := &Product{
+		ID:           p.ID,
+		Name:         localized.Name,
+		Description:  localized.Description,
+		Locale:       locale,
+		Translations: toProductTranslations(p),
+		Price:        p.Price,
+		Stock:        int(p.Stock),
+		CategoryPath: p.CategoryPath,
+		Tags:         p.Tags,
+		Archived:     p.Archived,
+		Variants:     []*Variant{},
+		Images:       []*ProductImage{},
+		RatingCount:  int(p.RatingCount),
+	}
+	if p.RatingCount > 0 {
+		product.AverageRating = &p.AverageRating
+	}
+	for _, v := range p.Variants {
+		product.Variants = append(product.Variants, toVariant(p, v))
+	}
+	for _, img := range p.Images {
+		product.Images = append(product.Images, &ProductImage{
+			ID:           img.ID,
+			URL:          img.URL,
+			ThumbnailURL: img.ThumbnailURL,
+			AltText:      img.AltText,
+			Width:        img.Width,
+			Height:       img.Height,
+		})
+	}
+	if p.CategoryID != "" {
+		product.CategoryID = &p.CategoryID
+	}
+	if !p.CreatedAt.IsZero() {
+		product.CreatedAt = &p.CreatedAt
+	}
+	return product
+}
+
+func toVariant(p catalog.Product, v catalog.Variant) *Variant {
+	variant := &Variant{
+		ID:            v.ID,
+		Sku:           v.SKU,
+		Options:       []*VariantOption{},
+		Price:         p.VariantPrice(v),
+		PriceOverride: v.Price,
+		Stock:         int(v.Stock),
+	}
+	for _, o := range v.Options {
+		variant.Options = append(variant.Options, &VariantOption{Name: o.Name, Value: o.Value})
+	}
+	return variant
+}
+
+func toCategory(c catalog.Category) *Category {
+	category := &Category{
+		ID:   c.ID,
+		Name: c.Name,
+		Path: c.Path,
+	}
+	if c.ParentID != "" {
+		category.ParentID = &c.ParentID
+	}
+	return category
+}
+
+func toFacetCounts(counts []catalog.FacetCount) []*FacetCount {
+	facetCounts := []*FacetCount{}
+	for _, c := range counts {
+		facetCounts = append(facetCounts, &FacetCount{Value: c.Value, Count: int(c.Count)})
+	}
+	return facetCounts
+}
+
+func (f ProductFilter) toFilter() catalog.ProductFilter {
+	filter := catalog.ProductFilter{
+		Tags:     f.Tags,
+		MinPrice: f.MinPrice,
+		MaxPrice: f.MaxPrice,
+	}
+	if f.CategoryID != nil {
+		filter.CategoryID = *f.CategoryID
+	}
+	if f.InStock != nil {
+		filter.InStock = *f.InStock
+	}
+	return filter
+}
@@ -1,9 +1,13 @@
-package main
+package graphql
 
 import (
 	"context"
 	"log"
 	"time"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"github.com/PranavTrip/go-grpc-graphql-ms/order"
+	"github.com/PranavTrip/go-grpc-graphql-ms/review"
 )
 
 type queryResolver struct {
@@ -21,10 +25,7 @@ func (r *queryResolver) Accounts(ctx context.Context, pagination *PaginationInpu
 			log.Println(err)
 			return nil, err
 		}
-		return []*Account{{
-			ID:   r.ID,
-			Name: r.Name,
-		}}, nil
+		return []*Account{toAccount(*r)}, nil
 	}
 
 	skip, take := uint64(0), uint64(0)
@@ -40,19 +41,33 @@ func (r *queryResolver) Accounts(ctx context.Context, pagination *PaginationInpu
 
 	var accounts []*Account
 	for _, a := range accountList {
-		account := &Account{
-			ID:   a.ID,
-			Name: a.Name,
-		}
-		accounts = append(accounts, account)
+		accounts = append(accounts, toAccount(a))
 	}
 
 	return accounts, nil
 }
-func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInput, query *string, id *string) ([]*Product, error) {
+
+func (r *queryResolver) GuestOrder(ctx context.Context, token string) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	o, err := r.server.orderClient.GetGuestOrder(ctx, token)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toOrder(*o), nil
+}
+
+func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInput, query *string, id *string, filter *ProductFilter, sort *ProductSort, locale *string, accountID *string) (*ProductSearchResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	l, err := requestedLocale(ctx, locale)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get single
 	if id != nil {
 		r, err := r.server.catalogClient.GetProduct(ctx, *id)
@@ -60,44 +75,235 @@ func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInpu
 			log.Println(err)
 			return nil, err
 		}
-		return []*Product{{
-			ID:          r.ID,
-			Name:        r.Name,
-			Description: r.Description,
-			Price:       r.Price,
-		}}, nil
+		return &ProductSearchResult{
+			Products:   []*Product{toProduct(*r, l)},
+			TotalCount: 1,
+			Facets:     &ProductFacets{Categories: []*FacetCount{}, Tags: []*FacetCount{}},
+		}, nil
 	}
 
-	skip, take := uint64(0), uint64(0)
+	q := catalog.ProductQuery{Locale: l}
 	if pagination != nil {
-		skip, take = pagination.bounds()
+		q.Skip, q.Take = pagination.bounds()
 	}
-
-	q := ""
 	if query != nil {
-		q = *query
+		q.Query = *query
+	}
+	if filter != nil {
+		q.Filter = filter.toFilter()
+	}
+	if sort != nil {
+		q.Sort = catalog.ProductSort(*sort)
 	}
-	productList, err := r.server.catalogClient.GetProducts(ctx, nil, skip, take, q)
+	if accountID != nil {
+		q.AccountID = *accountID
+	}
+	res, err := r.server.catalogClient.SearchProducts(ctx, q)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
 	var products []*Product
-	for _, a := range productList {
-		products = append(products,
-			&Product{
-				ID:          a.ID,
-				Name:        a.Name,
-				Description: a.Description,
-				Price:       a.Price,
-			},
-		)
+	for _, a := range res.Products {
+		products = append(products, toProduct(a, l))
+	}
+
+	return &ProductSearchResult{
+		Products:   products,
+		TotalCount: int(res.Total),
+		Facets: &ProductFacets{
+			Categories: toFacetCounts(res.Facets.Categories),
+			Tags:       toFacetCounts(res.Facets.Tags),
+		},
+	}, nil
+}
+
+func (r *queryResolver) ProductSuggestions(ctx context.Context, prefix string, limit *int) ([]*ProductSuggestion, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	size := uint64(0)
+	if limit != nil && *limit > 0 {
+		size = uint64(*limit)
+	}
+	suggestionList, err := r.server.catalogClient.SuggestProducts(ctx, prefix, size)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	suggestions := []*ProductSuggestion{}
+	for _, s := range suggestionList {
+		suggestions = append(suggestions, &ProductSuggestion{
+			ID:    s.ID,
+			Name:  s.Name,
+			Score: s.Score,
+		})
+	}
+	return suggestions, nil
+}
+
+func (r *queryResolver) Categories(ctx context.Context) ([]*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	categoryList, err := r.server.catalogClient.GetCategories(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	categories := []*Category{}
+	for _, c := range categoryList {
+		categories = append(categories, toCategory(c))
 	}
+	return categories, nil
+}
+
+func (r *queryResolver) Orders(ctx context.Context, filter *OrderSearchFilter, sort *OrderSort, pagination *OrderPaginationInput) (*OrderSearchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
 
+	f := order.OrderFilter{}
+	if filter != nil {
+		f = filter.toFilter()
+	}
+	orderSort := order.OrderSort("")
+	if sort != nil {
+		orderSort = order.OrderSort(*sort)
+	}
+	after, take := "", uint64(0)
+	if pagination != nil {
+		var err error
+		if after, take, err = pagination.bounds(); err != nil {
+			return nil, err
+		}
+	}
+
+	orderList, next, total, err := r.server.orderClient.SearchOrders(ctx, f, orderSort, after, take)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	result := &OrderSearchResult{
+		Orders:     []*Order{},
+		TotalCount: int(total),
+	}
+	for _, o := range orderList {
+		result.Orders = append(result.Orders, toOrder(o))
+	}
+	if next != "" {
+		result.NextCursor = &next
+	}
+	return result, nil
+}
+
+func (r *queryResolver) SalesReport(ctx context.Context, from *time.Time, to *time.Time, interval *SalesInterval) (*SalesReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	salesInterval := order.SalesInterval("")
+	if interval != nil {
+		salesInterval = order.SalesInterval(*interval)
+	}
+	report, err := r.server.orderClient.GetSalesReport(ctx, from, to, salesInterval)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	result := &SalesReport{
+		Buckets:           []*SalesBucket{},
+		Revenue:           report.Revenue,
+		OrderCount:        int(report.OrderCount),
+		AverageOrderValue: report.AverageOrderValue,
+	}
+	for _, b := range report.Buckets {
+		result.Buckets = append(result.Buckets, &SalesBucket{
+			Start:      b.Start,
+			Revenue:    b.Revenue,
+			OrderCount: int(b.OrderCount),
+		})
+	}
+	return result, nil
+}
+
+func (r *queryResolver) TopProducts(ctx context.Context, from *time.Time, to *time.Time, sortBy *ProductSalesSort, limit *int) ([]*ProductSales, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	salesSort := order.ProductSalesSort("")
+	if sortBy != nil {
+		salesSort = order.ProductSalesSort(*sortBy)
+	}
+	l := uint64(0)
+	if limit != nil {
+		l = uint64(*limit)
+	}
+	productList, err := r.server.orderClient.GetTopProducts(ctx, from, to, salesSort, l)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	products := []*ProductSales{}
+	for _, p := range productList {
+		products = append(products, &ProductSales{
+			ProductID: p.ID,
+			Name:      p.Name,
+			Quantity:  int(p.Quantity),
+			Revenue:   p.Revenue,
+		})
+	}
 	return products, nil
 }
 
+// Reviews lists reviews in any status, so moderators can work through the pending ones
+func (r *queryResolver) Reviews(ctx context.Context, productID *string, status *ReviewStatus, pagination *ReviewPaginationInput) (*ReviewConnection, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	filter := review.ReviewFilter{ProductID: stringValue(productID)}
+	if status != nil {
+		filter.Status = review.ReviewStatus(*status)
+	}
+	after, take := "", uint64(0)
+	if pagination != nil {
+		after, take = pagination.bounds()
+	}
+
+	reviewList, next, total, err := r.server.reviewClient.GetReviews(ctx, filter, after, take)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return toReviewConnection(reviewList, next, total), nil
+}
+
+func (f OrderSearchFilter) toFilter() order.OrderFilter {
+	filter := order.OrderFilter{
+		CreatedAfter:  f.CreatedAfter,
+		CreatedBefore: f.CreatedBefore,
+		MinTotal:      f.MinTotal,
+		MaxTotal:      f.MaxTotal,
+	}
+	if f.IDPrefix != nil {
+		filter.IDPrefix = *f.IDPrefix
+	}
+	if f.AccountID != nil {
+		filter.AccountID = *f.AccountID
+	}
+	if f.ProductID != nil {
+		filter.ProductID = *f.ProductID
+	}
+	for _, status := range f.Statuses {
+		filter.Statuses = append(filter.Statuses, order.OrderStatus(status))
+	}
+	return filter
+}
+
 func (p PaginationInput) bounds() (uint64, uint64) {
 	skipValue := uint64(0)
 	takeValue := uint64(100)
@@ -0,0 +1,44 @@
+package graphql
+
+import "github.com/PranavTrip/go-grpc-graphql-ms/review"
+
+func (p ReviewPaginationInput) bounds() (string, uint64) {
+	afterValue := ""
+	takeValue := uint64(100)
+	if p.After != nil {
+		afterValue = *p.After
+	}
+	if p.Take != nil {
+		takeValue = uint64(*p.Take)
+	}
+	return afterValue, takeValue
+}
+
+func toReview(r review.Review) *Review {
+	return &Review{
+		ID:        r.ID,
+		ProductID: r.ProductID,
+		AccountID: r.AccountID,
+		Rating:    int(r.Rating),
+		Text:      r.Text,
+		Status:    ReviewStatus(r.Status),
+		Note:      r.Note,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// toReviewConnection wraps a page of reviews with the cursor of the next page, which is only set if there is one
+func toReviewConnection(reviewList []review.Review, next string, total uint64) *ReviewConnection {
+	connection := &ReviewConnection{
+		Reviews:    []*Review{},
+		TotalCount: int(total),
+	}
+	for _, r := range reviewList {
+		connection.Reviews = append(connection.Reviews, toReview(r))
+	}
+	if next != "" {
+		connection.NextCursor = &next
+	}
+	return connection
+}
@@ -15,14 +15,15 @@ type Client struct {
 	service pb.OrderServiceClient
 }
 
-func NewClient(url string) (*Client, error) {
+// NewClient connects to the order service at url, dialing it with opts on top of the defaults
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
 
 	// Checks if url is not empty
 	if url == "" {
 		return nil, fmt.Errorf("grpc target url cannot be empty")
 	}
 	// Creates a connection using grpc.Dial()
-	conn, err := grpc.Dial(url, grpc.WithInsecure())
+	conn, err := grpc.Dial(url, append([]grpc.DialOption{grpc.WithInsecure()}, opts...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +50,7 @@ func (c *Client) PostOrder(
 	for _, p := range products {
 		protoProducts = append(protoProducts, &pb.PostOrderRequest_OrderProduct{
 			ProductId: p.ID,
+			VariantId: p.VariantID,
 			Quantity:  p.Quantity,
 		})
 	}
@@ -62,7 +64,7 @@ func (c *Client) PostOrder(
 		},
 	)
 	if err != nil {
-		return nil, err
+		return nil, validationErrorFromStatus(err)
 	}
 
 	// New order that is created
@@ -80,50 +82,450 @@ func (c *Client) PostOrder(
 		CreatedAt:  newOrderCreatedAt,
 		TotalPrice: newOrder.TotalPrice,
 		AccountID:  newOrder.AccountId,
+		Status:     OrderStatus(newOrder.Status),
 		Products:   products,
 	}, nil
 }
 
-func (c *Client) GetOrdersForAccount(ctx context.Context, accountID string) ([]Order, error) {
+// PostGuestOrder places an order for a guest identified by their email address, shipped to the given address.
+// It returns the order with the signed token the guest can look it up with.
+func (c *Client) PostGuestOrder(ctx context.Context, email string, shippingAddress Address, products []OrderedProduct) (*Order, string, error) {
+	protoProducts := []*pb.PostOrderRequest_OrderProduct{}
+	for _, p := range products {
+		protoProducts = append(protoProducts, &pb.PostOrderRequest_OrderProduct{
+			ProductId: p.ID,
+			VariantId: p.VariantID,
+			Quantity:  p.Quantity,
+		})
+	}
+
+	r, err := c.service.PostGuestOrder(ctx, &pb.PostGuestOrderRequest{
+		Email:           email,
+		ShippingAddress: addressToProto(&shippingAddress),
+		Products:        protoProducts,
+	})
+	if err != nil {
+		return nil, "", validationErrorFromStatus(err)
+	}
+	order := orderFromProto(r.Order)
+	return &order, r.Token, nil
+}
+
+// GetGuestOrder gets the order a guest token was issued for
+func (c *Client) GetGuestOrder(ctx context.Context, token string) (*Order, error) {
+	r, err := c.service.GetGuestOrder(ctx, &pb.GetGuestOrderRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	order := orderFromProto(r.Order)
+	return &order, nil
+}
+
+// ClaimGuestOrder moves the guest order a token was issued to into the registered account an email
+// verification token was sent to, as long as both have the same email address
+func (c *Client) ClaimGuestOrder(ctx context.Context, token string, emailToken string) (*Order, error) {
+	r, err := c.service.ClaimGuestOrder(ctx, &pb.ClaimGuestOrderRequest{
+		Token:      token,
+		EmailToken: emailToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	order := orderFromProto(r.Order)
+	return &order, nil
+}
+
+// ReorderOrder places a new order with the products of an earlier one at their current prices.
+// Lines that could not be carried over are returned alongside; the order is nil if none could.
+func (c *Client) ReorderOrder(ctx context.Context, orderID string) (*Order, []SkippedLine, error) {
+	r, err := c.service.ReorderOrder(ctx, &pb.ReorderOrderRequest{OrderId: orderID})
+	if err != nil {
+		return nil, nil, validationErrorFromStatus(err)
+	}
+
+	skipped := []SkippedLine{}
+	for _, l := range r.Skipped {
+		skipped = append(skipped, SkippedLine{
+			ProductID: l.ProductId,
+			VariantID: l.VariantId,
+			Quantity:  l.Quantity,
+			Reason:    l.Reason,
+		})
+	}
+	if r.Order == nil {
+		return nil, skipped, nil
+	}
+	o := orderFromProto(r.Order)
+	return &o, skipped, nil
+}
+
+// GetOrdersForAccount returns a page of the account's orders along with the cursor for the next page,
+// which is empty on the last page
+func (c *Client) GetOrdersForAccount(ctx context.Context, accountID string, filter OrderFilter, sort OrderSort, after string, take uint64) ([]Order, string, error) {
 
 	// Calls the function to Get orders for an account
 	r, err := c.service.GetOrdersForAccount(ctx, &pb.GetOrdersForAccountRequest{
 		AccountId: accountID,
+		Filter:    filterToProto(filter),
+		Sort:      string(sort),
+		After:     after,
+		Take:      take,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, "", err
+	}
+
+	return ordersFromProto(r.Orders), r.NextCursor, nil
+}
+
+// SearchOrders looks through the orders of all accounts, returning a page of them together with the
+// cursor for the next page and the number of orders matching the filter
+func (c *Client) SearchOrders(ctx context.Context, filter OrderFilter, sort OrderSort, after string, take uint64) ([]Order, string, uint64, error) {
+	r, err := c.service.SearchOrders(ctx, &pb.SearchOrdersRequest{
+		Filter: filterToProto(filter),
+		Sort:   string(sort),
+		After:  after,
+		Take:   take,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, "", 0, err
+	}
+	return ordersFromProto(r.Orders), r.NextCursor, r.TotalCount, nil
+}
+
+func (c *Client) GetSalesReport(ctx context.Context, from *time.Time, to *time.Time, interval SalesInterval) (*SalesReport, error) {
+	r, err := c.service.GetSalesReport(ctx, &pb.GetSalesReportRequest{
+		From:     timeToProto(from),
+		To:       timeToProto(to),
+		Interval: string(interval),
 	})
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
+	report := &SalesReport{
+		Buckets:           []SalesBucket{},
+		Revenue:           r.Revenue,
+		OrderCount:        r.OrderCount,
+		AverageOrderValue: r.AverageOrderValue,
+	}
+	for _, b := range r.Buckets {
+		bucket := SalesBucket{
+			Revenue:    b.Revenue,
+			OrderCount: b.OrderCount,
+		}
+		bucket.Start.UnmarshalBinary(b.Start)
+		report.Buckets = append(report.Buckets, bucket)
+	}
+	return report, nil
+}
+
+func (c *Client) GetTopProducts(ctx context.Context, from *time.Time, to *time.Time, sortBy ProductSalesSort, limit uint64) ([]ProductSales, error) {
+	r, err := c.service.GetTopProducts(ctx, &pb.GetTopProductsRequest{
+		From:   timeToProto(from),
+		To:     timeToProto(to),
+		SortBy: string(sortBy),
+		Limit:  limit,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	products := []ProductSales{}
+	for _, p := range r.Products {
+		products = append(products, ProductSales{
+			ID:       p.ProductId,
+			Name:     p.Name,
+			Quantity: p.Quantity,
+			Revenue:  p.Revenue,
+		})
+	}
+	return products, nil
+}
+
+// RecommendProducts returns the products frequently bought together with the product, or with what
+// the account has bought before; exactly one of productID and accountID must be given
+func (c *Client) RecommendProducts(ctx context.Context, productID string, accountID string, limit uint64) ([]RecommendedProduct, error) {
+	req := &pb.RecommendProductsRequest{Limit: limit}
+	if productID != "" {
+		req.Seed = &pb.RecommendProductsRequest_ProductId{ProductId: productID}
+	} else {
+		req.Seed = &pb.RecommendProductsRequest_AccountId{AccountId: accountID}
+	}
+	r, err := c.service.RecommendProducts(ctx, req)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	products := []RecommendedProduct{}
+	for _, p := range r.Products {
+		products = append(products, RecommendedProduct{
+			ID:          p.Id,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       p.Price,
+			Score:       p.Score,
+		})
+	}
+	return products, nil
+}
+
+func ordersFromProto(protoOrders []*pb.Order) []Order {
 	// create an empty slice to store the orders
 	orders := []Order{}
 
-	// Range over the orders and convert created_at from binary to time
-	for _, orderProto := range r.Orders {
-		newOrder := Order{
-			ID:         orderProto.Id,
-			TotalPrice: orderProto.TotalPrice,
-			AccountID:  orderProto.AccountId,
+	// Range over the orders and convert them one by one
+	for _, orderProto := range protoOrders {
+		orders = append(orders, orderFromProto(orderProto))
+	}
+	return orders
+}
+
+// Convert an order from protobuf, converting created_at from binary to time
+func orderFromProto(orderProto *pb.Order) Order {
+	newOrder := Order{
+		ID:              orderProto.Id,
+		TotalPrice:      orderProto.TotalPrice,
+		AccountID:       orderProto.AccountId,
+		Status:          OrderStatus(orderProto.Status),
+		ShippingAddress: addressFromProto(orderProto.ShippingAddress),
+	}
+	newOrder.CreatedAt = time.Time{}
+	newOrder.CreatedAt.UnmarshalBinary(orderProto.CreatedAt)
+
+	// Empty slice to store products in the order
+	products := []OrderedProduct{}
+
+	// Range over the products and append them in the slice above
+	for _, p := range orderProto.Products {
+		product := OrderedProduct{
+			ID:          p.Id,
+			VariantID:   p.VariantId,
+			SKU:         p.Sku,
+			Options:     []VariantOption{},
+			Quantity:    p.Quantity,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       p.Price,
 		}
-		newOrder.CreatedAt = time.Time{}
-		newOrder.CreatedAt.UnmarshalBinary(orderProto.CreatedAt)
-
-		// Empty slice to store products in the order
-		products := []OrderedProduct{}
-		
-		// Range over the products and append them in the slice above
-		for _, p := range orderProto.Products {
-			products = append(products, OrderedProduct{
-				ID:          p.Id,
-				Quantity:    p.Quantity,
-				Name:        p.Name,
-				Description: p.Description,
-				Price:       p.Price,
-			})
+		for _, o := range p.Options {
+			product.Options = append(product.Options, VariantOption{Name: o.Name, Value: o.Value})
 		}
-		newOrder.Products = products
+		products = append(products, product)
+	}
+	newOrder.Products = products
+	return newOrder
+}
 
-		orders = append(orders, newOrder)
+func filterToProto(filter OrderFilter) *pb.OrderFilter {
+	f := &pb.OrderFilter{
+		CreatedAfter:  timeToProto(filter.CreatedAfter),
+		CreatedBefore: timeToProto(filter.CreatedBefore),
+		MinTotal:      filter.MinTotal,
+		MaxTotal:      filter.MaxTotal,
+		IdPrefix:      filter.IDPrefix,
+		ProductId:     filter.ProductID,
+		AccountId:     filter.AccountID,
+	}
+	for _, status := range filter.Statuses {
+		f.Statuses = append(f.Statuses, string(status))
+	}
+	return f
+}
+
+func (c *Client) RequestReturn(ctx context.Context, orderID string, reason string, products []ReturnedProduct) (*Return, error) {
+	// Convert the lines to be returned to the protobuf format
+	protoProducts := []*pb.RequestReturnRequest_ReturnProduct{}
+	for _, p := range products {
+		protoProducts = append(protoProducts, &pb.RequestReturnRequest_ReturnProduct{
+			ProductId: p.ID,
+			VariantId: p.VariantID,
+			Quantity:  p.Quantity,
+		})
+	}
+
+	r, err := c.service.RequestReturn(ctx, &pb.RequestReturnRequest{
+		OrderId:  orderID,
+		Reason:   reason,
+		Products: protoProducts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return returnFromProto(r.Return), nil
+}
+
+func (c *Client) ApproveReturn(ctx context.Context, id string) (*Return, error) {
+	r, err := c.service.ApproveReturn(ctx, &pb.ApproveReturnRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return returnFromProto(r.Return), nil
+}
+
+func (c *Client) RejectReturn(ctx context.Context, id string, note string) (*Return, error) {
+	r, err := c.service.RejectReturn(ctx, &pb.RejectReturnRequest{Id: id, Note: note})
+	if err != nil {
+		return nil, err
+	}
+	return returnFromProto(r.Return), nil
+}
+
+func (c *Client) ReceiveReturn(ctx context.Context, id string) (*Return, error) {
+	r, err := c.service.ReceiveReturn(ctx, &pb.ReceiveReturnRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return returnFromProto(r.Return), nil
+}
+
+func (c *Client) GetReturnsForOrder(ctx context.Context, orderID string) ([]Return, error) {
+	r, err := c.service.GetReturnsForOrder(ctx, &pb.GetReturnsForOrderRequest{OrderId: orderID})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	returns := []Return{}
+	for _, rp := range r.Returns {
+		returns = append(returns, *returnFromProto(rp))
+	}
+	return returns, nil
+}
+
+// Convert a return from protobuf, unmarshalling its times from binary
+func returnFromProto(rp *pb.Return) *Return {
+	r := &Return{
+		ID:        rp.Id,
+		OrderID:   rp.OrderId,
+		AccountID: rp.AccountId,
+		Status:    ReturnStatus(rp.Status),
+		Reason:    rp.Reason,
+		Note:      rp.Note,
+		Products:  []ReturnedProduct{},
+	}
+	r.CreatedAt.UnmarshalBinary(rp.CreatedAt)
+	r.UpdatedAt.UnmarshalBinary(rp.UpdatedAt)
+	for _, p := range rp.Products {
+		r.Products = append(r.Products, ReturnedProduct{
+			ID:        p.Id,
+			VariantID: p.VariantId,
+			Quantity:  p.Quantity,
+			Price:     p.Price,
+			Restocked: p.Restocked,
+		})
+	}
+	if rp.Refund != nil {
+		r.Refund = &Refund{
+			ID:     rp.Refund.Id,
+			Amount: rp.Refund.Amount,
+		}
+		r.Refund.CreatedAt.UnmarshalBinary(rp.Refund.CreatedAt)
+	}
+	return r
+}
+
+func (c *Client) CreateShipment(ctx context.Context, orderID string, carrier string, trackingNumber string, shippedAt *time.Time, products []ShippedProduct) (*Shipment, OrderStatus, error) {
+	// Convert the shipped lines to the protobuf format
+	protoProducts := []*pb.Shipment_ShipmentProduct{}
+	for _, p := range products {
+		protoProducts = append(protoProducts, &pb.Shipment_ShipmentProduct{
+			ProductId: p.ID,
+			VariantId: p.VariantID,
+			Quantity:  p.Quantity,
+		})
+	}
+
+	r, err := c.service.CreateShipment(ctx, &pb.CreateShipmentRequest{
+		OrderId:        orderID,
+		Carrier:        carrier,
+		TrackingNumber: trackingNumber,
+		ShippedAt:      timeToProto(shippedAt),
+		Products:       protoProducts,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return shipmentFromProto(r.Shipment), OrderStatus(r.OrderStatus), nil
+}
+
+func (c *Client) UpdateShipment(ctx context.Context, id string, update ShipmentUpdate) (*Shipment, OrderStatus, error) {
+	r, err := c.service.UpdateShipment(ctx, &pb.UpdateShipmentRequest{
+		Id:             id,
+		Carrier:        update.Carrier,
+		TrackingNumber: update.TrackingNumber,
+		ShippedAt:      timeToProto(update.ShippedAt),
+		DeliveredAt:    timeToProto(update.DeliveredAt),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return shipmentFromProto(r.Shipment), OrderStatus(r.OrderStatus), nil
+}
+
+func (c *Client) GetShipmentsForOrder(ctx context.Context, orderID string) ([]Shipment, error) {
+	r, err := c.service.GetShipmentsForOrder(ctx, &pb.GetShipmentsForOrderRequest{OrderId: orderID})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	shipments := []Shipment{}
+	for _, sp := range r.Shipments {
+		shipments = append(shipments, *shipmentFromProto(sp))
+	}
+	return shipments, nil
+}
+
+// HasDeliveredProduct tells whether the product has been delivered to the account in any of its orders
+func (c *Client) HasDeliveredProduct(ctx context.Context, accountID string, productID string) (bool, error) {
+	r, err := c.service.HasDeliveredProduct(ctx, &pb.HasDeliveredProductRequest{
+		AccountId: accountID,
+		ProductId: productID,
+	})
+	if err != nil {
+		log.Println(err)
+		return false, err
+	}
+	return r.Delivered, nil
+}
+
+// GetPurchaseCounts returns how many of the account's orders each product it bought was in, by product ID
+func (c *Client) GetPurchaseCounts(ctx context.Context, accountID string) (map[string]uint64, error) {
+	r, err := c.service.GetPurchaseCounts(ctx, &pb.GetPurchaseCountsRequest{AccountId: accountID})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	counts := map[string]uint64{}
+	for _, count := range r.Counts {
+		counts[count.ProductId] = count.Orders
+	}
+	return counts, nil
+}
+
+func shipmentFromProto(sp *pb.Shipment) *Shipment {
+	s := &Shipment{
+		ID:             sp.Id,
+		OrderID:        sp.OrderId,
+		Carrier:        sp.Carrier,
+		TrackingNumber: sp.TrackingNumber,
+		ShippedAt:      timeFromProto(sp.ShippedAt),
+		DeliveredAt:    timeFromProto(sp.DeliveredAt),
+		Products:       []ShippedProduct{},
+	}
+	s.CreatedAt.UnmarshalBinary(sp.CreatedAt)
+	for _, p := range sp.Products {
+		s.Products = append(s.Products, ShippedProduct{
+			ID:        p.ProductId,
+			VariantID: p.VariantId,
+			Quantity:  p.Quantity,
+		})
 	}
-	return orders, nil
+	return s
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"time"
 
@@ -13,6 +14,14 @@ type Config struct {
 	DatabaseURL string `envconfig:"DATABASE_URL"`
 	AccountURL  string `envconfig:"ACCOUNT_SERVICE_URL"`
 	CatalogURL  string `envconfig:"CATALOG_SERVICE_URL"`
+	// Recompute the recommendation scores from the whole order history at startup
+	RebuildRecommendations bool `envconfig:"REBUILD_RECOMMENDATIONS"`
+	// Limits every order is validated against
+	MaxProductQuantity uint32  `envconfig:"MAX_PRODUCT_QUANTITY" default:"100"`
+	MaxOrderQuantity   uint32  `envconfig:"MAX_ORDER_QUANTITY" default:"1000"`
+	MinOrderValue      float64 `envconfig:"MIN_ORDER_VALUE" default:"0"`
+	// Key the order links of guests are signed with; guest checkout is disabled without it
+	GuestTokenSecret string `envconfig:"GUEST_TOKEN_SECRET"`
 }
 
 func main() {
@@ -32,7 +41,19 @@ func main() {
 	})
 	defer r.Close()
 
+	if cfg.RebuildRecommendations {
+		log.Println("Rebuilding recommendations...")
+		if err := r.RebuildRecommendations(context.Background()); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	log.Println("Listening on port 8080...")
 	s := order.NewService(r)
-	log.Fatal(order.ListenGRPC(s, cfg.AccountURL, cfg.CatalogURL, 8080))
+	limits := order.OrderLimits{
+		MaxProductQuantity: cfg.MaxProductQuantity,
+		MaxOrderQuantity:   cfg.MaxOrderQuantity,
+		MinOrderValue:      cfg.MinOrderValue,
+	}
+	log.Fatal(order.ListenGRPC(s, cfg.AccountURL, cfg.CatalogURL, limits, cfg.GuestTokenSecret, 8080))
 }
@@ -0,0 +1,60 @@
+package order
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrGuestCheckoutDisabled = errors.New("guest checkout is not configured")
+	ErrInvalidGuestToken     = errors.New("invalid or expired guest order token")
+)
+
+// How long the link sent to a guest keeps working
+const guestTokenTTL = 90 * 24 * time.Hour
+
+// guestTokens signs and verifies the tokens guests use to look up their orders without an account.
+// A token is "<order ID>.<expiry in unix seconds>.<signature>", where the signature is the
+// base64url encoded HMAC-SHA256 of the first two parts.
+type guestTokens struct {
+	secret []byte
+}
+
+func (t guestTokens) sign(orderID string, now time.Time) (string, error) {
+	if len(t.secret) == 0 {
+		return "", ErrGuestCheckoutDisabled
+	}
+	payload := orderID + "." + strconv.FormatInt(now.Add(guestTokenTTL).Unix(), 10)
+	return payload + "." + t.signature(payload), nil
+}
+
+// verify returns the ID of the order the token was issued for
+func (t guestTokens) verify(token string, now time.Time) (string, error) {
+	if len(t.secret) == 0 {
+		return "", ErrGuestCheckoutDisabled
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidGuestToken
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(t.signature(payload))) {
+		return "", ErrInvalidGuestToken
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return "", ErrInvalidGuestToken
+	}
+	return parts[0], nil
+}
+
+func (t guestTokens) signature(payload string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
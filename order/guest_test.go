@@ -0,0 +1,58 @@
+package order
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGuestTokens(t *testing.T) {
+	tokens := guestTokens{secret: []byte("secret")}
+	issued := day(1)
+	token, err := tokens.sign("order-1", issued)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tokens work until they expire, and no longer at the expiry itself
+	for _, now := range []time.Time{issued, issued.Add(guestTokenTTL - time.Second)} {
+		if id, err := tokens.verify(token, now); err != nil || id != "order-1" {
+			t.Fatalf("got %q, %v verifying at %v, want order-1", id, err, now)
+		}
+	}
+	if id, err := tokens.verify(token, issued.Add(guestTokenTTL)); !errors.Is(err, ErrInvalidGuestToken) {
+		t.Fatalf("got %q, %v verifying an expired token, want ErrInvalidGuestToken", id, err)
+	}
+
+	parts := strings.Split(token, ".")
+	invalid := []struct {
+		name   string
+		token  string
+		tokens guestTokens
+	}{
+		{"other order", "order-2." + parts[1] + "." + parts[2], tokens},
+		{"extended expiry", parts[0] + "." + "99999999999" + "." + parts[2], tokens},
+		{"tampered signature", parts[0] + "." + parts[1] + "." + strings.ToUpper(parts[2]), tokens},
+		{"no signature", parts[0] + "." + parts[1], tokens},
+		{"extra part", token + ".x", tokens},
+		{"empty", "", tokens},
+		{"other secret", token, guestTokens{secret: []byte("other")}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if id, err := tt.tokens.verify(tt.token, issued); !errors.Is(err, ErrInvalidGuestToken) {
+				t.Fatalf("got %q, %v, want ErrInvalidGuestToken", id, err)
+			}
+		})
+	}
+
+	// Without a secret, guest checkout is off altogether
+	disabled := guestTokens{}
+	if _, err := disabled.sign("order-1", issued); !errors.Is(err, ErrGuestCheckoutDisabled) {
+		t.Fatalf("got %v signing without a secret, want ErrGuestCheckoutDisabled", err)
+	}
+	if _, err := disabled.verify(token, issued); !errors.Is(err, ErrGuestCheckoutDisabled) {
+		t.Fatalf("got %v verifying without a secret, want ErrGuestCheckoutDisabled", err)
+	}
+}
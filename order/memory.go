@@ -0,0 +1,495 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// memoryRepository keeps orders, returns and shipments in memory, for development and tests.
+// Recommendations are computed from the orders whenever they are asked for.
+type memoryRepository struct {
+	mu        sync.RWMutex
+	orders    map[string]Order
+	returns   map[string]Return
+	shipments map[string]Shipment
+}
+
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		orders:    map[string]Order{},
+		returns:   map[string]Return{},
+		shipments: map[string]Shipment{},
+	}
+}
+
+func (r *memoryRepository) Close() {
+}
+
+// Copies an order, so that neither callers nor the repository see each other's changes to it
+func cloneOrder(o Order) Order {
+	products := []OrderedProduct{}
+	for _, p := range o.Products {
+		p.Options = slices.Clone(p.Options)
+		products = append(products, p)
+	}
+	o.Products = products
+	if o.ShippingAddress != nil {
+		a := *o.ShippingAddress
+		o.ShippingAddress = &a
+	}
+	return o
+}
+
+func cloneReturn(ret Return) Return {
+	ret.Products = slices.Clone(ret.Products)
+	if ret.Refund != nil {
+		refund := *ret.Refund
+		ret.Refund = &refund
+	}
+	return ret
+}
+
+func cloneShipment(s Shipment) Shipment {
+	s.Products = slices.Clone(s.Products)
+	if s.ShippedAt != nil {
+		shippedAt := *s.ShippedAt
+		s.ShippedAt = &shippedAt
+	}
+	if s.DeliveredAt != nil {
+		deliveredAt := *s.DeliveredAt
+		s.DeliveredAt = &deliveredAt
+	}
+	return s
+}
+
+func (r *memoryRepository) PutOrder(ctx context.Context, o Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.orders[o.ID]; ok {
+		return fmt.Errorf("order %q already exists", o.ID)
+	}
+	r.orders[o.ID] = cloneOrder(o)
+	return nil
+}
+
+func (r *memoryRepository) GetOrderByID(ctx context.Context, id string) (*Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	o, ok := r.orders[id]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	o = cloneOrder(o)
+	return &o, nil
+}
+
+// Whether an order passes the filter; nil and empty fields don't filter
+func matchesFilter(o Order, filter OrderFilter) bool {
+	if filter.AccountID != "" && o.AccountID != filter.AccountID {
+		return false
+	}
+	if !strings.HasPrefix(o.ID, filter.IDPrefix) {
+		return false
+	}
+	if filter.ProductID != "" && !slices.ContainsFunc(o.Products, func(p OrderedProduct) bool { return p.ID == filter.ProductID }) {
+		return false
+	}
+	if filter.CreatedAfter != nil && o.CreatedAt.Before(*filter.CreatedAfter) {
+		return false
+	}
+	if filter.CreatedBefore != nil && !o.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
+	if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, o.Status) {
+		return false
+	}
+	if filter.MinTotal != nil && o.TotalPrice < *filter.MinTotal {
+		return false
+	}
+	if filter.MaxTotal != nil && o.TotalPrice > *filter.MaxTotal {
+		return false
+	}
+	return true
+}
+
+// Returns the orders passing the filter, in no particular order
+func (r *memoryRepository) filterOrders(filter OrderFilter) []Order {
+	orders := []Order{}
+	for _, o := range r.orders {
+		if matchesFilter(o, filter) {
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
+
+// compareOrders compares orders by the sort key, then by ID, in the direction of the sort
+func compareOrders(a Order, b Order, sort OrderSort) int {
+	var c int
+	switch sort {
+	case OrderSortTotalPriceAsc, OrderSortTotalPriceDesc:
+		c = cmpFloat(a.TotalPrice, b.TotalPrice)
+	default:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c == 0 {
+		c = strings.Compare(a.ID, b.ID)
+	}
+	if sort == OrderSortCreatedAtAsc || sort == OrderSortTotalPriceAsc {
+		return c
+	}
+	return -c
+}
+
+func cmpFloat(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (r *memoryRepository) SearchOrders(ctx context.Context, filter OrderFilter, sort OrderSort, after *Order, take uint64) ([]Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Keyset pagination: continue right after the sort key of the last order of the previous page
+	orders := []Order{}
+	for _, o := range r.filterOrders(filter) {
+		if after == nil || compareOrders(o, *after, sort) > 0 {
+			orders = append(orders, cloneOrder(o))
+		}
+	}
+	slices.SortFunc(orders, func(a Order, b Order) int { return compareOrders(a, b, sort) })
+	if take < uint64(len(orders)) {
+		orders = orders[:take]
+	}
+	return orders, nil
+}
+
+func (r *memoryRepository) CountOrders(ctx context.Context, filter OrderFilter) (uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return uint64(len(r.filterOrders(filter))), nil
+}
+
+func (r *memoryRepository) GetSalesBuckets(ctx context.Context, filter OrderFilter, interval SalesInterval) ([]SalesBucket, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Buckets start at midnight UTC; weeks start on Monday
+	byStart := map[int64]*SalesBucket{}
+	for _, o := range r.filterOrders(filter) {
+		start := truncateToInterval(o.CreatedAt, interval)
+		b, ok := byStart[start.Unix()]
+		if !ok {
+			b = &SalesBucket{Start: start}
+			byStart[start.Unix()] = b
+		}
+		b.Revenue += o.TotalPrice
+		b.OrderCount++
+	}
+
+	buckets := []SalesBucket{}
+	for _, b := range byStart {
+		buckets = append(buckets, *b)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Start.Before(buckets[j].Start) })
+	return buckets, nil
+}
+
+func (r *memoryRepository) GetTopProducts(ctx context.Context, filter OrderFilter, sortBy ProductSalesSort, limit uint64) ([]ProductSales, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byID := map[string]*ProductSales{}
+	for _, o := range r.filterOrders(filter) {
+		for _, p := range o.Products {
+			sales, ok := byID[p.ID]
+			if !ok {
+				sales = &ProductSales{ID: p.ID}
+				byID[p.ID] = sales
+			}
+			sales.Quantity += uint64(p.Quantity)
+			sales.Revenue += p.Price * float64(p.Quantity)
+		}
+	}
+
+	products := []ProductSales{}
+	for _, sales := range byID {
+		products = append(products, *sales)
+	}
+	sort.Slice(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		if sortBy == ProductSalesSortRevenue && a.Revenue != b.Revenue {
+			return a.Revenue > b.Revenue
+		}
+		if sortBy != ProductSalesSortRevenue && a.Quantity != b.Quantity {
+			return a.Quantity > b.Quantity
+		}
+		return a.ID < b.ID
+	})
+	if limit < uint64(len(products)) {
+		products = products[:limit]
+	}
+	return products, nil
+}
+
+// RebuildRecommendations has nothing to do, as recommendations are always computed from the orders
+func (r *memoryRepository) RebuildRecommendations(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryRepository) GetPurchasedProductIDs(ctx context.Context, accountID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := map[string]bool{}
+	productIDs := []string{}
+	for _, o := range r.filterOrders(OrderFilter{AccountID: accountID}) {
+		for _, p := range o.Products {
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				productIDs = append(productIDs, p.ID)
+			}
+		}
+	}
+	return productIDs, nil
+}
+
+func (r *memoryRepository) GetPurchaseCounts(ctx context.Context, accountID string) (map[string]uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := map[string]uint64{}
+	for _, o := range r.filterOrders(OrderFilter{AccountID: accountID}) {
+		// Products ordered in several variants count once per order
+		seen := map[string]bool{}
+		for _, p := range o.Products {
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				counts[p.ID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// GetFrequentlyBoughtWith scores the products like the Postgres repository does: by summing, over the given
+// products, how often both were ordered together relative to how often each was ordered at all
+func (r *memoryRepository) GetFrequentlyBoughtWith(ctx context.Context, productIDs []string, limit uint64) ([]RecommendedProduct, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Count the orders of each product, and those of every other product together with a given one
+	type pair struct{ productID, otherProductID string }
+	orders := map[string]float64{}
+	pairs := map[pair]float64{}
+	for _, o := range r.orders {
+		ids := []string{}
+		for _, p := range o.Products {
+			if !slices.Contains(ids, p.ID) {
+				ids = append(ids, p.ID)
+			}
+		}
+		for _, id := range ids {
+			orders[id]++
+			if !slices.Contains(productIDs, id) {
+				continue
+			}
+			for _, other := range ids {
+				if !slices.Contains(productIDs, other) {
+					pairs[pair{id, other}]++
+				}
+			}
+		}
+	}
+
+	scores := map[string]float64{}
+	for p, together := range pairs {
+		scores[p.otherProductID] += together / math.Sqrt(orders[p.productID]*orders[p.otherProductID])
+	}
+	products := []RecommendedProduct{}
+	for id, score := range scores {
+		products = append(products, RecommendedProduct{ID: id, Score: score})
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].Score != products[j].Score {
+			return products[i].Score > products[j].Score
+		}
+		return products[i].ID < products[j].ID
+	})
+	if limit < uint64(len(products)) {
+		products = products[:limit]
+	}
+	return products, nil
+}
+
+func (r *memoryRepository) PutReturn(ctx context.Context, ret Return) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.returns[ret.ID]; ok {
+		return fmt.Errorf("return %q already exists", ret.ID)
+	}
+	r.returns[ret.ID] = cloneReturn(ret)
+	return nil
+}
+
+func (r *memoryRepository) GetReturnByID(ctx context.Context, id string) (*Return, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ret, ok := r.returns[id]
+	if !ok {
+		return nil, ErrReturnNotFound
+	}
+	ret = cloneReturn(ret)
+	return &ret, nil
+}
+
+func (r *memoryRepository) GetReturnsForOrder(ctx context.Context, orderID string) ([]Return, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	returns := []Return{}
+	for _, ret := range r.returns {
+		if ret.OrderID == orderID {
+			returns = append(returns, cloneReturn(ret))
+		}
+	}
+	sort.Slice(returns, func(i, j int) bool {
+		if !returns[i].CreatedAt.Equal(returns[j].CreatedAt) {
+			return returns[i].CreatedAt.Before(returns[j].CreatedAt)
+		}
+		return returns[i].ID < returns[j].ID
+	})
+	return returns, nil
+}
+
+func (r *memoryRepository) UpdateReturn(ctx context.Context, ret Return, from ReturnStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Only move the return on if nobody else has changed its status in the meantime
+	stored, ok := r.returns[ret.ID]
+	if !ok || stored.Status != from {
+		return ErrInvalidReturnStatus
+	}
+	stored.Status = ret.Status
+	stored.Note = ret.Note
+	stored.UpdatedAt = ret.UpdatedAt
+	if ret.Refund != nil {
+		refund := *ret.Refund
+		stored.Refund = &refund
+	}
+	r.returns[ret.ID] = stored
+	return nil
+}
+
+func (r *memoryRepository) MarkReturnRestocked(ctx context.Context, id string, productID string, variantID string, restocked bool) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret, ok := r.returns[id]
+	if !ok || ret.Status != ReturnStatusReceived {
+		return false, nil
+	}
+	for i, p := range ret.Products {
+		if p.ID == productID && p.VariantID == variantID && p.Restocked != restocked {
+			ret.Products[i].Restocked = restocked
+			r.returns[id] = ret
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryRepository) UpdateOrderStatus(ctx context.Context, id string, status OrderStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if o, ok := r.orders[id]; ok {
+		o.Status = status
+		r.orders[id] = o
+	}
+	return nil
+}
+
+func (r *memoryRepository) PutShipment(ctx context.Context, s Shipment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.shipments[s.ID]; ok {
+		return fmt.Errorf("shipment %q already exists", s.ID)
+	}
+	r.shipments[s.ID] = cloneShipment(s)
+	return nil
+}
+
+func (r *memoryRepository) GetShipmentByID(ctx context.Context, id string) (*Shipment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.shipments[id]
+	if !ok {
+		return nil, ErrShipmentNotFound
+	}
+	s = cloneShipment(s)
+	return &s, nil
+}
+
+func (r *memoryRepository) GetShipmentsForOrder(ctx context.Context, orderID string) ([]Shipment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	shipments := []Shipment{}
+	for _, s := range r.shipments {
+		if s.OrderID == orderID {
+			shipments = append(shipments, cloneShipment(s))
+		}
+	}
+	sort.Slice(shipments, func(i, j int) bool {
+		if !shipments[i].CreatedAt.Equal(shipments[j].CreatedAt) {
+			return shipments[i].CreatedAt.Before(shipments[j].CreatedAt)
+		}
+		return shipments[i].ID < shipments[j].ID
+	})
+	return shipments, nil
+}
+
+func (r *memoryRepository) UpdateShipment(ctx context.Context, s Shipment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.shipments[s.ID]
+	if !ok {
+		return nil
+	}
+	updated := cloneShipment(s)
+	stored.Carrier = updated.Carrier
+	stored.TrackingNumber = updated.TrackingNumber
+	stored.ShippedAt = updated.ShippedAt
+	stored.DeliveredAt = updated.DeliveredAt
+	r.shipments[s.ID] = stored
+	return nil
+}
+
+func (r *memoryRepository) ReassignOrder(ctx context.Context, id string, fromAccountID string, toAccountID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, ok := r.orders[id]
+	if !ok || o.AccountID != fromAccountID {
+		return ErrInvalidClaim
+	}
+	o.AccountID = toAccountID
+	r.orders[id] = o
+
+	// Returns keep the account of their order
+	for rid, ret := range r.returns {
+		if ret.OrderID == id {
+			ret.AccountID = toAccountID
+			r.returns[rid] = ret
+		}
+	}
+	return nil
+}
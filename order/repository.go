@@ -3,6 +3,10 @@ package order
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -11,7 +15,26 @@ import (
 type Repository interface {
 	Close()
 	PutOrder(ctx context.Context, o Order) error
-	GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error)
+	SearchOrders(ctx context.Context, filter OrderFilter, sort OrderSort, after *Order, take uint64) ([]Order, error)
+	CountOrders(ctx context.Context, filter OrderFilter) (uint64, error)
+	GetSalesBuckets(ctx context.Context, filter OrderFilter, interval SalesInterval) ([]SalesBucket, error)
+	GetTopProducts(ctx context.Context, filter OrderFilter, sortBy ProductSalesSort, limit uint64) ([]ProductSales, error)
+	RebuildRecommendations(ctx context.Context) error
+	GetPurchasedProductIDs(ctx context.Context, accountID string) ([]string, error)
+	GetPurchaseCounts(ctx context.Context, accountID string) (map[string]uint64, error)
+	GetFrequentlyBoughtWith(ctx context.Context, productIDs []string, limit uint64) ([]RecommendedProduct, error)
+	GetOrderByID(ctx context.Context, id string) (*Order, error)
+	PutReturn(ctx context.Context, r Return) error
+	GetReturnByID(ctx context.Context, id string) (*Return, error)
+	GetReturnsForOrder(ctx context.Context, orderID string) ([]Return, error)
+	UpdateReturn(ctx context.Context, r Return, from ReturnStatus) error
+	MarkReturnRestocked(ctx context.Context, id string, productID string, variantID string, restocked bool) (bool, error)
+	UpdateOrderStatus(ctx context.Context, id string, status OrderStatus) error
+	PutShipment(ctx context.Context, s Shipment) error
+	GetShipmentByID(ctx context.Context, id string) (*Shipment, error)
+	GetShipmentsForOrder(ctx context.Context, orderID string) ([]Shipment, error)
+	UpdateShipment(ctx context.Context, s Shipment) error
+	ReassignOrder(ctx context.Context, id string, fromAccountID string, toAccountID string) error
 }
 
 type postgresRepository struct {
@@ -51,20 +74,37 @@ func (r *postgresRepository) PutOrder(ctx context.Context, o Order) (err error)
 	}()
 
 	// ExexContext to execute the SQL command
-	_, err = tx.ExecContext(ctx, "INSERT INTO orders(id, created_at, account_id, total_price) VALUES($1, $2, $3, $4)", o.ID, o.CreatedAt, o.AccountID, o.TotalPrice)
+	_, err = tx.ExecContext(ctx, "INSERT INTO orders(id, created_at, account_id, total_price, status) VALUES($1, $2, $3, $4, $5)", o.ID, o.CreatedAt, o.AccountID, o.TotalPrice, o.Status)
 	if err != nil {
 		return
 	}
 
+	// Store where the order should be shipped, if it says so itself
+	if a := o.ShippingAddress; a != nil {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO order_shipping_addresses(order_id, name, line1, line2, city, region, postal_code, country)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+			`, o.ID, a.Name, a.Line1, a.Line2, a.City, a.Region, a.PostalCode, a.Country,
+		)
+		if err != nil {
+			return
+		}
+	}
+
 	// Prepare context to put products in the order
-	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("order_products", "order_id", "product_id", "quantity"))
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("order_products", "order_id", "product_id", "variant_id", "sku", "options", "quantity", "price"))
 	if err != nil {
 		return
 	}
 
 	// Range over the o.Products to put the products in the order based on the order ID
 	for _, p := range o.Products {
-		_, err = stmt.ExecContext(ctx, o.ID, p.ID, p.Quantity)
+		var options []byte
+		options, err = json.Marshal(optionsOrEmpty(p.Options))
+		if err != nil {
+			return
+		}
+		_, err = stmt.ExecContext(ctx, o.ID, p.ID, p.VariantID, p.SKU, string(options), p.Quantity, p.Price)
 		if err != nil {
 			return
 
@@ -77,79 +117,799 @@ func (r *postgresRepository) PutOrder(ctx context.Context, o Order) (err error)
 		return
 	}
 	stmt.Close()
+
+	// Count the order towards the recommendation scores of its products, once however many variants of
+	// a product it has
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_order_counts(product_id, orders)
+		SELECT DISTINCT product_id, 1 FROM order_products WHERE order_id = $1
+		ON CONFLICT (product_id) DO UPDATE SET orders = product_order_counts.orders + 1
+		`, o.ID,
+	)
+	if err != nil {
+		return
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_pairs(product_id, other_product_id, orders)
+		SELECT DISTINCT a.product_id, b.product_id, 1
+		FROM order_products a JOIN order_products b ON (a.order_id = b.order_id AND a.product_id <> b.product_id)
+		WHERE a.order_id = $1
+		ON CONFLICT (product_id, other_product_id) DO UPDATE SET orders = product_pairs.orders + 1
+		`, o.ID,
+	)
+	return
+}
+
+func (r *postgresRepository) SearchOrders(ctx context.Context, filter OrderFilter, sort OrderSort, after *Order, take uint64) ([]Order, error) {
+	args := []interface{}{}
+	conditions := filterConditions(filter, &args)
+
+	// Keyset pagination: continue right after the sort key of the last order of the previous page
+	column, direction := sortColumn(sort)
+	if after != nil {
+		key := interface{}(after.CreatedAt)
+		if sort == OrderSortTotalPriceAsc || sort == OrderSortTotalPriceDesc {
+			key = after.TotalPrice
+		}
+		comparison := "<"
+		if direction == "ASC" {
+			comparison = ">"
+		}
+		args = append(args, key, after.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, o.id) %s ($%d, $%d)", column, comparison, len(args)-1, len(args)))
+	}
+	args = append(args, take)
+
+	// Page through orders first and only then join their products, so LIMIT counts orders rather than lines
+	orderBy := fmt.Sprintf("%s %s, o.id %s", column, direction, direction)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT o.id, o.created_at, o.account_id, o.total_price::money::numeric::float8, o.status, op.product_id, op.variant_id, op.sku, op.options, op.quantity, op.price::money::numeric::float8
+		FROM (
+			SELECT o.id, o.created_at, o.account_id, o.total_price, o.status
+			FROM orders o
+			`+whereClause(conditions)+`
+			ORDER BY `+orderBy+`
+			LIMIT $`+fmt.Sprint(len(args))+`
+		) o JOIN order_products op ON (o.id = op.order_id)
+		ORDER BY `+orderBy+`
+		`, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOrders(rows)
+}
+
+func (r *postgresRepository) CountOrders(ctx context.Context, filter OrderFilter) (uint64, error) {
+	args := []interface{}{}
+	conditions := filterConditions(filter, &args)
+
+	var count uint64
+	row := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM orders o "+whereClause(conditions), args...)
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// filterConditions turns the filter into SQL conditions on orders aliased as o, appending their parameters to args
+func filterConditions(filter OrderFilter, args *[]interface{}) []string {
+	conditions := []string{}
+	add := func(condition string, arg interface{}) {
+		*args = append(*args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(*args)))
+	}
+
+	if filter.AccountID != "" {
+		add("o.account_id = $%d", filter.AccountID)
+	}
+	if filter.IDPrefix != "" {
+		// Escape LIKE wildcards so the prefix is matched literally
+		prefix := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(filter.IDPrefix)
+		add("o.id LIKE $%d", prefix+"%")
+	}
+	if filter.ProductID != "" {
+		add("EXISTS (SELECT 1 FROM order_products fp WHERE fp.order_id = o.id AND fp.product_id = $%d)", filter.ProductID)
+	}
+	if filter.CreatedAfter != nil {
+		add("o.created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		add("o.created_at < $%d", *filter.CreatedBefore)
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := []string{}
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		add("o.status = ANY($%d)", pq.Array(statuses))
+	}
+	if filter.MinTotal != nil {
+		add("o.total_price::numeric::float8 >= $%d", *filter.MinTotal)
+	}
+	if filter.MaxTotal != nil {
+		add("o.total_price::numeric::float8 <= $%d", *filter.MaxTotal)
+	}
+	return conditions
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// sortColumn maps a sort order to the orders column (aliased as o) and direction it sorts by
+func sortColumn(sort OrderSort) (string, string) {
+	switch sort {
+	case OrderSortCreatedAtAsc:
+		return "o.created_at", "ASC"
+	case OrderSortTotalPriceAsc:
+		return "o.total_price::numeric::float8", "ASC"
+	case OrderSortTotalPriceDesc:
+		return "o.total_price::numeric::float8", "DESC"
+	default:
+		return "o.created_at", "DESC"
+	}
+}
+
+func (r *postgresRepository) GetOrderByID(ctx context.Context, id string) (*Order, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT o.id, o.created_at, o.account_id, o.total_price::money::numeric::float8, o.status, op.product_id, op.variant_id, op.sku, op.options, op.quantity, op.price::money::numeric::float8
+		FROM orders o JOIN order_products op ON (o.id = op.order_id)
+		WHERE o.id = $1
+		`, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	order := &orders[0]
+
+	// Attach the shipping address, which only guest orders have
+	a := &Address{}
+	err = r.db.QueryRowContext(ctx, `
+		SELECT name, line1, line2, city, region, postal_code, country
+		FROM order_shipping_addresses WHERE order_id = $1
+		`, id,
+	).Scan(&a.Name, &a.Line1, &a.Line2, &a.City, &a.Region, &a.PostalCode, &a.Country)
+	if err == nil {
+		order.ShippingAddress = a
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Lines without a variant are stored with an empty list of options rather than null
+func optionsOrEmpty(options []VariantOption) []VariantOption {
+	if options == nil {
+		return []VariantOption{}
+	}
+	return options
+}
+
+// scanOrders folds the rows of an orders/order_products join, sorted by order, into orders with their products
+func scanOrders(rows *sql.Rows) ([]Order, error) {
+	orders := []Order{}
+	var currentOrder *Order = nil
+	var currentProducts []OrderedProduct = nil
+
+	for rows.Next() {
+		var orderID string
+		var createdAt time.Time
+		var accountIDFromDB string
+		var totalPrice float64
+		var status OrderStatus
+		var rawProductID sql.RawBytes
+		var variantID, sku, options string
+		var quantity uint32
+		var price float64
+
+		// Scan into local variables
+		err := rows.Scan(
+			&orderID,
+			&createdAt,
+			&accountIDFromDB,
+			&totalPrice,
+			&status,
+			&rawProductID,
+			&variantID,
+			&sku,
+			&options,
+			&quantity,
+			&price,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Convert rawProductID to string after successful scan
+		productID := string(rawProductID)
+
+		// Detect if we have a new order
+		if currentOrder == nil || currentOrder.ID != orderID {
+			if currentOrder != nil {
+				currentOrder.Products = currentProducts
+				orders = append(orders, *currentOrder)
+			}
+			currentOrder = &Order{
+				ID:         orderID,
+				CreatedAt:  createdAt,
+				AccountID:  accountIDFromDB,
+				TotalPrice: totalPrice,
+				Status:     status,
+			}
+			currentProducts = []OrderedProduct{}
+		}
+
+		product := OrderedProduct{
+			ID:        productID,
+			VariantID: variantID,
+			SKU:       sku,
+			Quantity:  quantity,
+			Price:     price,
+		}
+		if err := json.Unmarshal([]byte(options), &product.Options); err != nil {
+			return nil, err
+		}
+		currentProducts = append(currentProducts, product)
+	}
+
+	if currentOrder != nil {
+		currentOrder.Products = currentProducts
+		orders = append(orders, *currentOrder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *postgresRepository) PutReturn(ctx context.Context, ret Return) (err error) {
+	// Begin a transaction so the return and its products are stored together
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO returns(id, order_id, account_id, status, reason, note, created_at, updated_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+		`, ret.ID, ret.OrderID, ret.AccountID, ret.Status, ret.Reason, ret.Note, ret.CreatedAt, ret.UpdatedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("return_products", "return_id", "product_id", "variant_id", "quantity", "price"))
+	if err != nil {
+		return
+	}
+	for _, p := range ret.Products {
+		_, err = stmt.ExecContext(ctx, ret.ID, p.ID, p.VariantID, p.Quantity, p.Price)
+		if err != nil {
+			return
+		}
+	}
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		return
+	}
+	stmt.Close()
+	return
+}
+
+func (r *postgresRepository) GetReturnByID(ctx context.Context, id string) (*Return, error) {
+	returns, err := r.queryReturns(ctx, "r.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(returns) == 0 {
+		return nil, ErrReturnNotFound
+	}
+	return &returns[0], nil
+}
+
+func (r *postgresRepository) GetReturnsForOrder(ctx context.Context, orderID string) ([]Return, error) {
+	return r.queryReturns(ctx, "r.order_id = $1", orderID)
+}
+
+// queryReturns loads the returns matching the condition together with their products and refund
+func (r *postgresRepository) queryReturns(ctx context.Context, condition string, args ...interface{}) ([]Return, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.id, r.order_id, r.account_id, r.status, r.reason, r.note, r.created_at, r.updated_at,
+			rp.product_id, rp.variant_id, rp.quantity, rp.price::money::numeric::float8, rp.restocked,
+			rf.id, rf.amount::money::numeric::float8, rf.created_at
+		FROM returns r
+		JOIN return_products rp ON (r.id = rp.return_id)
+		LEFT JOIN refunds rf ON (r.id = rf.return_id)
+		WHERE `+condition+`
+		ORDER BY r.created_at, r.id
+		`, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := []Return{}
+	var current *Return = nil
+
+	for rows.Next() {
+		ret := Return{}
+		p := ReturnedProduct{}
+		var refundID sql.NullString
+		var refundAmount sql.NullFloat64
+		var refundCreatedAt sql.NullTime
+
+		err = rows.Scan(
+			&ret.ID, &ret.OrderID, &ret.AccountID, &ret.Status, &ret.Reason, &ret.Note, &ret.CreatedAt, &ret.UpdatedAt,
+			&p.ID, &p.VariantID, &p.Quantity, &p.Price, &p.Restocked,
+			&refundID, &refundAmount, &refundCreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Detect if we have a new return
+		if current == nil || current.ID != ret.ID {
+			if current != nil {
+				returns = append(returns, *current)
+			}
+			if refundID.Valid {
+				ret.Refund = &Refund{
+					ID:        refundID.String,
+					Amount:    refundAmount.Float64,
+					CreatedAt: refundCreatedAt.Time,
+				}
+			}
+			current = &ret
+		}
+		current.Products = append(current.Products, p)
+	}
+
+	if current != nil {
+		returns = append(returns, *current)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+func (r *postgresRepository) UpdateReturn(ctx context.Context, ret Return, from ReturnStatus) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Only move the return on if nobody else has changed its status in the meantime
+	res, err := tx.ExecContext(ctx, `
+		UPDATE returns SET status = $3, note = $4, updated_at = $5
+		WHERE id = $1 AND status = $2
+		`, ret.ID, from, ret.Status, ret.Note, ret.UpdatedAt,
+	)
+	if err != nil {
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		err = ErrInvalidReturnStatus
+		return
+	}
+
+	if ret.Refund != nil {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO refunds(id, return_id, order_id, amount, created_at)
+			VALUES($1, $2, $3, $4, $5)
+			`, ret.Refund.ID, ret.ID, ret.OrderID, ret.Refund.Amount, ret.Refund.CreatedAt,
+		)
+	}
+	return
+}
+
+func (r *postgresRepository) MarkReturnRestocked(ctx context.Context, id string, productID string, variantID string, restocked bool) (bool, error) {
+	// Only lines of received returns get restocked, and only one caller gets to change each line
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE return_products rp SET restocked = $4
+		FROM returns r
+		WHERE r.id = rp.return_id AND r.status = $5
+			AND rp.return_id = $1 AND rp.product_id = $2 AND rp.variant_id = $3 AND rp.restocked <> $4
+		`, id, productID, variantID, restocked, ReturnStatusReceived,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *postgresRepository) UpdateOrderStatus(ctx context.Context, id string, status OrderStatus) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE orders SET status = $2 WHERE id = $1", id, status)
+	return err
+}
+
+func (r *postgresRepository) PutShipment(ctx context.Context, s Shipment) (err error) {
+	// Begin a transaction so the shipment and its products are stored together
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO shipments(id, order_id, carrier, tracking_number, shipped_at, delivered_at, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+		`, s.ID, s.OrderID, s.Carrier, s.TrackingNumber, s.ShippedAt, s.DeliveredAt, s.CreatedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("shipment_products", "shipment_id", "product_id", "variant_id", "quantity"))
+	if err != nil {
+		return
+	}
+	for _, p := range s.Products {
+		_, err = stmt.ExecContext(ctx, s.ID, p.ID, p.VariantID, p.Quantity)
+		if err != nil {
+			return
+		}
+	}
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		return
+	}
+	stmt.Close()
+	return
+}
+
+func (r *postgresRepository) GetShipmentByID(ctx context.Context, id string) (*Shipment, error) {
+	shipments, err := r.queryShipments(ctx, "s.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(shipments) == 0 {
+		return nil, ErrShipmentNotFound
+	}
+	return &shipments[0], nil
+}
+
+func (r *postgresRepository) GetShipmentsForOrder(ctx context.Context, orderID string) ([]Shipment, error) {
+	return r.queryShipments(ctx, "s.order_id = $1", orderID)
+}
+
+// queryShipments loads the shipments matching the condition together with their products
+func (r *postgresRepository) queryShipments(ctx context.Context, condition string, args ...interface{}) ([]Shipment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.id, s.order_id, s.carrier, s.tracking_number, s.shipped_at, s.delivered_at, s.created_at,
+			sp.product_id, sp.variant_id, sp.quantity
+		FROM shipments s JOIN shipment_products sp ON (s.id = sp.shipment_id)
+		WHERE `+condition+`
+		ORDER BY s.created_at, s.id
+		`, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shipments := []Shipment{}
+	var current *Shipment = nil
+
+	for rows.Next() {
+		s := Shipment{}
+		p := ShippedProduct{}
+		var shippedAt, deliveredAt sql.NullTime
+
+		err = rows.Scan(
+			&s.ID, &s.OrderID, &s.Carrier, &s.TrackingNumber, &shippedAt, &deliveredAt, &s.CreatedAt,
+			&p.ID, &p.VariantID, &p.Quantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Detect if we have a new shipment
+		if current == nil || current.ID != s.ID {
+			if current != nil {
+				shipments = append(shipments, *current)
+			}
+			if shippedAt.Valid {
+				s.ShippedAt = &shippedAt.Time
+			}
+			if deliveredAt.Valid {
+				s.DeliveredAt = &deliveredAt.Time
+			}
+			current = &s
+		}
+		current.Products = append(current.Products, p)
+	}
+
+	if current != nil {
+		shipments = append(shipments, *current)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+func (r *postgresRepository) UpdateShipment(ctx context.Context, s Shipment) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE shipments SET carrier = $2, tracking_number = $3, shipped_at = $4, delivered_at = $5
+		WHERE id = $1
+		`, s.ID, s.Carrier, s.TrackingNumber, s.ShippedAt, s.DeliveredAt,
+	)
+	return err
+}
+
+func (r *postgresRepository) GetSalesBuckets(ctx context.Context, filter OrderFilter, interval SalesInterval) ([]SalesBucket, error) {
+	args := []interface{}{}
+	conditions := filterConditions(filter, &args)
+	args = append(args, strings.ToLower(string(interval)))
+
+	// Buckets start at midnight UTC; weeks start on Monday
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT date_trunc($`+fmt.Sprint(len(args))+`, o.created_at AT TIME ZONE 'UTC') AS bucket,
+			SUM(o.total_price)::numeric::float8, COUNT(*)
+		FROM orders o
+		`+whereClause(conditions)+`
+		GROUP BY bucket
+		ORDER BY bucket
+		`, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []SalesBucket{}
+	for rows.Next() {
+		b := SalesBucket{}
+		if err = rows.Scan(&b.Start, &b.Revenue, &b.OrderCount); err != nil {
+			return nil, err
+		}
+		b.Start = b.Start.UTC()
+		buckets = append(buckets, b)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+func (r *postgresRepository) GetTopProducts(ctx context.Context, filter OrderFilter, sortBy ProductSalesSort, limit uint64) ([]ProductSales, error) {
+	args := []interface{}{}
+	conditions := filterConditions(filter, &args)
+	args = append(args, limit)
+
+	orderBy := "quantity DESC"
+	if sortBy == ProductSalesSortRevenue {
+		orderBy = "revenue DESC"
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT op.product_id, SUM(op.quantity) AS quantity, SUM(op.price * op.quantity)::numeric::float8 AS revenue
+		FROM order_products op JOIN orders o ON (o.id = op.order_id)
+		`+whereClause(conditions)+`
+		GROUP BY op.product_id
+		ORDER BY `+orderBy+`, op.product_id
+		LIMIT $`+fmt.Sprint(len(args))+`
+		`, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []ProductSales{}
+	for rows.Next() {
+		p := ProductSales{}
+		if err = rows.Scan(&p.ID, &p.Quantity, &p.Revenue); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// RebuildRecommendations recomputes the product co-occurrence counts from the whole order history.
+// PutOrder keeps them up to date afterwards, so this is only needed to seed them for existing orders.
+func (r *postgresRepository) RebuildRecommendations(ctx context.Context) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(ctx, "TRUNCATE product_order_counts, product_pairs")
+	if err != nil {
+		return
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_order_counts(product_id, orders)
+		SELECT product_id, COUNT(DISTINCT order_id) FROM order_products GROUP BY product_id
+		`,
+	)
+	if err != nil {
+		return
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_pairs(product_id, other_product_id, orders)
+		SELECT a.product_id, b.product_id, COUNT(DISTINCT a.order_id)
+		FROM order_products a JOIN order_products b ON (a.order_id = b.order_id AND a.product_id <> b.product_id)
+		GROUP BY a.product_id, b.product_id
+		`,
+	)
 	return
 }
 
-func (r *postgresRepository) GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error) {
-    rows, err := r.db.QueryContext(ctx, `
-        SELECT o.id, o.created_at, o.account_id, o.total_price::money::numeric::float8, op.product_id, op.quantity
-        FROM orders o JOIN order_products op ON (o.id = op.order_id)
-        WHERE account_id = $1
-        ORDER BY o.id
-        `, accountID,
-    )
-
-    if err != nil {
-        return nil, err
-    }
-    defer rows.Close()
-
-    orders := []Order{}
-    var currentOrder *Order = nil
-    var currentProducts []OrderedProduct = nil
-
-    for rows.Next() {
-        var orderID string
-        var createdAt time.Time
-        var accountIDFromDB string
-        var totalPrice float64
-        var rawProductID sql.RawBytes
-        var quantity uint32
-
-        // Scan into local variables
-        err = rows.Scan(
-            &orderID,
-            &createdAt,
-            &accountIDFromDB,
-            &totalPrice,
-            &rawProductID, 
-            &quantity,
-        )
-        if err != nil {
-            return nil, err
-        }
-
-        // Convert rawProductID to string after successful scan
-        productID := string(rawProductID)
-
-        // Detect if we have a new order
-        if currentOrder == nil || currentOrder.ID != orderID {
-            if currentOrder != nil {
-                currentOrder.Products = currentProducts
-                orders = append(orders, *currentOrder)
-            }
-            currentOrder = &Order{
-                ID:         orderID,
-                CreatedAt:  createdAt,
-                AccountID:  accountIDFromDB,
-                TotalPrice: totalPrice,
-            }
-            currentProducts = []OrderedProduct{}
-        }
-
-        currentProducts = append(currentProducts, OrderedProduct{
-            ID:       productID,
-            Quantity: quantity,
-        })
-    }
-
-    if currentOrder != nil {
-        currentOrder.Products = currentProducts
-        orders = append(orders, *currentOrder)
-    }
-
-    if err = rows.Err(); err != nil {
-        return nil, err
-    }
-    return orders, nil
-}
\ No newline at end of file
+func (r *postgresRepository) GetPurchasedProductIDs(ctx context.Context, accountID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT op.product_id
+		FROM order_products op JOIN orders o ON (o.id = op.order_id)
+		WHERE o.account_id = $1
+		`, accountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	productIDs := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		productIDs = append(productIDs, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return productIDs, nil
+}
+
+// GetFrequentlyBoughtWith scores the products bought together with any of productIDs by summing,
+// over the given products, how often both were ordered together relative to how often each was
+// ordered at all (cosine similarity), so that best sellers don't dominate every recommendation.
+func (r *postgresRepository) GetFrequentlyBoughtWith(ctx context.Context, productIDs []string, limit uint64) ([]RecommendedProduct, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pp.other_product_id, SUM(pp.orders / sqrt(ca.orders::float8 * cb.orders)) AS score
+		FROM product_pairs pp
+		JOIN product_order_counts ca ON (ca.product_id = pp.product_id)
+		JOIN product_order_counts cb ON (cb.product_id = pp.other_product_id)
+		WHERE pp.product_id = ANY($1) AND NOT pp.other_product_id = ANY($1)
+		GROUP BY pp.other_product_id
+		ORDER BY score DESC, pp.other_product_id
+		LIMIT $2
+		`, pq.Array(productIDs), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []RecommendedProduct{}
+	for rows.Next() {
+		p := RecommendedProduct{}
+		if err = rows.Scan(&p.ID, &p.Score); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *postgresRepository) ReassignOrder(ctx context.Context, id string, fromAccountID string, toAccountID string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Only while the order still belongs to the account it is moved from, so it can be claimed once
+	res, err := tx.ExecContext(ctx, "UPDATE orders SET account_id = $3 WHERE id = $1 AND account_id = $2", id, fromAccountID, toAccountID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrInvalidClaim
+	}
+
+	// Returns keep the account of their order
+	_, err = tx.ExecContext(ctx, "UPDATE returns SET account_id = $2 WHERE order_id = $1", id, toAccountID)
+	return err
+}
+
+// GetPurchaseCounts counts the orders of the account each product it bought was in
+func (r *postgresRepository) GetPurchaseCounts(ctx context.Context, accountID string) (map[string]uint64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT op.product_id, COUNT(DISTINCT op.order_id)
+		FROM order_products op JOIN orders o ON (o.id = op.order_id)
+		WHERE o.account_id = $1
+		GROUP BY op.product_id
+		`, accountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]uint64{}
+	for rows.Next() {
+		var id string
+		var orders uint64
+		if err = rows.Scan(&id, &orders); err != nil {
+			return nil, err
+		}
+		counts[id] = orders
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"strings"
+	"time"
 
 	account "github.com/PranavTrip/go-grpc-graphql-ms/account"
 	catalog "github.com/PranavTrip/go-grpc-graphql-ms/catalog"
 	"github.com/PranavTrip/go-grpc-graphql-ms/order/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type grpcServer struct {
@@ -19,25 +23,35 @@ type grpcServer struct {
 	service       Service
 	accountClient *account.Client
 	catalogClient *catalog.Client
+	limits        OrderLimits
+	guestTokens   guestTokens
 }
 
-func ListenGRPC(s Service, accountURL string, catalogURL string, port int) error {
-	accountClient, err := account.NewClient(accountURL)
+// ListenGRPC serves the order service on the given port.
+// Guest checkout is only available when a guestTokenSecret is set to sign the guests' order links with.
+func ListenGRPC(s Service, accountURL string, catalogURL string, limits OrderLimits, guestTokenSecret string, port int) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
 	}
-	catalogClient, err := catalog.NewClient(catalogURL)
+	return ServeGRPC(s, accountURL, catalogURL, limits, guestTokenSecret, lis)
+}
+
+// ServeGRPC serves the order service on lis until it is closed, like ListenGRPC does on a port.
+// The dial options are used to connect to the account and catalog services.
+func ServeGRPC(s Service, accountURL string, catalogURL string, limits OrderLimits, guestTokenSecret string, lis net.Listener, opts ...grpc.DialOption) error {
+	accountClient, err := account.NewClient(accountURL, opts...)
 	if err != nil {
-		accountClient.Close()
+		lis.Close()
 		return err
 	}
-
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	defer accountClient.Close()
+	catalogClient, err := catalog.NewClient(catalogURL, opts...)
 	if err != nil {
-		accountClient.Close()
-		catalogClient.Close()
+		lis.Close()
 		return err
 	}
+	defer catalogClient.Close()
 
 	serv := grpc.NewServer()
 	pb.RegisterOrderServiceServer(serv, &grpcServer{
@@ -45,6 +59,8 @@ func ListenGRPC(s Service, accountURL string, catalogURL string, port int) error
 		service:                         s,
 		accountClient:                   accountClient,
 		catalogClient:                   catalogClient,
+		limits:                          limits,
+		guestTokens:                     guestTokens{secret: []byte(guestTokenSecret)},
 	})
 	reflection.Register(serv)
 	return serv.Serve(lis)
@@ -53,19 +69,55 @@ func ListenGRPC(s Service, accountURL string, catalogURL string, port int) error
 
 func (s *grpcServer) PostOrder(ctx context.Context, r *pb.PostOrderRequest) (*pb.PostOrderResponse, error) {
 
+	// Convert the requested products from protobuf
+	requested := []OrderedProduct{}
+	for _, p := range r.Products {
+		requested = append(requested, OrderedProduct{
+			ID:        p.ProductId,
+			VariantID: p.VariantId,
+			Quantity:  p.Quantity,
+		})
+	}
+
+	order, err := s.placeOrder(ctx, r.AccountId, nil, requested)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostOrderResponse{
+		Order: orderToProto(order),
+	}, nil
+}
+
+// placeOrder places an order for an existing account through placeOrderFor
+func (s *grpcServer) placeOrder(ctx context.Context, accountID string, shippingAddress *Address, requested []OrderedProduct) (*Order, error) {
+
 	// Get account from account client using the accountID
-	_, err := s.accountClient.GetAccount(ctx, r.AccountId)
+	_, err := s.accountClient.GetAccount(ctx, accountID)
 	if err != nil {
 		log.Println("Error getting account: ", err)
 		return nil, errors.New("account not found")
 	}
+	return s.placeOrderFor(ctx, func(ctx context.Context) (string, error) { return accountID, nil }, shippingAddress, requested)
+}
+
+// placeOrderFor prices the requested products with their current catalog details and stores the order.
+// It is the single path through which orders get created, so every order passes validateOrder;
+// a rejected request returns a *ValidationError, which reaches the client as InvalidArgument.
+// The order goes to the account returned by owner, which is only called once the order has been priced
+// and its stock taken, so that nothing is created for an order that gets rejected.
+func (s *grpcServer) placeOrderFor(ctx context.Context, owner func(ctx context.Context) (string, error), shippingAddress *Address, requested []OrderedProduct) (*Order, error) {
+
+	// Without any product IDs the catalog would list all of its products, so reject empty orders first
+	if len(requested) == 0 {
+		return nil, &ValidationError{Violations: []FieldViolation{{Field: "products", Description: "order has no products"}}}
+	}
 
 	// Empty slice for storing the product IDs of Ordered Products
 	productIDs := []string{}
 
-	// Range over the products coming from request and append in the above slice
-	for _, p := range r.Products {
-		productIDs = append(productIDs, p.ProductId)
+	// Range over the requested products and append in the above slice
+	for _, p := range requested {
+		productIDs = append(productIDs, p.ID)
 	}
 
 	// Now based on the productIDs of the ordered products, get the entire products using the catalogClient
@@ -75,73 +127,429 @@ func (s *grpcServer) PostOrder(ctx context.Context, r *pb.PostOrderRequest) (*pb
 		return nil, errors.New("products not found")
 	}
 
-	// Create empty slice for storing the ordered products
-	products := []OrderedProduct{}
+	// Merge and check the requested lines against the catalog products and the order limits
+	products, err := validateOrder(requested, orderedProducts, s.limits)
+	if err != nil {
+		log.Println("Invalid order: ", err)
+		return nil, err
+	}
 
-	// Range over the orderedProducts to include only the products with valid quantity
-	for _, p := range orderedProducts {
-		product := OrderedProduct{
-			ID:          p.ID,
-			Quantity:    0,
-			Price:       p.Price,
-			Name:        p.Name,
-			Description: p.Description,
-		}
-		for _, rp := range r.Products {
-			if rp.ProductId == p.ID {
-				product.Quantity = rp.Quantity
-				break
-			}
+	// Take the ordered quantities out of the stock, which returns put back once they are received
+	if err := s.takeStock(ctx, products); err != nil {
+		log.Println("Error taking stock: ", err)
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil, err
 		}
+		return nil, errors.New("could not take products out of stock")
+	}
 
-		if product.Quantity != 0 {
-			products = append(products, product)
-		}
+	accountID, err := owner(ctx)
+	if err != nil {
+		s.putBackStock(ctx, products)
+		return nil, err
 	}
 
 	// Call the service function to post the order in the DB
-	order, err := s.service.PostOrder(ctx, r.AccountId, products)
+	order, err := s.service.PostOrder(ctx, accountID, shippingAddress, products)
 	if err != nil {
 		log.Println("Error posting order: ", err)
+		s.putBackStock(ctx, products)
 		return nil, errors.New("could not post order")
 	}
+	return order, nil
+}
+
+// takeStock subtracts the quantities of the order lines from the stock of their products or variants.
+// If a line fails, what was already taken for the lines before it is put back. A line with less stock left
+// than ordered fails with FailedPrecondition.
+func (s *grpcServer) takeStock(ctx context.Context, products []OrderedProduct) error {
+	for i, p := range products {
+		if _, err := s.catalogClient.AdjustStock(ctx, p.ID, p.VariantID, -int64(p.Quantity)); err != nil {
+			s.putBackStock(ctx, products[:i])
+			if status.Code(err) == codes.FailedPrecondition {
+				return status.Errorf(codes.FailedPrecondition, "not enough stock left of %s to order %d", lineName(p), p.Quantity)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// lineName names the product of an order line in messages, with its variant if it has one
+func lineName(p OrderedProduct) string {
+	if p.VariantID != "" {
+		return fmt.Sprintf("variant %s of product %s", p.VariantID, p.ID)
+	}
+	return "product " + p.ID
+}
+
+// putBackStock undoes takeStock for an order that won't be placed after all
+func (s *grpcServer) putBackStock(ctx context.Context, products []OrderedProduct) {
+	for _, p := range products {
+		if _, err := s.catalogClient.AdjustStock(ctx, p.ID, p.VariantID, int64(p.Quantity)); err != nil {
+			log.Println("Error putting back stock: ", p.ID, err)
+		}
+	}
+}
+
+func (s *grpcServer) ReorderOrder(ctx context.Context, r *pb.ReorderOrderRequest) (*pb.ReorderOrderResponse, error) {
+
+	// Get the order to repeat
+	original, err := s.service.GetOrder(ctx, r.OrderId)
+	if err != nil {
+		log.Println("Error getting order: ", err)
+		return nil, err
+	}
+
+	// Check which of its products are still in the catalog
+	productIDs := []string{}
+	for _, p := range original.Products {
+		productIDs = append(productIDs, p.ID)
+	}
+	products, err := s.catalogClient.GetProducts(ctx, productIDs, 0, 0, "")
+	if err != nil {
+		log.Println("Error getting products: ", err)
+		return nil, errors.New("products not found")
+	}
+	available := map[string]catalog.Product{}
+	for _, p := range products {
+		if !p.Archived {
+			available[p.ID] = p
+		}
+	}
+
+	// Carry over the lines whose product, and variant, is still available and in stock, and report the rest
+	res := &pb.ReorderOrderResponse{Skipped: []*pb.ReorderOrderResponse_SkippedLine{}}
+	requested := []OrderedProduct{}
+	for _, p := range original.Products {
+		cp, productOK := available[p.ID]
+		variant, variantOK := cp.Variant(p.VariantID)
+		stock := cp.Stock
+		if p.VariantID != "" {
+			stock = variant.Stock
+		}
+		reason := ""
+		switch {
+		case !productOK:
+			reason = "product is no longer available"
+		case p.VariantID != "" && !variantOK:
+			reason = "variant is no longer available"
+		case p.VariantID == "" && len(cp.Variants) > 0:
+			reason = "product is now sold in variants"
+		case stock < int64(p.Quantity):
+			reason = "not enough stock left"
+		}
+		if reason != "" {
+			res.Skipped = append(res.Skipped, &pb.ReorderOrderResponse_SkippedLine{
+				ProductId: p.ID,
+				VariantId: p.VariantID,
+				Quantity:  p.Quantity,
+				Reason:    reason,
+			})
+			continue
+		}
+		requested = append(requested, OrderedProduct{
+			ID:        p.ID,
+			VariantID: p.VariantID,
+			Quantity:  p.Quantity,
+		})
+	}
+	if len(requested) == 0 {
+		return res, nil
+	}
+
+	// Place the new order at current prices through the same path as PostOrder
+	order, err := s.placeOrder(ctx, original.AccountID, original.ShippingAddress, requested)
+	if err != nil {
+		return nil, err
+	}
+	res.Order = orderToProto(order)
+	return res, nil
+}
+
+func (s *grpcServer) PostGuestOrder(ctx context.Context, r *pb.PostGuestOrderRequest) (*pb.PostGuestOrderResponse, error) {
+	if len(s.guestTokens.secret) == 0 {
+		return nil, ErrGuestCheckoutDisabled
+	}
+
+	// Check the guest's details before creating anything for them
+	address := addressFromProto(r.ShippingAddress)
+	if err := validateGuest(r.Email, address); err != nil {
+		log.Println("Invalid guest order: ", err)
+		return nil, err
+	}
 
-	// Convert the order to protobuf to match the return statements
+	requested := []OrderedProduct{}
+	for _, p := range r.Products {
+		requested = append(requested, OrderedProduct{
+			ID:        p.ProductId,
+			VariantID: p.VariantId,
+			Quantity:  p.Quantity,
+		})
+	}
+
+	// Orders always belong to an account, so every checkout creates a guest account of its own. It is
+	// only created once the order has been accepted, so rejected checkouts don't leave any behind.
+	order, err := s.placeOrderFor(ctx, func(ctx context.Context) (string, error) {
+		guest, err := s.accountClient.PostGuestAccount(ctx, r.Email)
+		if err != nil {
+			log.Println("Error creating guest account: ", err)
+			return "", err
+		}
+		return guest.ID, nil
+	}, address, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign the token the guest looks the order up with
+	token, err := s.guestTokens.sign(order.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostGuestOrderResponse{
+		Order: orderToProto(order),
+		Token: token,
+	}, nil
+}
+
+func (s *grpcServer) GetGuestOrder(ctx context.Context, r *pb.GetGuestOrderRequest) (*pb.GetGuestOrderResponse, error) {
+	orderID, err := s.guestTokens.verify(r.Token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.service.GetOrder(ctx, orderID)
+	if err != nil {
+		log.Println("Error getting order: ", err)
+		return nil, err
+	}
+
+	orders, err := s.ordersToProto(ctx, []Order{*order})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetGuestOrderResponse{Order: orders[0]}, nil
+}
+
+func (s *grpcServer) ClaimGuestOrder(ctx context.Context, r *pb.ClaimGuestOrderRequest) (*pb.ClaimGuestOrderResponse, error) {
+	// The guest token proves access to the order, and only to that order
+	orderID, err := s.guestTokens.verify(r.Token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.service.GetOrder(ctx, orderID)
+	if err != nil {
+		log.Println("Error getting order: ", err)
+		return nil, err
+	}
+	guest, err := s.accountClient.GetAccount(ctx, order.AccountID)
+	if err != nil {
+		log.Println("Error getting account: ", err)
+		return nil, err
+	}
+
+	// The email token was mailed to the account the order moves into, so it proves the caller owns that
+	// account and its email address. Verifying spends it, so every claim needs a freshly requested one.
+	account, err := s.accountClient.VerifyEmail(ctx, r.EmailToken)
+	if err != nil {
+		log.Println("Error verifying email: ", err)
+		return nil, err
+	}
+	if !guest.Guest || account.Guest || !account.EmailVerified || !strings.EqualFold(guest.Email, account.Email) {
+		return nil, ErrInvalidClaim
+	}
+
+	claimed, err := s.service.ClaimOrder(ctx, order.ID, guest.ID, account.ID)
+	if err != nil {
+		log.Println("Error claiming order: ", err)
+		return nil, err
+	}
+	orders, err := s.ordersToProto(ctx, []Order{*claimed})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ClaimGuestOrderResponse{Order: orders[0]}, nil
+}
+
+// Convert the order to protobuf to match the return statements
+func orderToProto(order *Order) *pb.Order {
 	orderProto := &pb.Order{
-		Id:         order.ID,
-		AccountId:  order.AccountID,
-		TotalPrice: order.TotalPrice,
-		Products:   []*pb.Order_OrderProduct{},
+		Id:              order.ID,
+		AccountId:       order.AccountID,
+		TotalPrice:      order.TotalPrice,
+		Status:          string(order.Status),
+		Products:        []*pb.Order_OrderProduct{},
+		ShippingAddress: addressToProto(order.ShippingAddress),
 	}
 	orderProto.CreatedAt, _ = order.CreatedAt.MarshalBinary()
 	for _, p := range order.Products {
-		orderProto.Products = append(orderProto.Products, &pb.Order_OrderProduct{
-			Id:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-			Quantity:    p.Quantity,
-		})
+		orderProto.Products = append(orderProto.Products, orderedProductToProto(p))
 	}
-	return &pb.PostOrderResponse{
-		Order: orderProto,
-	}, nil
+	return orderProto
+}
+
+func orderedProductToProto(p OrderedProduct) *pb.Order_OrderProduct {
+	protoProduct := &pb.Order_OrderProduct{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Quantity:    p.Quantity,
+		VariantId:   p.VariantID,
+		Sku:         p.SKU,
+		Options:     []*pb.Order_OrderProduct_Option{},
+	}
+	for _, o := range p.Options {
+		protoProduct.Options = append(protoProduct.Options, &pb.Order_OrderProduct_Option{Name: o.Name, Value: o.Value})
+	}
+	return protoProduct
 }
 
 func (s *grpcServer) GetOrdersForAccount(ctx context.Context, r *pb.GetOrdersForAccountRequest) (*pb.GetOrdersForAccountResponse, error) {
 
 	// Call the service function to Get all orders for a particular accountID
-	accountOrders, err := s.service.GetOrderForAccount(ctx, r.AccountId)
+	accountOrders, next, err := s.service.GetOrderForAccount(ctx, r.AccountId, filterFromProto(r.Filter), OrderSort(r.Sort), r.After, r.Take)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	orders, err := s.ordersToProto(ctx, accountOrders)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetOrdersForAccountResponse{Orders: orders, NextCursor: next}, nil
+}
+
+func (s *grpcServer) SearchOrders(ctx context.Context, r *pb.SearchOrdersRequest) (*pb.SearchOrdersResponse, error) {
+
+	// Call the service function to search the orders of all accounts
+	orderList, next, total, err := s.service.SearchOrders(ctx, filterFromProto(r.Filter), OrderSort(r.Sort), r.After, r.Take)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	orders, err := s.ordersToProto(ctx, orderList)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SearchOrdersResponse{Orders: orders, NextCursor: next, TotalCount: total}, nil
+}
+
+func (s *grpcServer) GetSalesReport(ctx context.Context, r *pb.GetSalesReportRequest) (*pb.GetSalesReportResponse, error) {
+	report, err := s.service.GetSalesReport(ctx, timeFromProto(r.From), timeFromProto(r.To), SalesInterval(r.Interval))
+	if err == ErrInvalidSalesQuery || err == ErrSalesRangeTooLong {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	res := &pb.GetSalesReportResponse{
+		Buckets:           []*pb.SalesBucket{},
+		Revenue:           report.Revenue,
+		OrderCount:        report.OrderCount,
+		AverageOrderValue: report.AverageOrderValue,
+	}
+	for _, b := range report.Buckets {
+		bucket := &pb.SalesBucket{
+			Revenue:    b.Revenue,
+			OrderCount: b.OrderCount,
+		}
+		bucket.Start, _ = b.Start.MarshalBinary()
+		res.Buckets = append(res.Buckets, bucket)
+	}
+	return res, nil
+}
+
+func (s *grpcServer) GetTopProducts(ctx context.Context, r *pb.GetTopProductsRequest) (*pb.GetTopProductsResponse, error) {
+	topProducts, err := s.service.GetTopProducts(ctx, timeFromProto(r.From), timeFromProto(r.To), ProductSalesSort(r.SortBy), r.Limit)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
+	// Look up the product names in the catalog
+	productIDs := []string{}
+	for _, p := range topProducts {
+		productIDs = append(productIDs, p.ID)
+	}
+	names := map[string]string{}
+	if len(productIDs) > 0 {
+		products, err := s.catalogClient.GetProducts(ctx, productIDs, 0, 0, "")
+		if err != nil {
+			log.Println("Error getting top products: ", err)
+			return nil, err
+		}
+		for _, p := range products {
+			names[p.ID] = p.Name
+		}
+	}
+
+	res := &pb.GetTopProductsResponse{Products: []*pb.ProductSales{}}
+	for _, p := range topProducts {
+		res.Products = append(res.Products, &pb.ProductSales{
+			ProductId: p.ID,
+			Name:      names[p.ID],
+			Quantity:  p.Quantity,
+			Revenue:   p.Revenue,
+		})
+	}
+	return res, nil
+}
+
+func (s *grpcServer) RecommendProducts(ctx context.Context, r *pb.RecommendProductsRequest) (*pb.RecommendProductsResponse, error) {
+	// Call the service function to score the products bought together with the product or account
+	recommended, err := s.service.RecommendProducts(ctx, r.GetProductId(), r.GetAccountId(), r.Limit)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	productIDs := []string{}
+	for _, p := range recommended {
+		productIDs = append(productIDs, p.ID)
+	}
+	res := &pb.RecommendProductsResponse{Products: []*pb.RecommendedProduct{}}
+	if len(productIDs) == 0 {
+		return res, nil
+	}
+
+	// Resolve the products against the catalog, skipping any that no longer exist there
+	products, err := s.catalogClient.GetProducts(ctx, productIDs, 0, 0, "")
+	if err != nil {
+		log.Println("Error getting recommended products: ", err)
+		return nil, err
+	}
+	catalogProducts := map[string]catalog.Product{}
+	for _, p := range products {
+		catalogProducts[p.ID] = p
+	}
+	for _, p := range recommended {
+		cp, ok := catalogProducts[p.ID]
+		if !ok {
+			continue
+		}
+		res.Products = append(res.Products, &pb.RecommendedProduct{
+			Id:          p.ID,
+			Name:        cp.Name,
+			Description: cp.Description,
+			Price:       cp.Price,
+			Score:       p.Score,
+		})
+	}
+	return res, nil
+}
+
+// ordersToProto converts orders to protobuf, filling in product names and descriptions from the catalog
+func (s *grpcServer) ordersToProto(ctx context.Context, orderList []Order) ([]*pb.Order, error) {
 	// Create a map to store all the product IDs in the order
 	productIDMap := map[string]bool{}
 
-	// Range over the orders of a particular account to store the product IDs avoiding duplicates - using map
-	for _, o := range accountOrders {
+	// Range over the orders to store the product IDs avoiding duplicates - using map
+	for _, o := range orderList {
 		for _, p := range o.Products {
 			productIDMap[p.ID] = true
 		}
@@ -157,46 +565,325 @@ func (s *grpcServer) GetOrdersForAccount(ctx context.Context, r *pb.GetOrdersFor
 	// Call the GetProducts from catalogClient to get all products using the productIDs
 	products, err := s.catalogClient.GetProducts(ctx, productIDs, 0, 0, "")
 	if err != nil {
-		log.Println("Error getting account products: ", err)
+		log.Println("Error getting order products: ", err)
 		return nil, err
 	}
 
 	// Constructing a variable to store the orders in the protobuf response
 	orders := []*pb.Order{}
 
-	// Range over the account orders to return the values
-	for _, o := range accountOrders {
+	// Range over the orders to return the values
+	for _, o := range orderList {
 		op := &pb.Order{
-			AccountId:  o.AccountID,
-			Id:         o.ID,
-			TotalPrice: o.TotalPrice,
-			Products:   []*pb.Order_OrderProduct{},
+			AccountId:       o.AccountID,
+			Id:              o.ID,
+			TotalPrice:      o.TotalPrice,
+			Status:          string(o.Status),
+			Products:        []*pb.Order_OrderProduct{},
+			ShippingAddress: addressToProto(o.ShippingAddress),
 		}
 		// Marshalling time to send over grpc
 		op.CreatedAt, _ = o.CreatedAt.MarshalBinary()
 
-		// Range over o.Products (it has only ID and quantity)
+		// Range over o.Products (it has only ID, quantity and the price paid)
 		for _, product := range o.Products {
 			// range over products (it has all the product info)
 			for _, p := range products {
 				if p.ID == product.ID {
 					product.Name = p.Name
 					product.Description = p.Description
-					product.Price = p.Price
 					break
 				}
 			}
 			// Convert to grpc format
-			op.Products = append(op.Products, &pb.Order_OrderProduct{
-				Id:          product.ID,
-				Name:        product.Name,
-				Description: product.Description,
-				Price:       product.Price,
-				Quantity:    product.Quantity,
-			})
+			op.Products = append(op.Products, orderedProductToProto(product))
 		}
 
 		orders = append(orders, op)
 	}
-	return &pb.GetOrdersForAccountResponse{Orders: orders}, nil
+	return orders, nil
+}
+
+func filterFromProto(f *pb.OrderFilter) OrderFilter {
+	filter := OrderFilter{}
+	if f == nil {
+		return filter
+	}
+	filter.CreatedAfter = timeFromProto(f.CreatedAfter)
+	filter.CreatedBefore = timeFromProto(f.CreatedBefore)
+	for _, status := range f.Statuses {
+		filter.Statuses = append(filter.Statuses, OrderStatus(status))
+	}
+	filter.MinTotal = f.MinTotal
+	filter.MaxTotal = f.MaxTotal
+	filter.IDPrefix = f.IdPrefix
+	filter.ProductID = f.ProductId
+	filter.AccountID = f.AccountId
+	return filter
+}
+
+func (s *grpcServer) RequestReturn(ctx context.Context, r *pb.RequestReturnRequest) (*pb.RequestReturnResponse, error) {
+	// Convert the requested lines from protobuf
+	products := []ReturnedProduct{}
+	for _, p := range r.Products {
+		products = append(products, ReturnedProduct{
+			ID:        p.ProductId,
+			VariantID: p.VariantId,
+			Quantity:  p.Quantity,
+		})
+	}
+
+	// Call the service function to validate and store the return request
+	ret, err := s.service.RequestReturn(ctx, r.OrderId, r.Reason, products)
+	if err != nil {
+		log.Println("Error requesting return: ", err)
+		return nil, err
+	}
+	return &pb.RequestReturnResponse{Return: returnToProto(ret)}, nil
+}
+
+func (s *grpcServer) ApproveReturn(ctx context.Context, r *pb.ApproveReturnRequest) (*pb.ApproveReturnResponse, error) {
+	ret, err := s.service.ApproveReturn(ctx, r.Id)
+	if err != nil {
+		log.Println("Error approving return: ", err)
+		return nil, err
+	}
+	return &pb.ApproveReturnResponse{Return: returnToProto(ret)}, nil
+}
+
+func (s *grpcServer) RejectReturn(ctx context.Context, r *pb.RejectReturnRequest) (*pb.RejectReturnResponse, error) {
+	ret, err := s.service.RejectReturn(ctx, r.Id, r.Note)
+	if err != nil {
+		log.Println("Error rejecting return: ", err)
+		return nil, err
+	}
+	return &pb.RejectReturnResponse{Return: returnToProto(ret)}, nil
+}
+
+func (s *grpcServer) ReceiveReturn(ctx context.Context, r *pb.ReceiveReturnRequest) (*pb.ReceiveReturnResponse, error) {
+	// Mark the return as received first so the refund can only ever be issued once. Receiving it again
+	// retries restocking the lines that couldn't be restocked before.
+	ret, err := s.service.ReceiveReturn(ctx, r.Id)
+	if err != nil {
+		log.Println("Error receiving return: ", err)
+		return nil, err
+	}
+
+	// Put the returned quantities back into the stock of the products or variants in the catalog
+	for i, p := range ret.Products {
+		if p.Restocked {
+			continue
+		}
+		// Claim the line first, so that concurrent retries don't both restock it
+		claimed, err := s.service.MarkReturnRestocked(ctx, ret.ID, p.ID, p.VariantID, true)
+		if err != nil {
+			log.Println("Error marking return restocked: ", err)
+			return nil, err
+		}
+		if !claimed {
+			continue
+		}
+		if _, err := s.catalogClient.AdjustStock(ctx, p.ID, p.VariantID, int64(p.Quantity)); err != nil {
+			log.Println("Error restocking product: ", p.ID, err)
+			// Release the line again so the next attempt restocks it
+			if _, err := s.service.MarkReturnRestocked(ctx, ret.ID, p.ID, p.VariantID, false); err != nil {
+				log.Println("Error marking return restocked: ", err)
+			}
+			return nil, errors.New("return received but products could not be restocked, receive it again to retry")
+		}
+		ret.Products[i].Restocked = true
+	}
+	return &pb.ReceiveReturnResponse{Return: returnToProto(ret)}, nil
+}
+
+func (s *grpcServer) GetReturnsForOrder(ctx context.Context, r *pb.GetReturnsForOrderRequest) (*pb.GetReturnsForOrderResponse, error) {
+	returns, err := s.service.GetReturnsForOrder(ctx, r.OrderId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	res := &pb.GetReturnsForOrderResponse{Returns: []*pb.Return{}}
+	for i := range returns {
+		res.Returns = append(res.Returns, returnToProto(&returns[i]))
+	}
+	return res, nil
+}
+
+// Convert a return to protobuf, marshalling its times to binary to send over grpc
+func returnToProto(r *Return) *pb.Return {
+	rp := &pb.Return{
+		Id:        r.ID,
+		OrderId:   r.OrderID,
+		AccountId: r.AccountID,
+		Status:    string(r.Status),
+		Reason:    r.Reason,
+		Note:      r.Note,
+		Products:  []*pb.Return_ReturnProduct{},
+	}
+	rp.CreatedAt, _ = r.CreatedAt.MarshalBinary()
+	rp.UpdatedAt, _ = r.UpdatedAt.MarshalBinary()
+	for _, p := range r.Products {
+		rp.Products = append(rp.Products, &pb.Return_ReturnProduct{
+			Id:        p.ID,
+			VariantId: p.VariantID,
+			Quantity:  p.Quantity,
+			Price:     p.Price,
+			Restocked: p.Restocked,
+		})
+	}
+	if r.Refund != nil {
+		rp.Refund = &pb.Refund{
+			Id:     r.Refund.ID,
+			Amount: r.Refund.Amount,
+		}
+		rp.Refund.CreatedAt, _ = r.Refund.CreatedAt.MarshalBinary()
+	}
+	return rp
+}
+
+func (s *grpcServer) CreateShipment(ctx context.Context, r *pb.CreateShipmentRequest) (*pb.CreateShipmentResponse, error) {
+	products := []ShippedProduct{}
+	for _, p := range r.Products {
+		products = append(products, ShippedProduct{
+			ID:        p.ProductId,
+			VariantID: p.VariantId,
+			Quantity:  p.Quantity,
+		})
+	}
+
+	// Call the service function to store the shipment and refresh the order status
+	sh, status, err := s.service.CreateShipment(ctx, r.OrderId, r.Carrier, r.TrackingNumber, timeFromProto(r.ShippedAt), products)
+	if err != nil {
+		log.Println("Error creating shipment: ", err)
+		return nil, err
+	}
+	return &pb.CreateShipmentResponse{
+		Shipment:    shipmentToProto(sh),
+		OrderStatus: string(status),
+	}, nil
+}
+
+func (s *grpcServer) UpdateShipment(ctx context.Context, r *pb.UpdateShipmentRequest) (*pb.UpdateShipmentResponse, error) {
+	sh, status, err := s.service.UpdateShipment(ctx, r.Id, ShipmentUpdate{
+		Carrier:        r.Carrier,
+		TrackingNumber: r.TrackingNumber,
+		ShippedAt:      timeFromProto(r.ShippedAt),
+		DeliveredAt:    timeFromProto(r.DeliveredAt),
+	})
+	if err != nil {
+		log.Println("Error updating shipment: ", err)
+		return nil, err
+	}
+	return &pb.UpdateShipmentResponse{
+		Shipment:    shipmentToProto(sh),
+		OrderStatus: string(status),
+	}, nil
+}
+
+func (s *grpcServer) GetShipmentsForOrder(ctx context.Context, r *pb.GetShipmentsForOrderRequest) (*pb.GetShipmentsForOrderResponse, error) {
+	shipments, err := s.service.GetShipmentsForOrder(ctx, r.OrderId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	res := &pb.GetShipmentsForOrderResponse{Shipments: []*pb.Shipment{}}
+	for i := range shipments {
+		res.Shipments = append(res.Shipments, shipmentToProto(&shipments[i]))
+	}
+	return res, nil
+}
+
+func (s *grpcServer) HasDeliveredProduct(ctx context.Context, r *pb.HasDeliveredProductRequest) (*pb.HasDeliveredProductResponse, error) {
+	delivered, err := s.service.HasDeliveredProduct(ctx, r.AccountId, r.ProductId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.HasDeliveredProductResponse{Delivered: delivered}, nil
+}
+
+func (s *grpcServer) GetPurchaseCounts(ctx context.Context, r *pb.GetPurchaseCountsRequest) (*pb.GetPurchaseCountsResponse, error) {
+	counts, err := s.service.GetPurchaseCounts(ctx, r.AccountId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	res := &pb.GetPurchaseCountsResponse{Counts: []*pb.PurchaseCount{}}
+	for productID, orders := range counts {
+		res.Counts = append(res.Counts, &pb.PurchaseCount{ProductId: productID, Orders: orders})
+	}
+	return res, nil
+}
+
+func shipmentToProto(s *Shipment) *pb.Shipment {
+	sp := &pb.Shipment{
+		Id:             s.ID,
+		OrderId:        s.OrderID,
+		Carrier:        s.Carrier,
+		TrackingNumber: s.TrackingNumber,
+		ShippedAt:      timeToProto(s.ShippedAt),
+		DeliveredAt:    timeToProto(s.DeliveredAt),
+		Products:       []*pb.Shipment_ShipmentProduct{},
+	}
+	sp.CreatedAt, _ = s.CreatedAt.MarshalBinary()
+	for _, p := range s.Products {
+		sp.Products = append(sp.Products, &pb.Shipment_ShipmentProduct{
+			ProductId: p.ID,
+			VariantId: p.VariantID,
+			Quantity:  p.Quantity,
+		})
+	}
+	return sp
+}
+
+func addressToProto(a *Address) *pb.Address {
+	if a == nil {
+		return nil
+	}
+	return &pb.Address{
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+func addressFromProto(a *pb.Address) *Address {
+	if a == nil {
+		return nil
+	}
+	return &Address{
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+// Optional times travel over grpc as binary, with no bytes meaning unset
+func timeToProto(t *time.Time) []byte {
+	if t == nil {
+		return nil
+	}
+	b, _ := t.MarshalBinary()
+	return b
+}
+
+func timeFromProto(b []byte) *time.Time {
+	if len(b) == 0 {
+		return nil
+	}
+	t := time.Time{}
+	if err := t.UnmarshalBinary(b); err != nil {
+		return nil
+	}
+	return &t
 }
@@ -2,32 +2,252 @@ package order
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/segmentio/ksuid"
 )
 
+var (
+	ErrOrderNotFound              = errors.New("order not found")
+	ErrReturnNotFound             = errors.New("return not found")
+	ErrInvalidReturnStatus        = errors.New("return is not in a valid status for this operation")
+	ErrInvalidReturn              = errors.New("invalid return")
+	ErrShipmentNotFound           = errors.New("shipment not found")
+	ErrInvalidShipment            = errors.New("invalid shipment")
+	ErrInvalidOrderQuery          = errors.New("invalid order filter, sort or cursor")
+	ErrInvalidSalesQuery          = errors.New("invalid sales interval or sort")
+	ErrSalesRangeTooLong          = fmt.Errorf("sales reports have at most %d buckets, report on a shorter range or a longer interval", maxSalesBuckets)
+	ErrInvalidRecommendationQuery = errors.New("recommendations need either a product or an account")
+	ErrInvalidClaim               = errors.New("guest orders can only be claimed into a registered account with the same verified email address")
+)
+
 type Service interface {
-	PostOrder(ctx context.Context, accountID string, products []OrderedProduct) (*Order, error)
-	GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error)
+	PostOrder(ctx context.Context, accountID string, shippingAddress *Address, products []OrderedProduct) (*Order, error)
+	GetOrderForAccount(ctx context.Context, accountID string, filter OrderFilter, sort OrderSort, after string, take uint64) ([]Order, string, error)
+	SearchOrders(ctx context.Context, filter OrderFilter, sort OrderSort, after string, take uint64) ([]Order, string, uint64, error)
+	GetSalesReport(ctx context.Context, from *time.Time, to *time.Time, interval SalesInterval) (*SalesReport, error)
+	GetTopProducts(ctx context.Context, from *time.Time, to *time.Time, sortBy ProductSalesSort, limit uint64) ([]ProductSales, error)
+	RecommendProducts(ctx context.Context, productID string, accountID string, limit uint64) ([]RecommendedProduct, error)
+	GetOrder(ctx context.Context, id string) (*Order, error)
+	RequestReturn(ctx context.Context, orderID string, reason string, products []ReturnedProduct) (*Return, error)
+	GetReturn(ctx context.Context, id string) (*Return, error)
+	GetReturnsForOrder(ctx context.Context, orderID string) ([]Return, error)
+	ApproveReturn(ctx context.Context, id string) (*Return, error)
+	RejectReturn(ctx context.Context, id string, note string) (*Return, error)
+	ReceiveReturn(ctx context.Context, id string) (*Return, error)
+	MarkReturnRestocked(ctx context.Context, id string, productID string, variantID string, restocked bool) (bool, error)
+	CreateShipment(ctx context.Context, orderID string, carrier string, trackingNumber string, shippedAt *time.Time, products []ShippedProduct) (*Shipment, OrderStatus, error)
+	UpdateShipment(ctx context.Context, id string, update ShipmentUpdate) (*Shipment, OrderStatus, error)
+	GetShipmentsForOrder(ctx context.Context, orderID string) ([]Shipment, error)
+	ClaimOrder(ctx context.Context, id string, fromAccountID string, toAccountID string) (*Order, error)
+	HasDeliveredProduct(ctx context.Context, accountID string, productID string) (bool, error)
+	GetPurchaseCounts(ctx context.Context, accountID string) (map[string]uint64, error)
 }
 
+type OrderStatus string
+
+const (
+	OrderStatusPending          OrderStatus = "PENDING"
+	OrderStatusPartiallyShipped OrderStatus = "PARTIALLY_SHIPPED"
+	OrderStatusShipped          OrderStatus = "SHIPPED"
+	OrderStatusDelivered        OrderStatus = "DELIVERED"
+)
+
 type Order struct {
 	ID         string
 	CreatedAt  time.Time
 	TotalPrice float64
 	AccountID  string
+	Status     OrderStatus
 	Products   []OrderedProduct
+	// Where to ship the order; only guest orders carry one, registered accounts are shipped to elsewhere
+	ShippingAddress *Address
+}
+
+type Address struct {
+	Name       string
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
 }
 
+// A line of an order. Lines of a product with variants are for one of them, recording the SKU and
+// options it had when ordered.
 type OrderedProduct struct {
 	ID          string
+	VariantID   string
+	SKU         string
+	Options     []VariantOption
 	Name        string
 	Description string
 	Price       float64
 	Quantity    uint32
 }
 
+type VariantOption struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// lineKey identifies the line of an order a shipment or return refers to
+type lineKey struct {
+	productID string
+	variantID string
+}
+
+func (p OrderedProduct) key() lineKey  { return lineKey{p.ID, p.VariantID} }
+func (p ReturnedProduct) key() lineKey { return lineKey{p.ID, p.VariantID} }
+func (p ShippedProduct) key() lineKey  { return lineKey{p.ID, p.VariantID} }
+
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "REQUESTED"
+	ReturnStatusApproved  ReturnStatus = "APPROVED"
+	ReturnStatusRejected  ReturnStatus = "REJECTED"
+	ReturnStatusReceived  ReturnStatus = "RECEIVED"
+)
+
+type Return struct {
+	ID        string
+	OrderID   string
+	AccountID string
+	Status    ReturnStatus
+	Reason    string
+	Note      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Products  []ReturnedProduct
+	Refund    *Refund
+}
+
+// A product line being sent back, priced at what was paid for it in the order. Restocked is set once its
+// quantity has been put back into the catalog stock after the return was received.
+type ReturnedProduct struct {
+	ID        string
+	VariantID string
+	Quantity  uint32
+	Price     float64
+	Restocked bool
+}
+
+// Refund issued once the returned products have been received
+type Refund struct {
+	ID        string
+	Amount    float64
+	CreatedAt time.Time
+}
+
+type OrderSort string
+
+const (
+	OrderSortCreatedAtDesc  OrderSort = "CREATED_AT_DESC"
+	OrderSortCreatedAtAsc   OrderSort = "CREATED_AT_ASC"
+	OrderSortTotalPriceDesc OrderSort = "TOTAL_PRICE_DESC"
+	OrderSortTotalPriceAsc  OrderSort = "TOTAL_PRICE_ASC"
+)
+
+// Restricts which orders are listed; nil and empty fields don't filter
+type OrderFilter struct {
+	AccountID     string
+	IDPrefix      string
+	ProductID     string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Statuses      []OrderStatus
+	MinTotal      *float64
+	MaxTotal      *float64
+}
+
+// Most buckets a sales report has, e.g. a little under three years of days
+const maxSalesBuckets = 1000
+
+type SalesInterval string
+
+const (
+	SalesIntervalDay   SalesInterval = "DAY"
+	SalesIntervalWeek  SalesInterval = "WEEK"
+	SalesIntervalMonth SalesInterval = "MONTH"
+)
+
+// Revenue and number of orders placed in the bucket starting at Start
+type SalesBucket struct {
+	Start      time.Time
+	Revenue    float64
+	OrderCount uint64
+}
+
+type SalesReport struct {
+	Buckets           []SalesBucket
+	Revenue           float64
+	OrderCount        uint64
+	AverageOrderValue float64
+}
+
+type ProductSalesSort string
+
+const (
+	ProductSalesSortQuantity ProductSalesSort = "QUANTITY"
+	ProductSalesSortRevenue  ProductSalesSort = "REVENUE"
+)
+
+// How much of a product has been sold; Name is filled in from the catalog
+type ProductSales struct {
+	ID       string
+	Name     string
+	Quantity uint64
+	Revenue  float64
+}
+
+// A product recommended based on what was bought together with it; the higher the score the
+// stronger the recommendation. Name, Description and Price are filled in from the catalog.
+type RecommendedProduct struct {
+	ID          string
+	Name        string
+	Description string
+	Price       float64
+	Score       float64
+}
+
+// A group of order lines sent out together; ShippedAt and DeliveredAt are nil until that happens
+type Shipment struct {
+	ID             string
+	OrderID        string
+	Carrier        string
+	TrackingNumber string
+	ShippedAt      *time.Time
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	Products       []ShippedProduct
+}
+
+type ShippedProduct struct {
+	ID        string
+	VariantID string
+	Quantity  uint32
+}
+
+// Fields of a shipment to change; nil and empty fields are left as they are
+type ShipmentUpdate struct {
+	Carrier        string
+	TrackingNumber string
+	ShippedAt      *time.Time
+	DeliveredAt    *time.Time
+}
+
+// An order line that could not be carried over into a new order, and why
+type SkippedLine struct {
+	ProductID string
+	VariantID string
+	Quantity  uint32
+	Reason    string
+}
+
 type orderService struct {
 	repository Repository
 }
@@ -36,13 +256,15 @@ func NewService(r Repository) Service {
 	return &orderService{r}
 }
 
-func (s *orderService) PostOrder(ctx context.Context, accountID string, products []OrderedProduct) (*Order, error) {
+func (s *orderService) PostOrder(ctx context.Context, accountID string, shippingAddress *Address, products []OrderedProduct) (*Order, error) {
 	// Create the order using Order struct based on the details in func params
 	order := &Order{
-		ID:        ksuid.New().String(),
-		CreatedAt: time.Now().UTC(),
-		AccountID: accountID,
-		Products:  products,
+		ID:              ksuid.New().String(),
+		CreatedAt:       time.Now().UTC(),
+		AccountID:       accountID,
+		Status:          OrderStatusPending,
+		Products:        products,
+		ShippingAddress: shippingAddress,
 	}
 	// Set the total price based on the quantity of product
 	order.TotalPrice = 0.0
@@ -57,7 +279,546 @@ func (s *orderService) PostOrder(ctx context.Context, accountID string, products
 
 }
 
-// Get Order for a particular account based on the accountID
-func (s orderService) GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error) {
-	return s.repository.GetOrderForAccount(ctx, accountID)
+// Get a page of orders for a particular account based on the accountID.
+// after is the cursor returned with the previous page, which is the ID of the last order on it;
+// the returned cursor is empty once there are no more orders.
+func (s orderService) GetOrderForAccount(ctx context.Context, accountID string, filter OrderFilter, sort OrderSort, after string, take uint64) ([]Order, string, error) {
+	filter.AccountID = accountID
+	return s.pageOrders(ctx, filter, sort, after, take)
+}
+
+// Search the orders of all accounts, paginated like GetOrderForAccount, also counting all matching orders
+func (s orderService) SearchOrders(ctx context.Context, filter OrderFilter, sort OrderSort, after string, take uint64) ([]Order, string, uint64, error) {
+	orders, next, err := s.pageOrders(ctx, filter, sort, after, take)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	total, err := s.repository.CountOrders(ctx, filter)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return orders, next, total, nil
+}
+
+func (s orderService) pageOrders(ctx context.Context, filter OrderFilter, sort OrderSort, after string, take uint64) ([]Order, string, error) {
+	if take > 100 || take == 0 {
+		take = 100
+	}
+	if sort == "" {
+		sort = OrderSortCreatedAtDesc
+	}
+	switch sort {
+	case OrderSortCreatedAtDesc, OrderSortCreatedAtAsc, OrderSortTotalPriceDesc, OrderSortTotalPriceAsc:
+	default:
+		return nil, "", ErrInvalidOrderQuery
+	}
+
+	// The cursor order provides the sort key to continue from
+	var last *Order
+	if after != "" {
+		o, err := s.repository.GetOrderByID(ctx, after)
+		if err == ErrOrderNotFound || (err == nil && filter.AccountID != "" && o.AccountID != filter.AccountID) {
+			return nil, "", ErrInvalidOrderQuery
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		last = o
+	}
+
+	// Fetch one more order than asked for to know whether there is a next page
+	orders, err := s.repository.SearchOrders(ctx, filter, sort, last, take+1)
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if uint64(len(orders)) > take {
+		orders = orders[:take]
+		next = orders[len(orders)-1].ID
+	}
+	return orders, next, nil
+}
+
+// Get a single order based on its ID
+func (s orderService) GetOrder(ctx context.Context, id string) (*Order, error) {
+	return s.repository.GetOrderByID(ctx, id)
+}
+
+// Move an order, along with its returns, from the account it belongs to into another one, e.g. when
+// a guest registers a full account. Fails if the order no longer belongs to fromAccountID.
+func (s orderService) ClaimOrder(ctx context.Context, id string, fromAccountID string, toAccountID string) (*Order, error) {
+	if fromAccountID == "" || toAccountID == "" || fromAccountID == toAccountID {
+		return nil, ErrInvalidClaim
+	}
+	if err := s.repository.ReassignOrder(ctx, id, fromAccountID, toAccountID); err != nil {
+		return nil, err
+	}
+	return s.repository.GetOrderByID(ctx, id)
+}
+
+func (s *orderService) RequestReturn(ctx context.Context, orderID string, reason string, products []ReturnedProduct) (*Return, error) {
+	if len(products) == 0 {
+		return nil, ErrInvalidReturn
+	}
+
+	o, err := s.repository.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Quantities that are still returnable per line: delivered minus everything already returned or pending
+	prices := map[lineKey]float64{}
+	for _, p := range o.Products {
+		prices[p.key()] = p.Price
+	}
+	shipments, err := s.repository.GetShipmentsForOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	_, returnable := shippedQuantities(shipments)
+	previous, err := s.repository.GetReturnsForOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range previous {
+		if r.Status == ReturnStatusRejected {
+			continue
+		}
+		for _, p := range r.Products {
+			returnable[p.key()] -= p.Quantity
+		}
+	}
+
+	// Every line must belong to the order and stay within what can still be returned
+	lines := []ReturnedProduct{}
+	for _, p := range products {
+		if p.Quantity == 0 || p.Quantity > returnable[p.key()] {
+			return nil, ErrInvalidReturn
+		}
+		returnable[p.key()] -= p.Quantity
+		lines = append(lines, ReturnedProduct{
+			ID:        p.ID,
+			VariantID: p.VariantID,
+			Quantity:  p.Quantity,
+			Price:     prices[p.key()],
+		})
+	}
+
+	now := time.Now().UTC()
+	r := &Return{
+		ID:        ksuid.New().String(),
+		OrderID:   o.ID,
+		AccountID: o.AccountID,
+		Status:    ReturnStatusRequested,
+		Reason:    reason,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Products:  lines,
+	}
+	if err := s.repository.PutReturn(ctx, *r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *orderService) GetReturn(ctx context.Context, id string) (*Return, error) {
+	return s.repository.GetReturnByID(ctx, id)
+}
+
+func (s *orderService) GetReturnsForOrder(ctx context.Context, orderID string) ([]Return, error) {
+	return s.repository.GetReturnsForOrder(ctx, orderID)
+}
+
+func (s *orderService) ApproveReturn(ctx context.Context, id string) (*Return, error) {
+	return s.transitionReturn(ctx, id, ReturnStatusRequested, ReturnStatusApproved, func(r *Return) {})
+}
+
+func (s *orderService) RejectReturn(ctx context.Context, id string, note string) (*Return, error) {
+	return s.transitionReturn(ctx, id, ReturnStatusRequested, ReturnStatusRejected, func(r *Return) {
+		r.Note = note
+	})
+}
+
+// Marks an approved return as received and issues the refund for the returned lines. A return that was
+// already received is returned as it is, so that restocking the lines that couldn't be restocked can be retried.
+func (s *orderService) ReceiveReturn(ctx context.Context, id string) (*Return, error) {
+	r, err := s.repository.GetReturnByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status == ReturnStatusReceived {
+		return r, nil
+	}
+	return s.transitionReturn(ctx, id, ReturnStatusApproved, ReturnStatusReceived, func(r *Return) {
+		refund := &Refund{
+			ID:        ksuid.New().String(),
+			CreatedAt: r.UpdatedAt,
+		}
+		for _, p := range r.Products {
+			refund.Amount += p.Price * float64(p.Quantity)
+		}
+		r.Refund = refund
+	})
+}
+
+// Marks a line of a received return as restocked, or no longer so, reporting whether it changed. Claiming a
+// line before restocking it keeps concurrent retries from restocking it twice.
+func (s *orderService) MarkReturnRestocked(ctx context.Context, id string, productID string, variantID string, restocked bool) (bool, error) {
+	return s.repository.MarkReturnRestocked(ctx, id, productID, variantID, restocked)
+}
+
+// transitionReturn moves a return from one status to the next, letting apply fill in status specific fields
+func (s *orderService) transitionReturn(ctx context.Context, id string, from ReturnStatus, to ReturnStatus, apply func(r *Return)) (*Return, error) {
+	r, err := s.repository.GetReturnByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != from {
+		return nil, ErrInvalidReturnStatus
+	}
+
+	r.Status = to
+	r.UpdatedAt = time.Now().UTC()
+	apply(r)
+	if err := s.repository.UpdateReturn(ctx, *r, from); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *orderService) CreateShipment(ctx context.Context, orderID string, carrier string, trackingNumber string, shippedAt *time.Time, products []ShippedProduct) (*Shipment, OrderStatus, error) {
+	if len(products) == 0 {
+		return nil, "", ErrInvalidShipment
+	}
+
+	o, err := s.repository.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	shipments, err := s.repository.GetShipmentsForOrder(ctx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Quantities not yet part of any shipment per line
+	remaining := map[lineKey]uint32{}
+	for _, p := range o.Products {
+		remaining[p.key()] = p.Quantity
+	}
+	for _, sh := range shipments {
+		for _, p := range sh.Products {
+			remaining[p.key()] -= p.Quantity
+		}
+	}
+
+	// Every line must belong to the order and fit into what is still unshipped
+	lines := map[lineKey]uint32{}
+	for _, p := range products {
+		if p.Quantity == 0 || lines[p.key()]+p.Quantity > remaining[p.key()] {
+			return nil, "", ErrInvalidShipment
+		}
+		lines[p.key()] += p.Quantity
+	}
+	merged := []ShippedProduct{}
+	for _, p := range products {
+		if q, ok := lines[p.key()]; ok {
+			merged = append(merged, ShippedProduct{ID: p.ID, VariantID: p.VariantID, Quantity: q})
+			delete(lines, p.key())
+		}
+	}
+
+	sh := &Shipment{
+		ID:             ksuid.New().String(),
+		OrderID:        o.ID,
+		Carrier:        carrier,
+		TrackingNumber: trackingNumber,
+		ShippedAt:      shippedAt,
+		CreatedAt:      time.Now().UTC(),
+		Products:       merged,
+	}
+	if err := s.repository.PutShipment(ctx, *sh); err != nil {
+		return nil, "", err
+	}
+
+	status, err := s.refreshOrderStatus(ctx, o, append(shipments, *sh))
+	if err != nil {
+		return nil, "", err
+	}
+	return sh, status, nil
+}
+
+func (s *orderService) UpdateShipment(ctx context.Context, id string, update ShipmentUpdate) (*Shipment, OrderStatus, error) {
+	sh, err := s.repository.GetShipmentByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if update.Carrier != "" {
+		sh.Carrier = update.Carrier
+	}
+	if update.TrackingNumber != "" {
+		sh.TrackingNumber = update.TrackingNumber
+	}
+	if update.ShippedAt != nil {
+		sh.ShippedAt = update.ShippedAt
+	}
+	if update.DeliveredAt != nil {
+		sh.DeliveredAt = update.DeliveredAt
+		// A delivered shipment has necessarily been shipped
+		if sh.ShippedAt == nil {
+			sh.ShippedAt = update.DeliveredAt
+		}
+	}
+	if sh.DeliveredAt != nil && sh.DeliveredAt.Before(*sh.ShippedAt) {
+		return nil, "", ErrInvalidShipment
+	}
+
+	if err := s.repository.UpdateShipment(ctx, *sh); err != nil {
+		return nil, "", err
+	}
+
+	o, err := s.repository.GetOrderByID(ctx, sh.OrderID)
+	if err != nil {
+		return nil, "", err
+	}
+	shipments, err := s.repository.GetShipmentsForOrder(ctx, sh.OrderID)
+	if err != nil {
+		return nil, "", err
+	}
+	status, err := s.refreshOrderStatus(ctx, o, shipments)
+	if err != nil {
+		return nil, "", err
+	}
+	return sh, status, nil
+}
+
+func (s *orderService) GetShipmentsForOrder(ctx context.Context, orderID string) ([]Shipment, error) {
+	return s.repository.GetShipmentsForOrder(ctx, orderID)
+}
+
+// Whether any of the account's orders has had a line of the product delivered, in any of its variants
+func (s *orderService) HasDeliveredProduct(ctx context.Context, accountID string, productID string) (bool, error) {
+	filter := OrderFilter{AccountID: accountID, ProductID: productID}
+	var after *Order
+	for {
+		orders, err := s.repository.SearchOrders(ctx, filter, OrderSortCreatedAtDesc, after, 100)
+		if err != nil {
+			return false, err
+		}
+		for _, o := range orders {
+			shipments, err := s.repository.GetShipmentsForOrder(ctx, o.ID)
+			if err != nil {
+				return false, err
+			}
+			for _, sh := range shipments {
+				if sh.DeliveredAt == nil {
+					continue
+				}
+				for _, p := range sh.Products {
+					if p.ID == productID {
+						return true, nil
+					}
+				}
+			}
+		}
+		if len(orders) < 100 {
+			return false, nil
+		}
+		after = &orders[len(orders)-1]
+	}
+}
+
+// Counts how many of the account's orders each product it bought was in, which the catalog ranks its
+// searches by when they are personalized
+func (s *orderService) GetPurchaseCounts(ctx context.Context, accountID string) (map[string]uint64, error) {
+	return s.repository.GetPurchaseCounts(ctx, accountID)
+}
+
+// refreshOrderStatus derives the order status from its shipments and stores it if it changed
+func (s *orderService) refreshOrderStatus(ctx context.Context, o *Order, shipments []Shipment) (OrderStatus, error) {
+	status := deriveOrderStatus(o, shipments)
+	if status == o.Status {
+		return status, nil
+	}
+	if err := s.repository.UpdateOrderStatus(ctx, o.ID, status); err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// deriveOrderStatus compares the ordered quantities with what has been shipped and delivered so far
+func deriveOrderStatus(o *Order, shipments []Shipment) OrderStatus {
+	shipped, delivered := shippedQuantities(shipments)
+
+	allShipped, allDelivered, anyShipped := true, true, false
+	for _, p := range o.Products {
+		if shipped[p.key()] > 0 {
+			anyShipped = true
+		}
+		if shipped[p.key()] < p.Quantity {
+			allShipped = false
+		}
+		if delivered[p.key()] < p.Quantity {
+			allDelivered = false
+		}
+	}
+
+	switch {
+	case allDelivered:
+		return OrderStatusDelivered
+	case allShipped:
+		return OrderStatusShipped
+	case anyShipped:
+		return OrderStatusPartiallyShipped
+	default:
+		return OrderStatusPending
+	}
+}
+
+// shippedQuantities sums up per order line how much has left the warehouse and how much has arrived
+func shippedQuantities(shipments []Shipment) (map[lineKey]uint32, map[lineKey]uint32) {
+	shipped := map[lineKey]uint32{}
+	delivered := map[lineKey]uint32{}
+	for _, sh := range shipments {
+		for _, p := range sh.Products {
+			if sh.ShippedAt != nil {
+				shipped[p.key()] += p.Quantity
+			}
+			if sh.DeliveredAt != nil {
+				delivered[p.key()] += p.Quantity
+			}
+		}
+	}
+	return shipped, delivered
+}
+
+// Revenue and order counts per day, week or month between from (inclusive) and to (exclusive).
+// Buckets without orders are included with zero values so the report can be charted directly.
+func (s *orderService) GetSalesReport(ctx context.Context, from *time.Time, to *time.Time, interval SalesInterval) (*SalesReport, error) {
+	if interval == "" {
+		interval = SalesIntervalDay
+	}
+	if interval != SalesIntervalDay && interval != SalesIntervalWeek && interval != SalesIntervalMonth {
+		return nil, ErrInvalidSalesQuery
+	}
+
+	buckets, err := s.repository.GetSalesBuckets(ctx, OrderFilter{CreatedAfter: from, CreatedBefore: to}, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SalesReport{Buckets: []SalesBucket{}}
+	for _, b := range buckets {
+		report.Revenue += b.Revenue
+		report.OrderCount += b.OrderCount
+	}
+	if report.OrderCount > 0 {
+		report.AverageOrderValue = report.Revenue / float64(report.OrderCount)
+	}
+
+	// Work out the range of buckets to report, widened to the requested dates
+	var first, last time.Time
+	if len(buckets) > 0 {
+		first, last = buckets[0].Start, buckets[len(buckets)-1].Start
+	}
+	if from != nil {
+		first = truncateToInterval(*from, interval)
+	}
+	if to != nil {
+		last = truncateToInterval(to.Add(-time.Nanosecond), interval)
+	}
+	// Without orders there is nothing to widen, unless both dates are given
+	if len(buckets) == 0 && (from == nil || to == nil) {
+		return report, nil
+	}
+	if intervalsBetween(first, last, interval) >= maxSalesBuckets {
+		return nil, ErrSalesRangeTooLong
+	}
+
+	// Fill the gaps between buckets with empty ones
+	i := 0
+	for start := first; !start.After(last); start = nextInterval(start, interval) {
+		if i < len(buckets) && buckets[i].Start.Equal(start) {
+			report.Buckets = append(report.Buckets, buckets[i])
+			i++
+			continue
+		}
+		report.Buckets = append(report.Buckets, SalesBucket{Start: start})
+	}
+	return report, nil
+}
+
+// Best selling products between from (inclusive) and to (exclusive) by quantity or revenue
+func (s *orderService) GetTopProducts(ctx context.Context, from *time.Time, to *time.Time, sortBy ProductSalesSort, limit uint64) ([]ProductSales, error) {
+	if sortBy == "" {
+		sortBy = ProductSalesSortQuantity
+	}
+	if sortBy != ProductSalesSortQuantity && sortBy != ProductSalesSortRevenue {
+		return nil, ErrInvalidSalesQuery
+	}
+	if limit > 100 || limit == 0 {
+		limit = 10
+	}
+	return s.repository.GetTopProducts(ctx, OrderFilter{CreatedAfter: from, CreatedBefore: to}, sortBy, limit)
+}
+
+// truncateToInterval returns the start of the UTC day, week (starting Monday) or month t falls in
+func truncateToInterval(t time.Time, interval SalesInterval) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case SalesIntervalWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case SalesIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// intervalsBetween counts how many intervals the one starting at last starts after the one starting at first
+func intervalsBetween(first time.Time, last time.Time, interval SalesInterval) int64 {
+	switch interval {
+	case SalesIntervalWeek:
+		return int64(last.Sub(first) / (7 * 24 * time.Hour))
+	case SalesIntervalMonth:
+		return int64(last.Year()-first.Year())*12 + int64(last.Month()) - int64(first.Month())
+	default:
+		return int64(last.Sub(first) / (24 * time.Hour))
+	}
+}
+
+func nextInterval(t time.Time, interval SalesInterval) time.Time {
+	switch interval {
+	case SalesIntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case SalesIntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// Recommends the products most frequently bought together with either a product or with everything
+// an account has bought so far, leaving out what was given
+func (s *orderService) RecommendProducts(ctx context.Context, productID string, accountID string, limit uint64) ([]RecommendedProduct, error) {
+	if (productID == "") == (accountID == "") {
+		return nil, ErrInvalidRecommendationQuery
+	}
+	if limit > 100 || limit == 0 {
+		limit = 10
+	}
+
+	productIDs := []string{productID}
+	if accountID != "" {
+		var err error
+		productIDs, err = s.repository.GetPurchasedProductIDs(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if len(productIDs) == 0 {
+			return []RecommendedProduct{}, nil
+		}
+	}
+	return s.repository.GetFrequentlyBoughtWith(ctx, productIDs, limit)
 }
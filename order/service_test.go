@@ -0,0 +1,569 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestReturn(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	s := NewService(r)
+
+	putOrder(t, r, Order{
+		ID:        "order",
+		AccountID: "account",
+		CreatedAt: day(1),
+		Products: []OrderedProduct{
+			{ID: "shoe", Price: 50, Quantity: 3},
+			{ID: "shirt", VariantID: "m", Price: 20, Quantity: 2},
+			{ID: "shirt", VariantID: "l", Price: 22, Quantity: 1},
+		},
+	})
+	// Two shoes and both medium shirts have arrived; the third shoe and the large shirt are still underway
+	putShipment(t, r, Shipment{ID: "delivered", OrderID: "order", ShippedAt: ptr(day(2)), DeliveredAt: ptr(day(3)), Products: []ShippedProduct{
+		{ID: "shoe", Quantity: 2},
+		{ID: "shirt", VariantID: "m", Quantity: 2},
+	}})
+	putShipment(t, r, Shipment{ID: "underway", OrderID: "order", ShippedAt: ptr(day(2)), Products: []ShippedProduct{
+		{ID: "shoe", Quantity: 1},
+		{ID: "shirt", VariantID: "l", Quantity: 1},
+	}})
+
+	rejected := []struct {
+		name     string
+		products []ReturnedProduct
+	}{
+		{"no lines", nil},
+		{"more than delivered", []ReturnedProduct{{ID: "shoe", Quantity: 3}}},
+		{"not delivered yet", []ReturnedProduct{{ID: "shirt", VariantID: "l", Quantity: 1}}},
+		{"other variant", []ReturnedProduct{{ID: "shirt", VariantID: "s", Quantity: 1}}},
+		{"without variant", []ReturnedProduct{{ID: "shirt", Quantity: 1}}},
+		{"not ordered", []ReturnedProduct{{ID: "hat", Quantity: 1}}},
+		{"zero quantity", []ReturnedProduct{{ID: "shoe", Quantity: 0}}},
+		{"repeated lines add up", []ReturnedProduct{{ID: "shoe", Quantity: 1}, {ID: "shoe", Quantity: 2}}},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.RequestReturn(ctx, "order", "reason", tt.products); !errors.Is(err, ErrInvalidReturn) {
+				t.Fatalf("got error %v, want %v", err, ErrInvalidReturn)
+			}
+		})
+	}
+	if _, err := s.RequestReturn(ctx, "missing", "reason", []ReturnedProduct{{ID: "shoe", Quantity: 1}}); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("got error %v for a missing order, want %v", err, ErrOrderNotFound)
+	}
+
+	// Accepted returns are priced at what was paid and count against what can still be returned
+	first, err := s.RequestReturn(ctx, "order", "too small", []ReturnedProduct{{ID: "shoe", Quantity: 1}, {ID: "shirt", VariantID: "m", Quantity: 2, Price: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ReturnedProduct{{ID: "shoe", Quantity: 1, Price: 50}, {ID: "shirt", VariantID: "m", Quantity: 2, Price: 20}}
+	if first.Status != ReturnStatusRequested || first.AccountID != "account" || !reflect.DeepEqual(first.Products, want) {
+		t.Fatalf("got return %+v, want a requested return of the account with %+v", first, want)
+	}
+	if _, err := s.RequestReturn(ctx, "order", "again", []ReturnedProduct{{ID: "shirt", VariantID: "m", Quantity: 1}}); !errors.Is(err, ErrInvalidReturn) {
+		t.Fatalf("got error %v returning a line twice, want %v", err, ErrInvalidReturn)
+	}
+
+	// Rejected returns give their quantities back
+	second, err := s.RequestReturn(ctx, "order", "changed my mind", []ReturnedProduct{{ID: "shoe", Quantity: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RequestReturn(ctx, "order", "again", []ReturnedProduct{{ID: "shoe", Quantity: 1}}); !errors.Is(err, ErrInvalidReturn) {
+		t.Fatalf("got error %v returning more shoes than delivered, want %v", err, ErrInvalidReturn)
+	}
+	if _, err := s.RejectReturn(ctx, second.ID, "worn"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RequestReturn(ctx, "order", "again", []ReturnedProduct{{ID: "shoe", Quantity: 1}}); err != nil {
+		t.Fatalf("got error %v returning a shoe of a rejected return", err)
+	}
+
+	returns, err := s.GetReturnsForOrder(ctx, "order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(returns) != 3 {
+		t.Fatalf("got %d returns, want 3", len(returns))
+	}
+}
+
+func TestReceiveReturn(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	s := NewService(r)
+
+	putOrder(t, r, Order{ID: "order", AccountID: "account", CreatedAt: day(1), Products: []OrderedProduct{
+		{ID: "shoe", Price: 50, Quantity: 2},
+		{ID: "hat", Price: 15, Quantity: 1},
+	}})
+	putShipment(t, r, Shipment{ID: "shipment", OrderID: "order", ShippedAt: ptr(day(2)), DeliveredAt: ptr(day(3)), Products: []ShippedProduct{
+		{ID: "shoe", Quantity: 2},
+		{ID: "hat", Quantity: 1},
+	}})
+	ret, err := s.RequestReturn(ctx, "order", "reason", []ReturnedProduct{{ID: "shoe", Quantity: 2}, {ID: "hat", Quantity: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ReceiveReturn(ctx, ret.ID); !errors.Is(err, ErrInvalidReturnStatus) {
+		t.Fatalf("got error %v receiving a return that wasn't approved, want %v", err, ErrInvalidReturnStatus)
+	}
+	if restocked, err := s.MarkReturnRestocked(ctx, ret.ID, "shoe", "", true); err != nil || restocked {
+		t.Fatalf("got %v, %v restocking a line of a return that wasn't received, want false", restocked, err)
+	}
+	if _, err := s.ApproveReturn(ctx, ret.ID); err != nil {
+		t.Fatal(err)
+	}
+	received, err := s.ReceiveReturn(ctx, ret.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.Status != ReturnStatusReceived || received.Refund == nil || received.Refund.Amount != 115 {
+		t.Fatalf("got return %+v, want it received with a refund of 115", received)
+	}
+
+	// Each line is restocked once, however often it is claimed
+	if restocked, err := s.MarkReturnRestocked(ctx, ret.ID, "shoe", "", true); err != nil || !restocked {
+		t.Fatalf("got %v, %v claiming the shoes, want true", restocked, err)
+	}
+	if restocked, err := s.MarkReturnRestocked(ctx, ret.ID, "shoe", "", true); err != nil || restocked {
+		t.Fatalf("got %v, %v claiming the shoes again, want false", restocked, err)
+	}
+
+	// Receiving the return again leaves its refund alone and tells which lines are still to be restocked
+	again, err := s.ReceiveReturn(ctx, ret.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Refund == nil || again.Refund.ID != received.Refund.ID {
+		t.Fatalf("got refund %+v receiving the return again, want %+v", again.Refund, received.Refund)
+	}
+	want := []ReturnedProduct{{ID: "shoe", Quantity: 2, Price: 50, Restocked: true}, {ID: "hat", Quantity: 1, Price: 15}}
+	if !reflect.DeepEqual(again.Products, want) {
+		t.Fatalf("got lines %+v, want %+v", again.Products, want)
+	}
+}
+
+func TestDeriveOrderStatus(t *testing.T) {
+	o := &Order{Products: []OrderedProduct{
+		{ID: "shoe", Quantity: 2},
+		{ID: "shirt", VariantID: "m", Quantity: 1},
+	}}
+	shipped, delivered := ptr(day(2)), ptr(day(3))
+	shoes := func(quantity uint32, shippedAt *time.Time, deliveredAt *time.Time) Shipment {
+		return Shipment{ShippedAt: shippedAt, DeliveredAt: deliveredAt, Products: []ShippedProduct{{ID: "shoe", Quantity: quantity}}}
+	}
+	shirt := func(variantID string, shippedAt *time.Time, deliveredAt *time.Time) Shipment {
+		return Shipment{ShippedAt: shippedAt, DeliveredAt: deliveredAt, Products: []ShippedProduct{{ID: "shirt", VariantID: variantID, Quantity: 1}}}
+	}
+
+	tests := []struct {
+		name      string
+		shipments []Shipment
+		want      OrderStatus
+	}{
+		{"no shipments", nil, OrderStatusPending},
+		{"packed but not shipped", []Shipment{shoes(2, nil, nil), shirt("m", nil, nil)}, OrderStatusPending},
+		{"some shipped", []Shipment{shoes(1, shipped, nil)}, OrderStatusPartiallyShipped},
+		{"other variant shipped", []Shipment{shoes(2, shipped, nil), shirt("l", shipped, nil)}, OrderStatusPartiallyShipped},
+		{"all shipped", []Shipment{shoes(1, shipped, nil), shoes(1, shipped, nil), shirt("m", shipped, nil)}, OrderStatusShipped},
+		{"some delivered", []Shipment{shoes(2, shipped, delivered), shirt("m", shipped, nil)}, OrderStatusShipped},
+		{"all delivered", []Shipment{shoes(2, shipped, delivered), shirt("m", shipped, delivered)}, OrderStatusDelivered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deriveOrderStatus(o, tt.shipments); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShippedQuantities(t *testing.T) {
+	shipments := []Shipment{
+		{Products: []ShippedProduct{{ID: "shoe", Quantity: 5}}},
+		{ShippedAt: ptr(day(2)), Products: []ShippedProduct{{ID: "shoe", Quantity: 1}, {ID: "shirt", VariantID: "m", Quantity: 2}}},
+		{ShippedAt: ptr(day(2)), DeliveredAt: ptr(day(4)), Products: []ShippedProduct{{ID: "shoe", Quantity: 2}, {ID: "shirt", VariantID: "l", Quantity: 1}}},
+	}
+	shipped, delivered := shippedQuantities(shipments)
+
+	wantShipped := map[lineKey]uint32{{"shoe", ""}: 3, {"shirt", "m"}: 2, {"shirt", "l"}: 1}
+	wantDelivered := map[lineKey]uint32{{"shoe", ""}: 2, {"shirt", "l"}: 1}
+	if !reflect.DeepEqual(shipped, wantShipped) {
+		t.Errorf("got shipped %v, want %v", shipped, wantShipped)
+	}
+	if !reflect.DeepEqual(delivered, wantDelivered) {
+		t.Errorf("got delivered %v, want %v", delivered, wantDelivered)
+	}
+}
+
+func TestGetOrderForAccount(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	s := NewService(r)
+	putPagingOrders(t, r)
+
+	// Orders with the same sort key are told apart by their IDs, in the direction of the sort
+	tests := []struct {
+		sort OrderSort
+		want []string
+	}{
+		{"", []string{"o5", "o4", "o3", "o2", "o1"}},
+		{OrderSortCreatedAtDesc, []string{"o5", "o4", "o3", "o2", "o1"}},
+		{OrderSortCreatedAtAsc, []string{"o1", "o2", "o3", "o4", "o5"}},
+		{OrderSortTotalPriceDesc, []string{"o5", "o3", "o1", "o4", "o2"}},
+		{OrderSortTotalPriceAsc, []string{"o2", "o4", "o1", "o3", "o5"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.sort), func(t *testing.T) {
+			got := []string{}
+			pages := 0
+			after := ""
+			for {
+				orders, next, err := s.GetOrderForAccount(ctx, "a", OrderFilter{}, tt.sort, after, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pages++
+				for _, o := range orders {
+					got = append(got, o.ID)
+				}
+				if next == "" {
+					break
+				}
+				if next != orders[len(orders)-1].ID {
+					t.Fatalf("got cursor %q, want the ID of the last order of the page", next)
+				}
+				after = next
+			}
+			if !reflect.DeepEqual(got, tt.want) || pages != 3 {
+				t.Fatalf("got %v in %d pages, want %v in 3", got, pages, tt.want)
+			}
+		})
+	}
+
+	t.Run("filter", func(t *testing.T) {
+		orders, next, err := s.GetOrderForAccount(ctx, "a", OrderFilter{AccountID: "b", Statuses: []OrderStatus{OrderStatusShipped}}, "", "", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(orders) != 1 || orders[0].ID != "o4" || next != "" {
+			t.Fatalf("got %v, %q, want only the shipped order of the account", orders, next)
+		}
+	})
+
+	t.Run("page filled exactly", func(t *testing.T) {
+		orders, next, err := s.GetOrderForAccount(ctx, "a", OrderFilter{}, "", "o3", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(orders) != 2 || next != "" {
+			t.Fatalf("got %d orders and cursor %q, want the last 2 and no cursor", len(orders), next)
+		}
+	})
+
+	invalid := []struct {
+		name  string
+		sort  OrderSort
+		after string
+	}{
+		{"unknown sort", "PRICE", ""},
+		{"unknown cursor", "", "missing"},
+		{"cursor of another account", "", "b1"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := s.GetOrderForAccount(ctx, "a", OrderFilter{}, tt.sort, tt.after, 2); !errors.Is(err, ErrInvalidOrderQuery) {
+				t.Fatalf("got error %v, want %v", err, ErrInvalidOrderQuery)
+			}
+		})
+	}
+}
+
+func putOrder(t *testing.T, r Repository, o Order) {
+	t.Helper()
+	if o.Status == "" {
+		o.Status = OrderStatusPending
+	}
+	for _, p := range o.Products {
+		o.TotalPrice += p.Price * float64(p.Quantity)
+	}
+	if err := r.PutOrder(context.Background(), o); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchOrders(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	s := NewService(r)
+	putPagingOrders(t, r)
+
+	tests := []struct {
+		name   string
+		filter OrderFilter
+		sort   OrderSort
+		want   []string
+	}{
+		{"all accounts", OrderFilter{}, "", []string{"o5", "o4", "b1", "o3", "o2", "o1"}},
+		{"account", OrderFilter{AccountID: "b"}, "", []string{"b1"}},
+		{"ID prefix", OrderFilter{IDPrefix: "o"}, OrderSortCreatedAtAsc, []string{"o1", "o2", "o3", "o4", "o5"}},
+		{"product", OrderFilter{ProductID: "product-o3"}, "", []string{"o3"}},
+		{"created between", OrderFilter{CreatedAfter: ptr(day(2)), CreatedBefore: ptr(day(4))}, "", []string{"b1", "o3", "o2"}},
+		{"status", OrderFilter{Statuses: []OrderStatus{OrderStatusShipped, OrderStatusDelivered}}, "", []string{"o4"}},
+		{"total between", OrderFilter{MinTotal: ptr(20.0), MaxTotal: ptr(40.0)}, OrderSortTotalPriceDesc, []string{"b1", "o3", "o1", "o4"}},
+		{"nothing", OrderFilter{IDPrefix: "x"}, "", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Page through with a cursor, which may be an order of any account, checking the count on every page
+			got := []string{}
+			after := ""
+			for {
+				orders, next, total, err := s.SearchOrders(ctx, tt.filter, tt.sort, after, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if total != uint64(len(tt.want)) {
+					t.Fatalf("got a total of %d, want %d", total, len(tt.want))
+				}
+				for _, o := range orders {
+					got = append(got, o.ID)
+				}
+				if next == "" {
+					break
+				}
+				after = next
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, _, _, err := s.SearchOrders(ctx, OrderFilter{}, "", "missing", 2); !errors.Is(err, ErrInvalidOrderQuery) {
+		t.Fatalf("got error %v for an unknown cursor, want %v", err, ErrInvalidOrderQuery)
+	}
+}
+
+func TestGetSalesReport(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	s := NewService(r)
+
+	sales := []struct {
+		createdAt time.Time
+		total     float64
+	}{
+		{day(1).Add(10 * time.Hour), 10},
+		{day(1).Add(20 * time.Hour), 30},
+		{day(4), 20},
+		{day(15), 40},
+		{time.Date(2024, time.April, 2, 12, 0, 0, 0, time.UTC), 100},
+	}
+	for i, o := range sales {
+		putOrder(t, r, Order{ID: fmt.Sprint("order", i), AccountID: "account", CreatedAt: o.createdAt, Products: []OrderedProduct{{ID: "product", Price: o.total, Quantity: 1}}})
+	}
+
+	type bucket struct {
+		start      time.Time
+		revenue    float64
+		orderCount uint64
+	}
+	tests := []struct {
+		name     string
+		from     *time.Time
+		to       *time.Time
+		interval SalesInterval
+		want     []bucket
+		revenue  float64
+	}{
+		{"days up to an exclusive end", ptr(day(1)), ptr(day(5)), SalesIntervalDay, []bucket{
+			{day(1), 40, 2}, {day(2), 0, 0}, {day(3), 0, 0}, {day(4), 20, 1},
+		}, 60},
+		{"weeks starting on Monday around the orders", nil, nil, SalesIntervalWeek, []bucket{
+			{time.Date(2024, time.February, 26, 0, 0, 0, 0, time.UTC), 40, 2},
+			{day(4), 20, 1},
+			{day(11), 40, 1},
+			{day(18), 0, 0},
+			{day(25), 0, 0},
+			{time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), 100, 1},
+		}, 200},
+		{"months from a date within one", ptr(time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)), nil, SalesIntervalMonth, []bucket{
+			{time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), 0, 0},
+			{time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), 0, 0},
+			{day(1), 100, 4},
+			{time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), 100, 1},
+		}, 200},
+		{"range without orders", ptr(day(20)), ptr(day(22)), SalesIntervalDay, []bucket{{day(20), 0, 0}, {day(21), 0, 0}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := s.GetSalesReport(ctx, tt.from, tt.to, tt.interval)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []bucket{}
+			var orderCount uint64
+			for _, b := range report.Buckets {
+				got = append(got, bucket{b.Start, b.Revenue, b.OrderCount})
+				orderCount += b.OrderCount
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got buckets %v, want %v", got, tt.want)
+			}
+			if report.Revenue != tt.revenue || report.OrderCount != orderCount {
+				t.Fatalf("got revenue %v of %d orders, want %v of %d", report.Revenue, report.OrderCount, tt.revenue, orderCount)
+			}
+			if orderCount > 0 && report.AverageOrderValue != tt.revenue/float64(orderCount) {
+				t.Fatalf("got average order value %v, want %v", report.AverageOrderValue, tt.revenue/float64(orderCount))
+			}
+		})
+	}
+
+	t.Run("by day without an interval", func(t *testing.T) {
+		report, err := s.GetSalesReport(ctx, ptr(day(1)), ptr(day(3)), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Buckets) != 2 {
+			t.Fatalf("got %d buckets, want 2", len(report.Buckets))
+		}
+	})
+
+	limits := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		interval SalesInterval
+		wantErr  error
+	}{
+		{"most days", day(1), day(1).AddDate(0, 0, maxSalesBuckets), SalesIntervalDay, nil},
+		{"too many days", day(1), day(1).AddDate(0, 0, maxSalesBuckets+1), SalesIntervalDay, ErrSalesRangeTooLong},
+		{"too many weeks", day(4), day(4).AddDate(0, 0, 7*maxSalesBuckets+1), SalesIntervalWeek, ErrSalesRangeTooLong},
+		{"most months", day(1), day(1).AddDate(0, maxSalesBuckets, 0), SalesIntervalMonth, nil},
+		{"too many months", day(1), day(1).AddDate(0, maxSalesBuckets, 1), SalesIntervalMonth, ErrSalesRangeTooLong},
+		{"since the beginning of time", time.Time{}.Add(time.Hour), day(1), SalesIntervalMonth, ErrSalesRangeTooLong},
+		{"unknown interval", day(1), day(2), "HOUR", ErrInvalidSalesQuery},
+	}
+	for _, tt := range limits {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := s.GetSalesReport(ctx, &tt.from, &tt.to, tt.interval)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && len(report.Buckets) != maxSalesBuckets {
+				t.Fatalf("got %d buckets, want %d", len(report.Buckets), maxSalesBuckets)
+			}
+		})
+	}
+}
+
+func TestRecommendProducts(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	s := NewService(r)
+	putRecommendationOrders(t, r)
+
+	ids := func(products []RecommendedProduct) []string {
+		ids := []string{}
+		for _, p := range products {
+			ids = append(ids, strings.TrimRight(p.ID, "0"))
+		}
+		return ids
+	}
+	tests := []struct {
+		name      string
+		productID string
+		accountID string
+		limit     uint64
+		want      []string
+	}{
+		{"product", "shoe", "", 0, []string{"socks", "laces"}},
+		{"limited", "shoe", "", 1, []string{"socks"}},
+		// Laces and the hat were each bought once with the account's products, but the hat also sells on its own
+		{"account", "", "a", 0, []string{"laces", "hat"}},
+		{"account that bought all but one", "", "b", 0, []string{"hat"}},
+		{"account without orders", "", "d", 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productID, accountID := tt.productID, tt.accountID
+			if productID != "" {
+				productID = recommendationID(productID)
+			}
+			if accountID != "" {
+				accountID = recommendationID(accountID)
+			}
+			got, err := s.RecommendProducts(ctx, productID, accountID, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ids(got), tt.want) {
+				t.Fatalf("got %v, want %v", ids(got), tt.want)
+			}
+		})
+	}
+
+	for _, seed := range [][2]string{{"", ""}, {recommendationID("shoe"), recommendationID("a")}} {
+		if _, err := s.RecommendProducts(ctx, seed[0], seed[1], 0); !errors.Is(err, ErrInvalidRecommendationQuery) {
+			t.Errorf("got error %v recommending for %q, want %v", err, seed, ErrInvalidRecommendationQuery)
+		}
+	}
+}
+
+// putPagingOrders stores five orders of account a, with ties on both creation time and total, and one of account b
+func putPagingOrders(t *testing.T, r Repository) {
+	t.Helper()
+	orders := []struct {
+		id        string
+		accountID string
+		createdAt time.Time
+		total     float64
+		status    OrderStatus
+	}{
+		{"o1", "a", day(1), 30, ""},
+		{"o2", "a", day(2), 10, ""},
+		{"o3", "a", day(2), 30, ""},
+		{"o4", "a", day(4), 20, OrderStatusShipped},
+		{"o5", "a", day(5), 50, ""},
+		{"b1", "b", day(3), 40, ""},
+	}
+	for _, o := range orders {
+		putOrder(t, r, Order{
+			ID:        o.id,
+			AccountID: o.accountID,
+			CreatedAt: o.createdAt,
+			Status:    o.status,
+			Products:  []OrderedProduct{{ID: "product-" + o.id, Price: o.total, Quantity: 1}},
+		})
+	}
+}
+
+func putShipment(t *testing.T, r Repository, s Shipment) {
+	t.Helper()
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = day(1)
+	}
+	if err := r.PutShipment(context.Background(), s); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// day returns midnight UTC of a day in March 2024, which starts on a Friday
+func day(d int) time.Time {
+	return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -2,12 +2,151 @@ CREATE TABLE IF NOT EXISTS orders (
   id CHAR(27) PRIMARY KEY,
   created_at TIMESTAMP WITH TIME ZONE NOT NULL,
   account_id CHAR(27) NOT NULL,
-  total_price MONEY NOT NULL
+  total_price MONEY NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
 );
 
+-- CREATE TABLE IF NOT EXISTS leaves the tables of existing databases as they are, so the columns added to them
+-- since they were first created are also added here, which lets this script run again to upgrade a database
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'PENDING';
+
+CREATE INDEX IF NOT EXISTS orders_account_id_created_at_idx ON orders (account_id, created_at, id);
+CREATE INDEX IF NOT EXISTS orders_created_at_idx ON orders (created_at, id);
+CREATE INDEX IF NOT EXISTS orders_status_created_at_idx ON orders (status, created_at);
+-- Supports prefix searches on order IDs (id LIKE 'prefix%')
+CREATE INDEX IF NOT EXISTS orders_id_pattern_idx ON orders (id bpchar_pattern_ops);
+
+-- Only guest orders carry their own shipping address
+CREATE TABLE IF NOT EXISTS order_shipping_addresses (
+  order_id CHAR(27) PRIMARY KEY REFERENCES orders (id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  line1 VARCHAR(200) NOT NULL,
+  line2 VARCHAR(200) NOT NULL DEFAULT '',
+  city VARCHAR(100) NOT NULL,
+  region VARCHAR(100) NOT NULL DEFAULT '',
+  postal_code VARCHAR(20) NOT NULL,
+  country VARCHAR(2) NOT NULL
+);
+
+-- Lines of products without variants have an empty variant_id; options is the JSON array of the variant's
+-- option names and values when it was ordered
 CREATE TABLE IF NOT EXISTS order_products (
   order_id CHAR(27) REFERENCES orders (id) ON DELETE CASCADE,
   product_id CHAR(27),
+  variant_id VARCHAR(27) NOT NULL DEFAULT '',
+  sku VARCHAR(64) NOT NULL DEFAULT '',
+  options TEXT NOT NULL DEFAULT '[]',
   quantity INT NOT NULL,
-  PRIMARY KEY (product_id, order_id)
-);
\ No newline at end of file
+  price MONEY NOT NULL DEFAULT 0,
+  PRIMARY KEY (product_id, order_id, variant_id)
+);
+
+ALTER TABLE order_products
+  ADD COLUMN IF NOT EXISTS variant_id VARCHAR(27) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS sku VARCHAR(64) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS options TEXT NOT NULL DEFAULT '[]',
+  ADD COLUMN IF NOT EXISTS price MONEY NOT NULL DEFAULT 0;
+
+-- Lines of orders placed before prices were stored with them got a price of 0. What each line cost is lost, but
+-- not the total of its order: every unit gets the order's average unit price, so that refunds and revenue still
+-- add up to what was paid, and an order of a single product gets exactly its price back. Once backfilled, an
+-- order no longer has only lines without a price, so running this again changes nothing.
+UPDATE order_products op SET price = o.total_price / unpriced.quantity
+FROM orders o, (
+  SELECT order_id, SUM(quantity) AS quantity FROM order_products
+  GROUP BY order_id HAVING bool_and(price = 0::money) AND SUM(quantity) > 0
+) unpriced
+WHERE op.order_id = unpriced.order_id AND o.id = unpriced.order_id AND o.total_price > 0::money;
+
+-- The primary key already serves lookups by product; this one serves joins from orders
+CREATE INDEX IF NOT EXISTS order_products_order_id_idx ON order_products (order_id);
+
+-- Co-occurrence counts behind the "frequently bought together" recommendations, kept up to date by PutOrder
+CREATE TABLE IF NOT EXISTS product_order_counts (
+  product_id CHAR(27) PRIMARY KEY,
+  orders INT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS product_pairs (
+  product_id CHAR(27),
+  other_product_id CHAR(27),
+  orders INT NOT NULL,
+  PRIMARY KEY (product_id, other_product_id)
+);
+
+CREATE TABLE IF NOT EXISTS returns (
+  id CHAR(27) PRIMARY KEY,
+  order_id CHAR(27) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+  account_id CHAR(27) NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  reason TEXT NOT NULL,
+  note TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS returns_order_id_idx ON returns (order_id);
+
+CREATE TABLE IF NOT EXISTS return_products (
+  return_id CHAR(27) REFERENCES returns (id) ON DELETE CASCADE,
+  product_id CHAR(27),
+  variant_id VARCHAR(27) NOT NULL DEFAULT '',
+  quantity INT NOT NULL,
+  price MONEY NOT NULL,
+  restocked BOOLEAN NOT NULL DEFAULT FALSE,
+  PRIMARY KEY (return_id, product_id, variant_id)
+);
+
+ALTER TABLE return_products
+  ADD COLUMN IF NOT EXISTS variant_id VARCHAR(27) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS restocked BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS refunds (
+  id CHAR(27) PRIMARY KEY,
+  return_id CHAR(27) NOT NULL UNIQUE REFERENCES returns (id) ON DELETE CASCADE,
+  order_id CHAR(27) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+  amount MONEY NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS shipments (
+  id CHAR(27) PRIMARY KEY,
+  order_id CHAR(27) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+  carrier VARCHAR(64) NOT NULL,
+  tracking_number VARCHAR(128) NOT NULL,
+  shipped_at TIMESTAMP WITH TIME ZONE,
+  delivered_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS shipments_order_id_idx ON shipments (order_id);
+
+CREATE TABLE IF NOT EXISTS shipment_products (
+  shipment_id CHAR(27) REFERENCES shipments (id) ON DELETE CASCADE,
+  product_id CHAR(27),
+  variant_id VARCHAR(27) NOT NULL DEFAULT '',
+  quantity INT NOT NULL,
+  PRIMARY KEY (shipment_id, product_id, variant_id)
+);
+
+ALTER TABLE shipment_products ADD COLUMN IF NOT EXISTS variant_id VARCHAR(27) NOT NULL DEFAULT '';
+
+-- Lines used to be keyed without their variant; widen the primary keys created before variants existed
+DO $$
+DECLARE
+  pk RECORD;
+BEGIN
+  FOR pk IN SELECT * FROM (VALUES
+    ('order_products', 'product_id, order_id, variant_id'),
+    ('return_products', 'return_id, product_id, variant_id'),
+    ('shipment_products', 'shipment_id, product_id, variant_id')
+  ) AS keys(table_name, columns) LOOP
+    IF NOT EXISTS (
+      SELECT 1 FROM pg_index i JOIN pg_attribute a ON (a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey))
+      WHERE i.indrelid = pk.table_name::regclass AND i.indisprimary AND a.attname = 'variant_id'
+    ) THEN
+      EXECUTE format('ALTER TABLE %I DROP CONSTRAINT %I, ADD PRIMARY KEY (%s)', pk.table_name, pk.table_name || '_pkey', pk.columns);
+    END IF;
+  END LOOP;
+END
+$$;
\ No newline at end of file
@@ -0,0 +1,238 @@
+package order
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"strings"
+
+	catalog "github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OrderLimits bounds what a single order may contain
+type OrderLimits struct {
+	// Most units of one product, or of one variant of it, after merging duplicate lines
+	MaxProductQuantity uint32
+	// Most units over all products of the order
+	MaxOrderQuantity uint32
+	// Least total price of the order
+	MinOrderValue float64
+}
+
+var DefaultOrderLimits = OrderLimits{
+	MaxProductQuantity: 100,
+	MaxOrderQuantity:   1000,
+	MinOrderValue:      0,
+}
+
+// FieldViolation describes what is wrong with one field of an order request.
+// Field is "products" for the order as a whole, "products[i]", "products[i].quantity" and
+// "products[i].variantId" for the line at index i of the request, or "email" and "shippingAddress.<field>" for guest orders.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// ValidationError is returned when an order request is rejected, with every problem found in it
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	descriptions := []string{}
+	for _, v := range e.Violations {
+		descriptions = append(descriptions, fmt.Sprintf("%s: %s", v.Field, v.Description))
+	}
+	return "invalid order: " + strings.Join(descriptions, "; ")
+}
+
+func (e *ValidationError) add(field string, format string, args ...any) {
+	e.Violations = append(e.Violations, FieldViolation{
+		Field:       field,
+		Description: fmt.Sprintf(format, args...),
+	})
+}
+
+// validateOrder checks the requested lines against the catalog products and the limits.
+// Lines of the same product, or of the same variant of it, are merged into the first of them, and the merged
+// lines are returned with their current catalog details. Every problem found is reported in a single
+// ValidationError.
+func validateOrder(requested []OrderedProduct, catalogProducts []catalog.Product, limits OrderLimits) ([]OrderedProduct, error) {
+	verr := &ValidationError{}
+	if len(requested) == 0 {
+		verr.add("products", "order has no products")
+		return nil, verr
+	}
+
+	// Index the catalog products by their ID
+	known := map[string]catalog.Product{}
+	for _, p := range catalogProducts {
+		known[p.ID] = p
+	}
+
+	// Merge the requested lines by product and variant, remembering where each line first appeared. Merged
+	// quantities are added up in 64 bits, so that enough repeated lines can't wrap around and slip under the limits.
+	products := []OrderedProduct{}
+	quantities := []uint64{}
+	lines := []int{}
+	merged := map[lineKey]int{}
+	for i, rp := range requested {
+		if rp.Quantity == 0 {
+			verr.add(fmt.Sprintf("products[%d].quantity", i), "quantity must be positive")
+		}
+		p, ok := known[rp.ID]
+		if !ok {
+			verr.add(fmt.Sprintf("products[%d]", i), "product %q does not exist", rp.ID)
+			continue
+		}
+		if p.Archived {
+			verr.add(fmt.Sprintf("products[%d]", i), "product %q is no longer sold", rp.ID)
+			continue
+		}
+		line := OrderedProduct{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       p.Price,
+			Quantity:    rp.Quantity,
+		}
+
+		// Products with variants are ordered as one of them, at its price
+		switch {
+		case len(p.Variants) > 0 && rp.VariantID == "":
+			verr.add(fmt.Sprintf("products[%d].variantId", i), "product %q is sold in variants, choose one of them", rp.ID)
+			continue
+		case rp.VariantID != "":
+			v, ok := p.Variant(rp.VariantID)
+			if !ok {
+				verr.add(fmt.Sprintf("products[%d].variantId", i), "variant %q of product %q does not exist", rp.VariantID, rp.ID)
+				continue
+			}
+			line.VariantID = v.ID
+			line.SKU = v.SKU
+			line.Price = p.VariantPrice(v)
+			line.Options = []VariantOption{}
+			for _, o := range v.Options {
+				line.Options = append(line.Options, VariantOption{Name: o.Name, Value: o.Value})
+			}
+		}
+
+		if j, ok := merged[line.key()]; ok {
+			quantities[j] += uint64(rp.Quantity)
+			continue
+		}
+		merged[line.key()] = len(products)
+		lines = append(lines, i)
+		products = append(products, line)
+		quantities = append(quantities, uint64(rp.Quantity))
+	}
+
+	// Check the limits on the merged lines and on the order as a whole. Without a limit per product, a line
+	// still can't have more units than its quantity holds.
+	maxProductQuantity := uint64(math.MaxUint32)
+	if limits.MaxProductQuantity > 0 {
+		maxProductQuantity = uint64(limits.MaxProductQuantity)
+	}
+	var quantity uint64
+	var total float64
+	for j, p := range products {
+		if quantities[j] > maxProductQuantity {
+			verr.add(fmt.Sprintf("products[%d].quantity", lines[j]), "at most %d units of a product can be ordered, got %d", maxProductQuantity, quantities[j])
+		}
+		products[j].Quantity = uint32(quantities[j])
+		quantity += quantities[j]
+		total += p.Price * float64(quantities[j])
+	}
+	if limits.MaxOrderQuantity > 0 && quantity > uint64(limits.MaxOrderQuantity) {
+		verr.add("products", "at most %d units can be ordered at once, got %d", limits.MaxOrderQuantity, quantity)
+	}
+	if total < limits.MinOrderValue {
+		verr.add("products", "order total must be at least %.2f, got %.2f", limits.MinOrderValue, total)
+	}
+
+	if len(verr.Violations) > 0 {
+		return nil, verr
+	}
+	return products, nil
+}
+
+// validateGuest checks the contact details a guest checks out with
+func validateGuest(email string, address *Address) error {
+	verr := &ValidationError{}
+	if _, err := mail.ParseAddress(email); err != nil {
+		verr.add("email", "invalid email address")
+	}
+	if address == nil {
+		verr.add("shippingAddress", "shipping address is required")
+		return verr
+	}
+	required := []struct {
+		field string
+		value string
+	}{
+		{"name", address.Name},
+		{"line1", address.Line1},
+		{"city", address.City},
+		{"postalCode", address.PostalCode},
+		{"country", address.Country},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			verr.add("shippingAddress."+r.field, "%s is required", r.field)
+		}
+	}
+	if address.Country != "" && len(address.Country) != 2 {
+		verr.add("shippingAddress.country", "country must be a two-letter ISO 3166 code")
+	}
+
+	if len(verr.Violations) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// Convert the validation error to an InvalidArgument status carrying one BadRequest violation per problem
+func (e *ValidationError) GRPCStatus() *status.Status {
+	st := status.New(codes.InvalidArgument, "invalid order")
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range e.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	withDetails, err := st.WithDetails(badRequest)
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
+// Convert an InvalidArgument status with BadRequest details back to a validation error.
+// Other errors are returned unchanged.
+func validationErrorFromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		return err
+	}
+	verr := &ValidationError{}
+	for _, d := range st.Details() {
+		badRequest, ok := d.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, v := range badRequest.FieldViolations {
+			verr.Violations = append(verr.Violations, FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+	}
+	if len(verr.Violations) == 0 {
+		return err
+	}
+	return verr
+}
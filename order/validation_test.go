@@ -0,0 +1,123 @@
+package order
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	catalog "github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+)
+
+func TestValidateOrder(t *testing.T) {
+	large := 25.0
+	catalogProducts := []catalog.Product{
+		{ID: "shoe", Name: "Shoe", Description: "Runs", Price: 50},
+		{ID: "hat", Name: "Hat", Price: 10},
+		{ID: "old", Name: "Old", Price: 5, Archived: true},
+		{ID: "shirt", Name: "Shirt", Price: 20, Variants: []catalog.Variant{
+			{ID: "m", SKU: "SHIRT-M", Options: []catalog.VariantOption{{Name: "size", Value: "M"}}},
+			{ID: "l", SKU: "SHIRT-L", Options: []catalog.VariantOption{{Name: "size", Value: "L"}}, Price: &large},
+		}},
+	}
+	limits := OrderLimits{MaxProductQuantity: 10, MaxOrderQuantity: 15, MinOrderValue: 20}
+
+	valid := []struct {
+		name      string
+		requested []OrderedProduct
+		limits    OrderLimits
+		want      []OrderedProduct
+	}{
+		{
+			"lines priced from the catalog",
+			[]OrderedProduct{{ID: "shoe", Quantity: 2, Price: 1, Name: "Free"}},
+			limits,
+			[]OrderedProduct{{ID: "shoe", Name: "Shoe", Description: "Runs", Price: 50, Quantity: 2}},
+		},
+		{
+			"variants at their own price",
+			[]OrderedProduct{{ID: "shirt", VariantID: "m", Quantity: 1}, {ID: "shirt", VariantID: "l", Quantity: 1}},
+			limits,
+			[]OrderedProduct{
+				{ID: "shirt", VariantID: "m", SKU: "SHIRT-M", Options: []VariantOption{{Name: "size", Value: "M"}}, Name: "Shirt", Price: 20, Quantity: 1},
+				{ID: "shirt", VariantID: "l", SKU: "SHIRT-L", Options: []VariantOption{{Name: "size", Value: "L"}}, Name: "Shirt", Price: 25, Quantity: 1},
+			},
+		},
+		{
+			"repeated lines merged into the first",
+			[]OrderedProduct{{ID: "hat", Quantity: 4}, {ID: "shoe", Quantity: 1}, {ID: "hat", Quantity: 6}},
+			limits,
+			[]OrderedProduct{{ID: "hat", Name: "Hat", Price: 10, Quantity: 10}, {ID: "shoe", Name: "Shoe", Description: "Runs", Price: 50, Quantity: 1}},
+		},
+		{
+			"no limits",
+			[]OrderedProduct{{ID: "hat", Quantity: math.MaxUint32}},
+			OrderLimits{},
+			[]OrderedProduct{{ID: "hat", Name: "Hat", Price: 10, Quantity: math.MaxUint32}},
+		},
+	}
+	for _, tt := range valid {
+		t.Run(tt.name, func(t *testing.T) {
+			products, err := validateOrder(tt.requested, catalogProducts, tt.limits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(products, tt.want) {
+				t.Fatalf("got %+v, want %+v", products, tt.want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name      string
+		requested []OrderedProduct
+		limits    OrderLimits
+		want      []string
+	}{
+		{"no products", nil, limits, []string{"products"}},
+		{"zero quantity", []OrderedProduct{{ID: "shoe", Quantity: 0}, {ID: "hat", Quantity: 2}}, limits, []string{"products[0].quantity"}},
+		{"unknown product", []OrderedProduct{{ID: "shoe", Quantity: 1}, {ID: "sock", Quantity: 1}}, limits, []string{"products[1]"}},
+		{"archived product", []OrderedProduct{{ID: "old", Quantity: 5}}, limits, []string{"products[0]", "products"}},
+		{"variant missing", []OrderedProduct{{ID: "shirt", Quantity: 1}}, limits, []string{"products[0].variantId", "products"}},
+		{"unknown variant", []OrderedProduct{{ID: "shirt", VariantID: "xl", Quantity: 1}}, limits, []string{"products[0].variantId", "products"}},
+		{"variant of a product without", []OrderedProduct{{ID: "shoe", VariantID: "m", Quantity: 1}}, limits, []string{"products[0].variantId", "products"}},
+		{"too many of a product", []OrderedProduct{{ID: "hat", Quantity: 11}}, limits, []string{"products[0].quantity"}},
+		{"too many once merged", []OrderedProduct{{ID: "shoe", Quantity: 1}, {ID: "hat", Quantity: 6}, {ID: "hat", Quantity: 5}}, limits, []string{"products[1].quantity"}},
+		{"too many in the order", []OrderedProduct{{ID: "hat", Quantity: 10}, {ID: "shirt", VariantID: "m", Quantity: 6}}, limits, []string{"products"}},
+		{"too little in the order", []OrderedProduct{{ID: "hat", Quantity: 1}}, limits, []string{"products"}},
+		{
+			"merged quantities wrapping around",
+			[]OrderedProduct{{ID: "hat", Quantity: math.MaxUint32}, {ID: "hat", Quantity: 5}},
+			limits,
+			[]string{"products[0].quantity", "products"},
+		},
+		{
+			"merged quantities beyond a line without limits",
+			[]OrderedProduct{{ID: "hat", Quantity: math.MaxUint32}, {ID: "hat", Quantity: 1}},
+			OrderLimits{},
+			[]string{"products[0].quantity"},
+		},
+		{
+			"every problem at once",
+			[]OrderedProduct{{ID: "sock", Quantity: 1}, {ID: "shoe", Quantity: 0}, {ID: "shirt", Quantity: 1}},
+			limits,
+			[]string{"products[0]", "products[1].quantity", "products[2].variantId", "products"},
+		},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			products, err := validateOrder(tt.requested, catalogProducts, tt.limits)
+			verr := &ValidationError{}
+			if !errors.As(err, &verr) {
+				t.Fatalf("got %+v, %v, want a validation error", products, err)
+			}
+			fields := []string{}
+			for _, v := range verr.Violations {
+				fields = append(fields, v.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Fatalf("got violations %+v, want of the fields %v", verr.Violations, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/review/pb"
+	"google.golang.org/grpc"
+)
+
+type Client struct {
+	conn    *grpc.ClientConn
+	service pb.ReviewServiceClient
+}
+
+// NewClient connects to the review service at url, dialing it with opts on top of the defaults
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
+	if url == "" {
+		return nil, fmt.Errorf("grpc target url cannot be empty")
+	}
+	conn, err := grpc.Dial(url, append([]grpc.DialOption{grpc.WithInsecure()}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	c := pb.NewReviewServiceClient(conn)
+	return &Client{conn, c}, nil
+}
+
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+// PostReview submits a review for moderation, which is refused unless the product has been delivered to the account
+func (c *Client) PostReview(ctx context.Context, accountID string, productID string, rating uint32, text string) (*Review, error) {
+	r, err := c.service.PostReview(ctx, &pb.PostReviewRequest{
+		AccountId: accountID,
+		ProductId: productID,
+		Rating:    rating,
+		Text:      text,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviewFromProto(r.Review), nil
+}
+
+func (c *Client) GetReview(ctx context.Context, id string) (*Review, error) {
+	r, err := c.service.GetReview(ctx, &pb.GetReviewRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return reviewFromProto(r.Review), nil
+}
+
+// GetReviews returns a page of the reviews matching filter, newest first, along with the cursor
+// for the next page and the number of matching reviews
+func (c *Client) GetReviews(ctx context.Context, filter ReviewFilter, after string, take uint64) ([]Review, string, uint64, error) {
+	r, err := c.service.GetReviews(ctx, &pb.GetReviewsRequest{
+		ProductId: filter.ProductID,
+		AccountId: filter.AccountID,
+		Status:    string(filter.Status),
+		After:     after,
+		Take:      take,
+	})
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	reviews := []Review{}
+	for _, rp := range r.Reviews {
+		reviews = append(reviews, *reviewFromProto(rp))
+	}
+	return reviews, r.NextCursor, r.TotalCount, nil
+}
+
+func (c *Client) ApproveReview(ctx context.Context, id string) (*Review, error) {
+	r, err := c.service.ApproveReview(ctx, &pb.ApproveReviewRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return reviewFromProto(r.Review), nil
+}
+
+func (c *Client) RejectReview(ctx context.Context, id string, note string) (*Review, error) {
+	r, err := c.service.RejectReview(ctx, &pb.RejectReviewRequest{Id: id, Note: note})
+	if err != nil {
+		return nil, err
+	}
+	return reviewFromProto(r.Review), nil
+}
+
+func (c *Client) GetRating(ctx context.Context, productID string) (*Rating, error) {
+	r, err := c.service.GetRating(ctx, &pb.GetRatingRequest{ProductId: productID})
+	if err != nil {
+		return nil, err
+	}
+	return &Rating{ProductID: productID, AverageRating: r.AverageRating, RatingCount: r.RatingCount}, nil
+}
+
+func reviewFromProto(rp *pb.Review) *Review {
+	r := &Review{
+		ID:        rp.Id,
+		ProductID: rp.ProductId,
+		AccountID: rp.AccountId,
+		Rating:    rp.Rating,
+		Text:      rp.Text,
+		Status:    ReviewStatus(rp.Status),
+		Note:      rp.Note,
+	}
+	r.CreatedAt.UnmarshalBinary(rp.CreatedAt)
+	r.UpdatedAt.UnmarshalBinary(rp.UpdatedAt)
+	return r
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/PranavTrip/go-grpc-graphql-ms/review"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/tinrab/retry"
+)
+
+type Config struct {
+	DatabaseURL string `envconfig:"DATABASE_URL"`
+	OrderURL    string `envconfig:"ORDER_SERVICE_URL"`
+	CatalogURL  string `envconfig:"CATALOG_SERVICE_URL"`
+}
+
+func main() {
+	var cfg Config
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var r review.Repository
+	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
+		r, err = review.NewPostgresRepository(cfg.DatabaseURL)
+		if err != nil {
+			log.Println(err)
+		}
+		return
+	})
+	defer r.Close()
+
+	log.Println("Listening on port 8080...")
+	s := review.NewService(r)
+	log.Fatal(review.ListenGRPC(s, cfg.OrderURL, cfg.CatalogURL, 8080))
+}
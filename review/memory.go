@@ -0,0 +1,118 @@
+package review
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// memoryRepository keeps reviews in memory, for development and tests
+type memoryRepository struct {
+	mu      sync.RWMutex
+	reviews map[string]Review
+}
+
+func NewMemoryRepository() Repository {
+	return &memoryRepository{reviews: map[string]Review{}}
+}
+
+func (r *memoryRepository) Close() {
+}
+
+func (r *memoryRepository) PutReview(ctx context.Context, rev Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Same rule as the unique index of the Postgres repository
+	for _, other := range r.reviews {
+		if other.AccountID == rev.AccountID && other.ProductID == rev.ProductID && other.Status != ReviewStatusRejected {
+			return ErrAlreadyReviewed
+		}
+	}
+	r.reviews[rev.ID] = rev
+	return nil
+}
+
+func (r *memoryRepository) GetReviewByID(ctx context.Context, id string) (*Review, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rev, ok := r.reviews[id]
+	if !ok {
+		return nil, ErrReviewNotFound
+	}
+	return &rev, nil
+}
+
+func (r *memoryRepository) ListReviews(ctx context.Context, filter ReviewFilter, after *Review, take uint64) ([]Review, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reviews := r.filterReviews(filter)
+	sort.Slice(reviews, func(i, j int) bool { return newerThan(reviews[i], reviews[j]) })
+
+	if after != nil {
+		i := sort.Search(len(reviews), func(i int) bool { return newerThan(*after, reviews[i]) })
+		reviews = reviews[i:]
+	}
+	if take > 0 && take < uint64(len(reviews)) {
+		reviews = reviews[:take]
+	}
+	return reviews, nil
+}
+
+func (r *memoryRepository) CountReviews(ctx context.Context, filter ReviewFilter) (uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return uint64(len(r.filterReviews(filter))), nil
+}
+
+func (r *memoryRepository) UpdateReview(ctx context.Context, rev Review, from ReviewStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.reviews[rev.ID]
+	if !ok || current.Status != from {
+		return ErrInvalidReviewStatus
+	}
+	current.Status = rev.Status
+	current.Note = rev.Note
+	current.UpdatedAt = rev.UpdatedAt
+	r.reviews[rev.ID] = current
+	return nil
+}
+
+func (r *memoryRepository) GetRating(ctx context.Context, productID string) (*Rating, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rating := &Rating{ProductID: productID}
+	sum := 0.0
+	for _, rev := range r.filterReviews(ReviewFilter{ProductID: productID, Status: ReviewStatusApproved}) {
+		sum += float64(rev.Rating)
+		rating.RatingCount++
+	}
+	if rating.RatingCount > 0 {
+		rating.AverageRating = sum / float64(rating.RatingCount)
+	}
+	return rating, nil
+}
+
+// filterReviews returns the reviews matching filter; the caller holds the lock
+func (r *memoryRepository) filterReviews(filter ReviewFilter) []Review {
+	reviews := []Review{}
+	for _, rev := range r.reviews {
+		if (filter.ProductID == "" || rev.ProductID == filter.ProductID) &&
+			(filter.AccountID == "" || rev.AccountID == filter.AccountID) &&
+			(filter.Status == "" || rev.Status == filter.Status) {
+			reviews = append(reviews, rev)
+		}
+	}
+	return reviews
+}
+
+// newerThan orders reviews the way listings return them, newest first with ties broken by ID
+func newerThan(a Review, b Review) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
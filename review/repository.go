@@ -0,0 +1,198 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	Close()
+	PutReview(ctx context.Context, r Review) error
+	GetReviewByID(ctx context.Context, id string) (*Review, error)
+	// ListReviews returns the matching reviews newest first, continuing after the given review;
+	// a take of 0 lists them all
+	ListReviews(ctx context.Context, filter ReviewFilter, after *Review, take uint64) ([]Review, error)
+	CountReviews(ctx context.Context, filter ReviewFilter) (uint64, error)
+	UpdateReview(ctx context.Context, r Review, from ReviewStatus) error
+	GetRating(ctx context.Context, productID string) (*Rating, error)
+}
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(url string) (Repository, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+	return &postgresRepository{db}, nil
+}
+
+func (r *postgresRepository) Close() {
+	r.db.Close()
+}
+
+func (r *postgresRepository) PutReview(ctx context.Context, rev Review) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO reviews(id, product_id, account_id, rating, text, status, note, created_at, updated_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, rev.ID, rev.ProductID, rev.AccountID, rev.Rating, rev.Text, rev.Status, rev.Note, rev.CreatedAt, rev.UpdatedAt,
+	)
+
+	// The unique index on open reviews catches two reviews of the same product being posted at once
+	if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+		return ErrAlreadyReviewed
+	}
+	return err
+}
+
+func (r *postgresRepository) GetReviewByID(ctx context.Context, id string) (*Review, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+reviewColumns+" FROM reviews WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews, err := scanReviews(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, ErrReviewNotFound
+	}
+	return &reviews[0], nil
+}
+
+func (r *postgresRepository) ListReviews(ctx context.Context, filter ReviewFilter, after *Review, take uint64) ([]Review, error) {
+	args := []interface{}{}
+	conditions := filterConditions(filter, &args)
+
+	// Keyset pagination: continue right after the last review of the previous page
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	limit := ""
+	if take > 0 {
+		args = append(args, take)
+		limit = fmt.Sprintf("LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews
+		`+whereClause(conditions)+`
+		ORDER BY created_at DESC, id DESC
+		`+limit, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
+func (r *postgresRepository) CountReviews(ctx context.Context, filter ReviewFilter) (uint64, error) {
+	args := []interface{}{}
+	conditions := filterConditions(filter, &args)
+
+	var count uint64
+	row := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reviews "+whereClause(conditions), args...)
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *postgresRepository) UpdateReview(ctx context.Context, rev Review, from ReviewStatus) error {
+	// Only move the review on if no other moderator has changed its status in the meantime
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE reviews SET status = $3, note = $4, updated_at = $5
+		WHERE id = $1 AND status = $2
+		`, rev.ID, from, rev.Status, rev.Note, rev.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrInvalidReviewStatus
+	}
+	return nil
+}
+
+func (r *postgresRepository) GetRating(ctx context.Context, productID string) (*Rating, error) {
+	rating := &Rating{ProductID: productID}
+	row := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(AVG(rating), 0)::float8, COUNT(*)
+		FROM reviews
+		WHERE product_id = $1 AND status = $2
+		`, productID, ReviewStatusApproved,
+	)
+	if err := row.Scan(&rating.AverageRating, &rating.RatingCount); err != nil {
+		return nil, err
+	}
+	return rating, nil
+}
+
+const reviewColumns = "id, product_id, account_id, rating, text, status, note, created_at, updated_at"
+
+func scanReviews(rows *sql.Rows) ([]Review, error) {
+	reviews := []Review{}
+	for rows.Next() {
+		rev := Review{}
+		if err := rows.Scan(
+			&rev.ID, &rev.ProductID, &rev.AccountID, &rev.Rating, &rev.Text,
+			&rev.Status, &rev.Note, &rev.CreatedAt, &rev.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		rev.CreatedAt = rev.CreatedAt.UTC()
+		rev.UpdatedAt = rev.UpdatedAt.UTC()
+		reviews = append(reviews, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// filterConditions turns the filter into SQL conditions on reviews, appending their parameters to args
+func filterConditions(filter ReviewFilter, args *[]interface{}) []string {
+	conditions := []string{}
+	add := func(condition string, arg interface{}) {
+		*args = append(*args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(*args)))
+	}
+
+	if filter.ProductID != "" {
+		add("product_id = $%d", filter.ProductID)
+	}
+	if filter.AccountID != "" {
+		add("account_id = $%d", filter.AccountID)
+	}
+	if filter.Status != "" {
+		add("status = $%d", filter.Status)
+	}
+	return conditions
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
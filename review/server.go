@@ -0,0 +1,203 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	catalog "github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	order "github.com/PranavTrip/go-grpc-graphql-ms/order"
+	"github.com/PranavTrip/go-grpc-graphql-ms/review/pb"
+	"github.com/tinrab/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// How often and how far apart a failed rating sync is retried
+const (
+	syncRatingRetries  = 10
+	syncRatingInterval = 5 * time.Second
+)
+
+type grpcServer struct {
+	pb.UnimplementedReviewServiceServer
+	service       Service
+	orderClient   *order.Client
+	catalogClient ratingUpdater
+	// How long a failed rating sync waits before trying again
+	syncInterval time.Duration
+}
+
+// ratingUpdater is what the server needs of the catalog client, the storing of ratings
+type ratingUpdater interface {
+	UpdateRating(ctx context.Context, id string, averageRating float64, ratingCount uint64) (*catalog.Product, error)
+}
+
+// ListenGRPC serves the review service on the given port. Posted reviews are checked against the order
+// history of the order service, and the ratings of moderated products are pushed to the catalog service.
+func ListenGRPC(s Service, orderURL string, catalogURL string, port int) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	return ServeGRPC(s, orderURL, catalogURL, lis)
+}
+
+// ServeGRPC serves the review service on lis until it is closed, like ListenGRPC does on a port.
+// The dial options are used to connect to the order and catalog services.
+func ServeGRPC(s Service, orderURL string, catalogURL string, lis net.Listener, opts ...grpc.DialOption) error {
+	orderClient, err := order.NewClient(orderURL, opts...)
+	if err != nil {
+		lis.Close()
+		return err
+	}
+	defer orderClient.Close()
+	catalogClient, err := catalog.NewClient(catalogURL, opts...)
+	if err != nil {
+		lis.Close()
+		return err
+	}
+	defer catalogClient.Close()
+
+	serv := grpc.NewServer()
+	pb.RegisterReviewServiceServer(serv, &grpcServer{
+		UnimplementedReviewServiceServer: pb.UnimplementedReviewServiceServer{},
+		service:                          s,
+		orderClient:                      orderClient,
+		catalogClient:                    catalogClient,
+		syncInterval:                     syncRatingInterval,
+	})
+	reflection.Register(serv)
+	return serv.Serve(lis)
+}
+
+func (s *grpcServer) PostReview(ctx context.Context, r *pb.PostReviewRequest) (*pb.PostReviewResponse, error) {
+	// Only accounts that have received the product get to review it
+	delivered, err := s.orderClient.HasDeliveredProduct(ctx, r.AccountId, r.ProductId)
+	if err != nil {
+		log.Println("Error checking order history: ", err)
+		return nil, err
+	}
+	if !delivered {
+		return nil, ErrNotDelivered
+	}
+
+	rev, err := s.service.PostReview(ctx, r.AccountId, r.ProductId, r.Rating, r.Text)
+	if err == ErrAlreadyReviewed {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.PostReviewResponse{Review: reviewToProto(rev)}, nil
+}
+
+func (s *grpcServer) GetReview(ctx context.Context, r *pb.GetReviewRequest) (*pb.GetReviewResponse, error) {
+	rev, err := s.service.GetReview(ctx, r.Id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.GetReviewResponse{Review: reviewToProto(rev)}, nil
+}
+
+func (s *grpcServer) GetReviews(ctx context.Context, r *pb.GetReviewsRequest) (*pb.GetReviewsResponse, error) {
+	filter := ReviewFilter{
+		ProductID: r.ProductId,
+		AccountID: r.AccountId,
+		Status:    ReviewStatus(r.Status),
+	}
+	reviews, next, total, err := s.service.GetReviews(ctx, filter, r.After, r.Take)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	protoReviews := []*pb.Review{}
+	for i := range reviews {
+		protoReviews = append(protoReviews, reviewToProto(&reviews[i]))
+	}
+	return &pb.GetReviewsResponse{Reviews: protoReviews, NextCursor: next, TotalCount: total}, nil
+}
+
+func (s *grpcServer) ApproveReview(ctx context.Context, r *pb.ApproveReviewRequest) (*pb.ApproveReviewResponse, error) {
+	rev, err := s.service.ApproveReview(ctx, r.Id)
+	if err != nil {
+		log.Println("Error approving review: ", err)
+		return nil, err
+	}
+	s.syncRating(ctx, rev.ProductID)
+	return &pb.ApproveReviewResponse{Review: reviewToProto(rev)}, nil
+}
+
+func (s *grpcServer) RejectReview(ctx context.Context, r *pb.RejectReviewRequest) (*pb.RejectReviewResponse, error) {
+	rev, err := s.service.RejectReview(ctx, r.Id, r.Note)
+	if err != nil {
+		log.Println("Error rejecting review: ", err)
+		return nil, err
+	}
+
+	// The review may have been approved before, in which case it no longer counts towards the rating
+	s.syncRating(ctx, rev.ProductID)
+	return &pb.RejectReviewResponse{Review: reviewToProto(rev)}, nil
+}
+
+func (s *grpcServer) GetRating(ctx context.Context, r *pb.GetRatingRequest) (*pb.GetRatingResponse, error) {
+	rating, err := s.service.GetRating(ctx, r.ProductId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &pb.GetRatingResponse{AverageRating: rating.AverageRating, RatingCount: rating.RatingCount}, nil
+}
+
+// syncRating stores the rating of the product's approved reviews on its catalog document, so search can sort by it.
+// The moderation itself has already gone through, so a failed sync doesn't fail the request. It is retried in
+// the background instead, recomputing the rating every time so that it never stores an outdated one.
+func (s *grpcServer) syncRating(ctx context.Context, productID string) {
+	if err := s.updateRating(ctx, productID); err == nil {
+		return
+	}
+	go func() {
+		ctx := context.WithoutCancel(ctx)
+		err := retry.DoSleep(syncRatingRetries, s.syncInterval, func(_ int) error {
+			return s.updateRating(ctx, productID)
+		})
+		if err != nil {
+			log.Printf("Giving up updating the rating of product %s: %v", productID, err)
+		}
+	}()
+}
+
+func (s *grpcServer) updateRating(ctx context.Context, productID string) error {
+	rating, err := s.service.GetRating(ctx, productID)
+	if err != nil {
+		log.Println("Error computing rating: ", err)
+		return err
+	}
+	if _, err := s.catalogClient.UpdateRating(ctx, productID, rating.AverageRating, rating.RatingCount); err != nil {
+		log.Println("Error updating rating in catalog: ", err)
+		return err
+	}
+	return nil
+}
+
+func reviewToProto(r *Review) *pb.Review {
+	rp := &pb.Review{
+		Id:        r.ID,
+		ProductId: r.ProductID,
+		AccountId: r.AccountID,
+		Rating:    r.Rating,
+		Text:      r.Text,
+		Status:    string(r.Status),
+		Note:      r.Note,
+	}
+	rp.CreatedAt, _ = r.CreatedAt.MarshalBinary()
+	rp.UpdatedAt, _ = r.UpdatedAt.MarshalBinary()
+	return rp
+}
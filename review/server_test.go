@@ -0,0 +1,68 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	catalog "github.com/PranavTrip/go-grpc-graphql-ms/catalog"
+	"github.com/PranavTrip/go-grpc-graphql-ms/review/pb"
+)
+
+// flakyCatalog fails the given number of rating updates before storing them, and records the ones it stored
+type flakyCatalog struct {
+	mu       sync.Mutex
+	failures int
+	ratings  chan Rating
+}
+
+func (c *flakyCatalog) UpdateRating(ctx context.Context, id string, averageRating float64, ratingCount uint64) (*catalog.Product, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		return nil, errors.New("catalog unavailable")
+	}
+	c.ratings <- Rating{ProductID: id, AverageRating: averageRating, RatingCount: ratingCount}
+	return &catalog.Product{ID: id, AverageRating: averageRating, RatingCount: ratingCount}, nil
+}
+
+func TestSyncRatingRetries(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	putReview(t, r, Review{ID: "r1", ProductID: "shoe", AccountID: "ann", Rating: 4, Status: ReviewStatusApproved, CreatedAt: day(1)})
+	putReview(t, r, Review{ID: "r2", ProductID: "shoe", AccountID: "bob", Rating: 2, Status: ReviewStatusPending, CreatedAt: day(2)})
+
+	catalogClient := &flakyCatalog{failures: 2, ratings: make(chan Rating, 10)}
+	s := &grpcServer{service: NewService(r), catalogClient: catalogClient, syncInterval: time.Millisecond}
+
+	// The moderation goes through although the catalog can't be updated, which is tried again until it can
+	if _, err := s.ApproveReview(ctx, &pb.ApproveReviewRequest{Id: "r2"}); err != nil {
+		t.Fatal(err)
+	}
+	want := Rating{ProductID: "shoe", AverageRating: 3, RatingCount: 2}
+	select {
+	case got := <-catalogClient.ratings:
+		if got != want {
+			t.Errorf("got rating %+v stored, want %+v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the rating was not stored after the catalog came back")
+	}
+
+	// A sync that succeeds right away stores the rating without retrying
+	if _, err := s.RejectReview(ctx, &pb.RejectReviewRequest{Id: "r1", Note: "spam"}); err != nil {
+		t.Fatal(err)
+	}
+	want = Rating{ProductID: "shoe", AverageRating: 2, RatingCount: 1}
+	select {
+	case got := <-catalogClient.ratings:
+		if got != want {
+			t.Errorf("got rating %+v stored, want %+v", got, want)
+		}
+	default:
+		t.Fatal("the rating was not stored right away")
+	}
+}
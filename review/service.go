@@ -0,0 +1,199 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrReviewNotFound      = errors.New("review not found")
+	ErrInvalidReview       = errors.New("invalid review: the rating must be from 1 to 5 stars and the text at most 5000 characters")
+	ErrInvalidReviewStatus = errors.New("review is not in a valid status for this operation")
+	ErrInvalidReviewQuery  = errors.New("invalid review filter or cursor")
+	ErrAlreadyReviewed     = errors.New("account has already reviewed this product")
+	ErrNotDelivered        = errors.New("products can only be reviewed once they have been delivered to the account")
+)
+
+// Longest review text that is accepted, in characters
+const maxTextLength = 5000
+
+type Service interface {
+	PostReview(ctx context.Context, accountID string, productID string, rating uint32, text string) (*Review, error)
+	GetReview(ctx context.Context, id string) (*Review, error)
+	GetReviews(ctx context.Context, filter ReviewFilter, after string, take uint64) ([]Review, string, uint64, error)
+	ApproveReview(ctx context.Context, id string) (*Review, error)
+	RejectReview(ctx context.Context, id string, note string) (*Review, error)
+	GetRating(ctx context.Context, productID string) (*Rating, error)
+}
+
+// Reviews start out pending and only count towards the rating of the product once a moderator approves them.
+// Approved reviews can still be rejected later on, rejected ones are final.
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "PENDING"
+	ReviewStatusApproved ReviewStatus = "APPROVED"
+	ReviewStatusRejected ReviewStatus = "REJECTED"
+)
+
+type Review struct {
+	ID        string
+	ProductID string
+	AccountID string
+	Rating    uint32
+	Text      string
+	Status    ReviewStatus
+	Note      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ReviewFilter narrows down a listing of reviews; empty fields match any review
+type ReviewFilter struct {
+	ProductID string
+	AccountID string
+	Status    ReviewStatus
+}
+
+// Rating summarizes the approved reviews of a product
+type Rating struct {
+	ProductID     string
+	AverageRating float64
+	RatingCount   uint64
+}
+
+type reviewService struct {
+	repository Repository
+}
+
+func NewService(r Repository) Service {
+	return &reviewService{r}
+}
+
+// PostReview stores a review awaiting moderation. Whether the account has received the product
+// is up to the caller to check, since it takes the order history to do so.
+func (s *reviewService) PostReview(ctx context.Context, accountID string, productID string, rating uint32, text string) (*Review, error) {
+	text = strings.TrimSpace(text)
+	if accountID == "" || productID == "" || rating < 1 || rating > 5 || utf8.RuneCountInString(text) > maxTextLength {
+		return nil, ErrInvalidReview
+	}
+
+	// An account gets one review per product, unless its previous one was rejected. This only catches the
+	// common case early: the repository enforces the rule for reviews that are posted at the same time.
+	reviews, err := s.repository.ListReviews(ctx, ReviewFilter{ProductID: productID, AccountID: accountID}, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reviews {
+		if r.Status != ReviewStatusRejected {
+			return nil, ErrAlreadyReviewed
+		}
+	}
+
+	now := time.Now().UTC()
+	r := &Review{
+		ID:        ksuid.New().String(),
+		ProductID: productID,
+		AccountID: accountID,
+		Rating:    rating,
+		Text:      text,
+		Status:    ReviewStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repository.PutReview(ctx, *r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *reviewService) GetReview(ctx context.Context, id string) (*Review, error) {
+	return s.repository.GetReviewByID(ctx, id)
+}
+
+// GetReviews returns a page of matching reviews, newest first, along with the cursor for the next page
+// and how many reviews match in total. after is the cursor returned with the previous page, which is the
+// ID of the last review on it; the returned cursor is empty once there are no more reviews.
+func (s *reviewService) GetReviews(ctx context.Context, filter ReviewFilter, after string, take uint64) ([]Review, string, uint64, error) {
+	switch filter.Status {
+	case "", ReviewStatusPending, ReviewStatusApproved, ReviewStatusRejected:
+	default:
+		return nil, "", 0, ErrInvalidReviewQuery
+	}
+	if take > 100 || take == 0 {
+		take = 100
+	}
+
+	var last *Review
+	if after != "" {
+		r, err := s.repository.GetReviewByID(ctx, after)
+		if err == ErrReviewNotFound {
+			return nil, "", 0, ErrInvalidReviewQuery
+		}
+		if err != nil {
+			return nil, "", 0, err
+		}
+		last = r
+	}
+
+	// Fetch one more review than asked for to know whether there is a next page
+	reviews, err := s.repository.ListReviews(ctx, filter, last, take+1)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	next := ""
+	if uint64(len(reviews)) > take {
+		reviews = reviews[:take]
+		next = reviews[len(reviews)-1].ID
+	}
+
+	total, err := s.repository.CountReviews(ctx, filter)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return reviews, next, total, nil
+}
+
+func (s *reviewService) ApproveReview(ctx context.Context, id string) (*Review, error) {
+	r, err := s.repository.GetReviewByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != ReviewStatusPending {
+		return nil, ErrInvalidReviewStatus
+	}
+	return s.transitionReview(ctx, *r, ReviewStatusApproved, "")
+}
+
+// RejectReview turns down a pending review, or takes an approved one down again
+func (s *reviewService) RejectReview(ctx context.Context, id string, note string) (*Review, error) {
+	r, err := s.repository.GetReviewByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != ReviewStatusPending && r.Status != ReviewStatusApproved {
+		return nil, ErrInvalidReviewStatus
+	}
+	return s.transitionReview(ctx, *r, ReviewStatusRejected, note)
+}
+
+// transitionReview moves the review on from the status it was read with
+func (s *reviewService) transitionReview(ctx context.Context, r Review, to ReviewStatus, note string) (*Review, error) {
+	from := r.Status
+	r.Status = to
+	r.Note = note
+	r.UpdatedAt = time.Now().UTC()
+	if err := s.repository.UpdateReview(ctx, r, from); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *reviewService) GetRating(ctx context.Context, productID string) (*Rating, error) {
+	return s.repository.GetRating(ctx, productID)
+}
@@ -0,0 +1,250 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostReview(t *testing.T) {
+	ctx := context.Background()
+
+	invalid := []struct {
+		name      string
+		accountID string
+		productID string
+		rating    uint32
+		text      string
+	}{
+		{"no account", "", "shoe", 5, "Great"},
+		{"no product", "ann", "", 5, "Great"},
+		{"no stars", "ann", "shoe", 0, "Great"},
+		{"too many stars", "ann", "shoe", 6, "Great"},
+		{"too long", "ann", "shoe", 5, strings.Repeat("é", maxTextLength+1)},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(NewMemoryRepository())
+			if _, err := s.PostReview(ctx, tt.accountID, tt.productID, tt.rating, tt.text); !errors.Is(err, ErrInvalidReview) {
+				t.Fatalf("got error %v, want %v", err, ErrInvalidReview)
+			}
+		})
+	}
+
+	s := NewService(NewMemoryRepository())
+	r, err := s.PostReview(ctx, "ann", "shoe", 4, "  Comfortable  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != ReviewStatusPending || r.Text != "Comfortable" {
+		t.Errorf("got a %s review saying %q, want a pending one saying Comfortable", r.Status, r.Text)
+	}
+	// The text may be as long as allowed, counted in characters rather than bytes
+	if _, err := s.PostReview(ctx, "bob", "shoe", 5, strings.Repeat("é", maxTextLength)); err != nil {
+		t.Errorf("posting the longest review: %v", err)
+	}
+
+	// One review per product, as long as it is pending or approved
+	if _, err := s.PostReview(ctx, "ann", "shoe", 5, "Even better"); !errors.Is(err, ErrAlreadyReviewed) {
+		t.Fatalf("reviewing a product again while the review is pending: got error %v, want %v", err, ErrAlreadyReviewed)
+	}
+	if _, err := s.ApproveReview(ctx, r.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PostReview(ctx, "ann", "shoe", 5, "Even better"); !errors.Is(err, ErrAlreadyReviewed) {
+		t.Fatalf("reviewing a product again after the review was approved: got error %v, want %v", err, ErrAlreadyReviewed)
+	}
+	if _, err := s.PostReview(ctx, "ann", "sock", 3, "Thin"); err != nil {
+		t.Fatalf("reviewing another product: %v", err)
+	}
+
+	// Once the review is rejected, the account may post another one
+	if _, err := s.RejectReview(ctx, r.ID, "off topic"); err != nil {
+		t.Fatal(err)
+	}
+	again, err := s.PostReview(ctx, "ann", "shoe", 5, "Even better")
+	if err != nil {
+		t.Fatalf("reviewing a product again after the review was rejected: %v", err)
+	}
+	if _, err := s.PostReview(ctx, "ann", "shoe", 5, "Best yet"); !errors.Is(err, ErrAlreadyReviewed) {
+		t.Fatalf("reviewing a product a third time: got error %v, want %v", err, ErrAlreadyReviewed)
+	}
+	reviews, _, total, err := s.GetReviews(ctx, ReviewFilter{ProductID: "shoe", AccountID: "ann"}, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || reviews[0].ID != again.ID || reviews[1].Status != ReviewStatusRejected {
+		t.Errorf("got %d reviews %+v, want the new one and the rejected one", total, reviews)
+	}
+}
+
+func TestModerateReview(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		from ReviewStatus
+		// What approving and rejecting a review in the status leads to, empty if they fail
+		approved ReviewStatus
+		rejected ReviewStatus
+	}{
+		{ReviewStatusPending, ReviewStatusApproved, ReviewStatusRejected},
+		{ReviewStatusApproved, "", ReviewStatusRejected},
+		{ReviewStatusRejected, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from), func(t *testing.T) {
+			review := Review{ID: "review", ProductID: "shoe", AccountID: "ann", Rating: 4, Status: tt.from, CreatedAt: day(1)}
+
+			r := NewMemoryRepository()
+			putReview(t, r, review)
+			approved, err := NewService(r).ApproveReview(ctx, "review")
+			checkModeration(t, r, "approving", approved, err, tt.from, tt.approved, "")
+
+			r = NewMemoryRepository()
+			putReview(t, r, review)
+			rejected, err := NewService(r).RejectReview(ctx, "review", "spam")
+			checkModeration(t, r, "rejecting", rejected, err, tt.from, tt.rejected, "spam")
+		})
+	}
+
+	s := NewService(NewMemoryRepository())
+	if _, err := s.ApproveReview(ctx, "missing"); !errors.Is(err, ErrReviewNotFound) {
+		t.Errorf("approving a missing review: got error %v, want %v", err, ErrReviewNotFound)
+	}
+	if _, err := s.RejectReview(ctx, "missing", ""); !errors.Is(err, ErrReviewNotFound) {
+		t.Errorf("rejecting a missing review: got error %v, want %v", err, ErrReviewNotFound)
+	}
+}
+
+// checkModeration checks the outcome of moving a review on from a status: the review it was moved to,
+// or ErrInvalidReviewStatus with the stored review left as it was if want is empty
+func checkModeration(t *testing.T, r Repository, action string, got *Review, err error, from ReviewStatus, want ReviewStatus, note string) {
+	t.Helper()
+	stored, getErr := r.GetReviewByID(context.Background(), "review")
+	if getErr != nil {
+		t.Fatal(getErr)
+	}
+	if want == "" {
+		if !errors.Is(err, ErrInvalidReviewStatus) {
+			t.Errorf("%s a %s review: got error %v, want %v", action, from, err, ErrInvalidReviewStatus)
+		}
+		if stored.Status != from {
+			t.Errorf("%s a %s review: got it stored %s, want it left as it was", action, from, stored.Status)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("%s a %s review: %v", action, from, err)
+	}
+	if got.Status != want || stored.Status != want || stored.Note != note || !stored.UpdatedAt.After(stored.CreatedAt) {
+		t.Errorf("%s a %s review: got %+v stored as %+v, want it %s with the note %q", action, from, got, stored, want, note)
+	}
+}
+
+func TestGetReviews(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	s := NewService(r)
+
+	// Five reviews of the shoe, one a day, the third of them approved, and one of another product
+	for i, id := range []string{"r1", "r2", "r3", "r4", "r5"} {
+		status := ReviewStatusPending
+		if id == "r3" {
+			status = ReviewStatusApproved
+		}
+		putReview(t, r, Review{ID: id, ProductID: "shoe", AccountID: id, Rating: 5, Status: status, CreatedAt: day(i + 1)})
+	}
+	putReview(t, r, Review{ID: "other", ProductID: "sock", AccountID: "ann", Rating: 5, Status: ReviewStatusPending, CreatedAt: day(9)})
+
+	// Pages follow each other newest first, each counting all matching reviews
+	pages := []struct {
+		after string
+		ids   []string
+		next  string
+	}{
+		{"", []string{"r5", "r4"}, "r4"},
+		{"r4", []string{"r3", "r2"}, "r2"},
+		{"r2", []string{"r1"}, ""},
+	}
+	for _, page := range pages {
+		reviews, next, total, err := s.GetReviews(ctx, ReviewFilter{ProductID: "shoe"}, page.after, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := reviewIDs(reviews); !reflect.DeepEqual(ids, page.ids) || next != page.next || total != 5 {
+			t.Errorf("got page %v after %q with cursor %q of %d, want %v with cursor %q of 5", ids, page.after, next, total, page.ids, page.next)
+		}
+	}
+
+	// A page that ends exactly at the last review has no next one
+	reviews, next, total, err := s.GetReviews(ctx, ReviewFilter{ProductID: "shoe"}, "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reviews) != 5 || next != "" || total != 5 {
+		t.Errorf("got %d reviews with cursor %q of %d, want all 5 without a cursor", len(reviews), next, total)
+	}
+
+	// The total counts the reviews matching the filter
+	reviews, next, total, err = s.GetReviews(ctx, ReviewFilter{ProductID: "shoe", Status: ReviewStatusPending}, "r4", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := reviewIDs(reviews); !reflect.DeepEqual(ids, []string{"r2"}) || next != "r2" || total != 4 {
+		t.Errorf("got pending page %v with cursor %q of %d, want [r2] with cursor r2 of 4", ids, next, total)
+	}
+
+	if _, _, _, err := s.GetReviews(ctx, ReviewFilter{Status: "HIDDEN"}, "", 10); !errors.Is(err, ErrInvalidReviewQuery) {
+		t.Errorf("listing reviews of an unknown status: got error %v, want %v", err, ErrInvalidReviewQuery)
+	}
+	if _, _, _, err := s.GetReviews(ctx, ReviewFilter{}, "missing", 10); !errors.Is(err, ErrInvalidReviewQuery) {
+		t.Errorf("listing reviews after an unknown cursor: got error %v, want %v", err, ErrInvalidReviewQuery)
+	}
+}
+
+func TestGetRating(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	s := NewService(r)
+
+	// Only approved reviews count
+	putReview(t, r, Review{ID: "r1", ProductID: "shoe", AccountID: "ann", Rating: 5, Status: ReviewStatusApproved, CreatedAt: day(1)})
+	putReview(t, r, Review{ID: "r2", ProductID: "shoe", AccountID: "bob", Rating: 2, Status: ReviewStatusApproved, CreatedAt: day(2)})
+	putReview(t, r, Review{ID: "r3", ProductID: "shoe", AccountID: "cat", Rating: 1, Status: ReviewStatusPending, CreatedAt: day(3)})
+	putReview(t, r, Review{ID: "r4", ProductID: "shoe", AccountID: "dan", Rating: 1, Status: ReviewStatusRejected, CreatedAt: day(4)})
+
+	rating, err := s.GetRating(ctx, "shoe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rating.AverageRating != 3.5 || rating.RatingCount != 2 {
+		t.Errorf("got %+v, want 3.5 stars from 2 reviews", rating)
+	}
+	if rating, err := s.GetRating(ctx, "sock"); err != nil || rating.AverageRating != 0 || rating.RatingCount != 0 {
+		t.Errorf("got %+v, %v for a product without reviews, want no stars from 0 reviews", rating, err)
+	}
+}
+
+// putReview stores a review in whatever status it has, last updated when it was created
+func putReview(t *testing.T, r Repository, rev Review) {
+	t.Helper()
+	rev.UpdatedAt = rev.CreatedAt
+	if err := r.PutReview(context.Background(), rev); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func reviewIDs(reviews []Review) []string {
+	ids := []string{}
+	for _, r := range reviews {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func day(n int) time.Time {
+	return time.Date(2024, 1, n, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS reviews (
+  id CHAR(27) PRIMARY KEY,
+  product_id CHAR(27) NOT NULL,
+  account_id CHAR(27) NOT NULL,
+  rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+  text TEXT NOT NULL DEFAULT '',
+  status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+  note TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS reviews_product_id_status_created_at_idx ON reviews (product_id, status, created_at, id);
+-- Serves the moderation queue
+CREATE INDEX IF NOT EXISTS reviews_status_created_at_idx ON reviews (status, created_at, id);
+-- An account can only review a product again once its previous review has been rejected
+CREATE UNIQUE INDEX IF NOT EXISTS reviews_account_id_product_id_idx ON reviews (account_id, product_id) WHERE status <> 'REJECTED';